@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"avito_test/api/grpc/pb"
+	"avito_test/domain"
+	"avito_test/pkg/auth"
+	"avito_test/pkg/clientip"
+	"avito_test/pkg/logger"
+	"avito_test/usecases"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"strconv"
+)
+
+type auditAction struct {
+	name string
+	// createdId names the id of the returned object in the entry targets.
+	createdId string
+}
+
+// auditActions mirrors the actions the HTTP AuditMiddleware records for the same calls.
+var auditActions = map[string]auditAction{
+	pb.PvzService_OpenPvz_FullMethodName:        {domain.AuditPvzOpen, domain.AuditTargetPvz},
+	pb.PvzService_StartReception_FullMethodName: {domain.AuditReceptionStart, domain.AuditTargetReception},
+	pb.PvzService_CloseReception_FullMethodName: {domain.AuditReceptionClose, domain.AuditTargetReception},
+	pb.PvzService_AddProduct_FullMethodName:     {domain.AuditProductAdd, domain.AuditTargetProduct},
+	pb.PvzService_DeleteProduct_FullMethodName:  {domain.AuditProductDelete, ""},
+}
+
+// AuditInterceptor records the mutating calls. It runs before AuthInterceptor, so
+// refused calls are recorded too. The client IP is taken from x-forwarded-for only for
+// calls coming from one of the proxies.
+func AuditInterceptor(audit usecases.Audit, proxies clientip.TrustedProxies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		action, ok := auditActions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		ctx, actor := auth.WithActor(ctx)
+		resp, err := handler(ctx, req)
+
+		entry := domain.AuditEntry{
+			ActorId:   actor.Id,
+			Role:      actor.Role,
+			Action:    action.name,
+			TargetIds: make(map[string]string),
+			Status:    httpStatus(status.Code(err)),
+			IP:        peerIP(ctx, proxies),
+		}
+		if msg, ok := req.(proto.Message); ok {
+			entry.Payload, _ = protojson.Marshal(msg)
+		}
+		if id := returnedId(resp); action.createdId != "" && err == nil && id != 0 {
+			entry.TargetIds[action.createdId] = strconv.FormatInt(id, 10)
+		}
+
+		if recordErr := audit.Record(context.WithoutCancel(ctx), entry); recordErr != nil {
+			logger.FromContext(ctx).Error("failed to record audit entry", "error", recordErr)
+		}
+		return resp, err
+	}
+}
+
+func returnedId(resp any) int64 {
+	switch r := resp.(type) {
+	case *pb.Pvz:
+		return r.GetId()
+	case *pb.Reception:
+		return r.GetId()
+	case *pb.Product:
+		return r.GetId()
+	default:
+		return 0
+	}
+}
+
+func peerIP(ctx context.Context, proxies clientip.TrustedProxies) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return proxies.ClientIP(p.Addr.String(), md.Get("x-forwarded-for"))
+}
+
+// httpStatus maps the codes returned by toStatus to the statuses the HTTP handlers
+// answer the same errors with.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusUnprocessableEntity
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"avito_test/api/grpc/pb"
+	"avito_test/pkg/auth"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// MethodRoles mirrors the role groups the chi router wraps in AuthMiddleware.
+var MethodRoles = map[string][]string{
+	pb.PvzService_OpenPvz_FullMethodName:              {"moderator"},
+	pb.PvzService_GetPvzListWithFilter_FullMethodName: {"employee", "moderator"},
+	pb.PvzService_StartReception_FullMethodName:       {"employee"},
+	pb.PvzService_CloseReception_FullMethodName:       {"employee"},
+	pb.PvzService_AddProduct_FullMethodName:           {"employee"},
+	pb.PvzService_DeleteProduct_FullMethodName:        {"employee"},
+}
+
+func AuthInterceptor(methodRoles map[string][]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requiredRoles, ok := methodRoles[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.PermissionDenied, "Forbidden")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+			return nil, status.Error(codes.PermissionDenied, "Forbidden")
+		}
+
+		claims, err := auth.ParseToken(strings.TrimPrefix(values[0], "Bearer "))
+		if err != nil {
+			return nil, status.Error(codes.PermissionDenied, "Forbidden")
+		}
+		auth.SetActor(ctx, claims)
+
+		if !auth.RoleAllowed(claims.Role, requiredRoles) {
+			return nil, status.Error(codes.PermissionDenied, "Forbidden")
+		}
+
+		ctx = context.WithValue(ctx, "user_info", map[string]interface{}{
+			"id":   claims.Id,
+			"role": claims.Role,
+		})
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"avito_test/api/grpc/pb"
+	"avito_test/domain"
+	"avito_test/usecases"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toPbPvz(pvz domain.Pvz) *pb.Pvz {
+	return &pb.Pvz{
+		Id:               int64(pvz.Id),
+		RegistrationDate: timestamppb.New(pvz.RegistrationDate),
+		City:             pvz.City,
+		Capacity:         int64(pvz.Capacity),
+		TypeCapacity:     toPbCounts(pvz.TypeCapacity),
+	}
+}
+
+func toPbOccupancy(occupancy domain.Occupancy) *pb.Occupancy {
+	return &pb.Occupancy{
+		Total:  int64(occupancy.Total),
+		ByType: toPbCounts(occupancy.ByType),
+	}
+}
+
+func toPbCounts(counts map[string]int) map[string]int64 {
+	if len(counts) == 0 {
+		return nil
+	}
+	result := make(map[string]int64, len(counts))
+	for key, count := range counts {
+		result[key] = int64(count)
+	}
+	return result
+}
+
+func toPbReception(reception domain.Reception) *pb.Reception {
+	return &pb.Reception{
+		Id:        int64(reception.Id),
+		StartDate: timestamppb.New(reception.StartDate),
+		PvzId:     int64(reception.PvzId),
+		Status:    reception.Status,
+		Kind:      reception.Kind,
+	}
+}
+
+func toPbProduct(product domain.Product) *pb.Product {
+	return &pb.Product{
+		Id:                int64(product.Id),
+		DateTime:          timestamppb.New(product.DateTime),
+		Type:              product.Type,
+		TypeName:          product.TypeName,
+		Barcode:           product.Barcode,
+		OrderNumber:       product.OrderNumber,
+		ReturnReason:      product.ReturnReason,
+		OriginalProductId: int64(product.OriginalProductId),
+	}
+}
+
+func toPbPvzWithReceptions(item usecases.PvzWithReceptions) *pb.PvzWithReceptions {
+	return &pb.PvzWithReceptions{
+		Pvz:        toPbPvz(item.Pvz),
+		Receptions: toPbReceptionsWithProducts(item.Receptions),
+		Returns:    toPbReceptionsWithProducts(item.Returns),
+		Occupancy:  toPbOccupancy(item.Occupancy),
+	}
+}
+
+func toPbReceptionsWithProducts(items []domain.ReceptionWithProducts) []*pb.ReceptionWithProducts {
+	receptions := make([]*pb.ReceptionWithProducts, 0, len(items))
+	for _, r := range items {
+		products := make([]*pb.Product, 0, len(r.Products))
+		for _, p := range r.Products {
+			products = append(products, toPbProduct(p))
+		}
+		receptions = append(receptions, &pb.ReceptionWithProducts{
+			Reception: toPbReception(r.Reception),
+			Products:  products,
+		})
+	}
+	return receptions
+}
@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"avito_test/pkg/logger"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps usecase errors to gRPC statuses; timeouts and unexpected errors are
+// logged with the request logger, as they reach the caller without the cause.
+func toStatus(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, usecases.ErrTimeout):
+		logger.FromContext(ctx).Error("Request timeout", "code", codes.DeadlineExceeded.String(), "error", err)
+		return status.Error(codes.DeadlineExceeded, "Request timeout")
+	case errors.Is(err, usecases.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, "Invalid cursor")
+	case errors.Is(err, usecases.ErrInvalidCity):
+		return status.Error(codes.InvalidArgument, "Invalid City")
+	case errors.Is(err, usecases.ErrInvalidProductType):
+		return status.Error(codes.InvalidArgument, "Invalid product type")
+	case errors.Is(err, usecases.ErrInvalidReceptionKind):
+		return status.Error(codes.InvalidArgument, "Invalid reception kind")
+	case errors.Is(err, usecases.ErrReturnDetailsRequired):
+		return status.Error(codes.InvalidArgument, "Return items need a reason and an order or product reference")
+	case errors.Is(err, usecases.ErrUnexpectedReturnDetails):
+		return status.Error(codes.InvalidArgument, "Return details are only accepted in customer returns")
+	case errors.Is(err, usecases.ErrInvalidReturnReference):
+		return status.Error(codes.InvalidArgument, "Original product is not an issued item of the order")
+	case errors.Is(err, usecases.ErrCapacityExceeded):
+		return status.Error(codes.ResourceExhausted, "Pvz capacity exceeded")
+	case errors.Is(err, usecases.ErrCityDisabled):
+		return status.Error(codes.FailedPrecondition, "City is disabled")
+	case errors.Is(err, repository.NotFound):
+		return status.Error(codes.NotFound, "Pvz not found")
+	case errors.Is(err, repository.ErrBarcodeAlreadyExists):
+		return status.Error(codes.AlreadyExists, "Product with this barcode is already in stock")
+	case errors.Is(err, repository.ErrProductNotFound):
+		return status.Error(codes.NotFound, "Product not found in reception")
+	case errors.Is(err, usecases.ErrUnclosedReception):
+		return status.Error(codes.FailedPrecondition, "Unclosed reception")
+	case errors.Is(err, usecases.ErrAlreadyClosed):
+		return status.Error(codes.FailedPrecondition, "Reception already closed")
+	default:
+		logger.FromContext(ctx).Error("Internal Error", "code", codes.Internal.String(), "error", err)
+		return status.Error(codes.Internal, "Internal Error")
+	}
+}
@@ -0,0 +1,251 @@
+package grpc_test
+
+import (
+	grpc2 "avito_test/api/grpc"
+	"avito_test/api/grpc/pb"
+	"avito_test/domain"
+	"avito_test/pkg/testutils"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"avito_test/usecases/mocks"
+	"context"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type services struct {
+	pvz       *mocks.Pvz
+	reception *mocks.Reception
+	product   *mocks.Product
+	audit     *mocks.Audit
+}
+
+func newClient(t *testing.T) (pb.PvzServiceClient, services) {
+	s := services{pvz: new(mocks.Pvz), reception: new(mocks.Reception), product: new(mocks.Product), audit: new(mocks.Audit)}
+	s.audit.On("Record", mock.Anything).Return(nil).Maybe()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc2.NewGRPCServer(grpc2.NewServer(s.pvz, s.reception, s.product), s.audit, nil, slog.Default())
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewPvzServiceClient(conn), s
+}
+
+func withRole(t *testing.T, role string) context.Context {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":   "1",
+		"role": role,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, err := token.SignedString([]byte("secret-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+tokenString)
+}
+
+func TestServer_OpenPvz(t *testing.T) {
+	tests := []struct {
+		name         string
+		role         string
+		city         string
+		mockSetup    func(*mocks.Pvz)
+		expectedCode codes.Code
+	}{
+		{
+			name: "Success",
+			role: "moderator",
+			city: "Москва",
+			mockSetup: func(m *mocks.Pvz) {
+				m.On("OpenPvz", "Москва").Return(testutils.MockPvz(), nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Employee forbidden",
+			role:         "employee",
+			city:         "Москва",
+			mockSetup:    func(m *mocks.Pvz) {},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name: "Invalid city",
+			role: "moderator",
+			city: "Новосибирск",
+			mockSetup: func(m *mocks.Pvz) {
+				m.On("OpenPvz", "Новосибирск").Return(domain.Pvz{}, usecases.ErrInvalidCity)
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, s := newClient(t)
+			tt.mockSetup(s.pvz)
+
+			resp, err := client.OpenPvz(withRole(t, tt.role), &pb.OpenPvzRequest{City: tt.city})
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, int64(1), resp.GetId())
+				assert.Equal(t, "Москва", resp.GetCity())
+			}
+			s.pvz.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_MissingToken(t *testing.T) {
+	client, _ := newClient(t)
+
+	_, err := client.GetPvzListWithFilter(context.Background(), &pb.GetPvzListRequest{})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_GetPvzListWithFilter(t *testing.T) {
+	client, s := newClient(t)
+	s.pvz.On("GetPvzListWithFilter", usecases.PvzFilter{Page: 1, Limit: 10, Cursor: "next"}).Return(usecases.PvzPage{
+		Items: []usecases.PvzWithReceptions{
+			{
+				Pvz: testutils.MockPvz(),
+				Receptions: []domain.ReceptionWithProducts{
+					{Reception: testutils.MockReception(), Products: []domain.Product{testutils.MockProduct()}},
+				},
+			},
+		},
+		Total:      3,
+		NextCursor: "after",
+	}, nil)
+
+	resp, err := client.GetPvzListWithFilter(withRole(t, "employee"), &pb.GetPvzListRequest{Cursor: "next"})
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, resp.GetTotal())
+	assert.Equal(t, "after", resp.GetNextCursor())
+	assert.Len(t, resp.GetItems(), 1)
+	assert.Len(t, resp.GetItems()[0].GetReceptions(), 1)
+	assert.Len(t, resp.GetItems()[0].GetReceptions()[0].GetProducts(), 1)
+	s.pvz.AssertExpectations(t)
+}
+
+func TestServer_StartReception(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockErr      error
+		expectedCode codes.Code
+	}{
+		{name: "Success", mockErr: nil, expectedCode: codes.OK},
+		{name: "Unclosed reception", mockErr: usecases.ErrUnclosedReception, expectedCode: codes.FailedPrecondition},
+		{name: "Pvz not found", mockErr: repository.NotFound, expectedCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, s := newClient(t)
+			reception := domain.Reception{}
+			if tt.mockErr == nil {
+				reception = testutils.MockReception()
+			}
+			s.reception.On("StartReception", 1, domain.ReceptionKindDelivery).Return(reception, tt.mockErr)
+
+			_, err := client.StartReception(withRole(t, "employee"), &pb.StartReceptionRequest{PvzId: 1})
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			s.reception.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_AddAndDeleteProduct(t *testing.T) {
+	client, s := newClient(t)
+	s.product.On("AddProduct", usecases.NewProduct{Type: "электроника"}, 1).Return(testutils.MockProduct(), nil)
+	s.product.On("DeleteProduct", 1).Return(usecases.ErrAlreadyClosed)
+
+	product, err := client.AddProduct(withRole(t, "employee"), &pb.AddProductRequest{PvzId: 1, Type: "электроника"})
+	assert.NoError(t, err)
+	assert.Equal(t, "electronics", product.GetType())
+	assert.Equal(t, "электроника", product.GetTypeName())
+
+	_, err = client.DeleteProduct(withRole(t, "employee"), &pb.DeleteProductRequest{PvzId: 1})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.AddProduct(withRole(t, "moderator"), &pb.AddProductRequest{PvzId: 1, Type: "электроника"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	s.product.AssertExpectations(t)
+}
+
+func TestServer_Audit(t *testing.T) {
+	client, s := newClient(t)
+	s.pvz.On("OpenPvz", "Москва").Return(testutils.MockPvz(), nil)
+
+	// No proxy is trusted, so the forwarded address is the caller's to forge.
+	spoofed := metadata.AppendToOutgoingContext(withRole(t, "moderator"), "x-forwarded-for", "203.0.113.5")
+	_, err := client.OpenPvz(spoofed, &pb.OpenPvzRequest{City: "Москва"})
+	assert.NoError(t, err)
+	_, err = client.OpenPvz(withRole(t, "employee"), &pb.OpenPvzRequest{City: "Москва"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetPvzListWithFilter(context.Background(), &pb.GetPvzListRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Reads are not recorded.
+	if !assert.Len(t, s.audit.Calls, 2) {
+		return
+	}
+	opened := s.audit.Calls[0].Arguments.Get(0).(domain.AuditEntry)
+	assert.Equal(t, domain.AuditPvzOpen, opened.Action)
+	assert.Equal(t, "1", opened.ActorId)
+	assert.Equal(t, "moderator", opened.Role)
+	assert.Equal(t, http.StatusOK, opened.Status)
+	assert.Equal(t, map[string]string{domain.AuditTargetPvz: "1"}, opened.TargetIds)
+	assert.JSONEq(t, `{"city":"Москва"}`, string(opened.Payload))
+	assert.Equal(t, "bufconn", opened.IP)
+
+	refused := s.audit.Calls[1].Arguments.Get(0).(domain.AuditEntry)
+	assert.Equal(t, "employee", refused.Role)
+	assert.Equal(t, http.StatusForbidden, refused.Status)
+	assert.Empty(t, refused.TargetIds)
+}
+
+func TestServer_RequestId(t *testing.T) {
+	client, s := newClient(t)
+	s.pvz.On("OpenPvz", "Москва").Return(testutils.MockPvz(), nil)
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(withRole(t, "moderator"), "x-request-id", "req-1")
+	_, err := client.OpenPvz(ctx, &pb.OpenPvzRequest{City: "Москва"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"req-1"}, header.Get("x-request-id"))
+
+	_, err = client.OpenPvz(withRole(t, "moderator"), &pb.OpenPvzRequest{City: "Москва"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Len(t, header.Get("x-request-id"), 1)
+	assert.NotEqual(t, "req-1", header.Get("x-request-id")[0])
+}
@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"avito_test/pkg/logger"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"log/slog"
+)
+
+// maxRequestIdLength bounds a client supplied request id; longer ones are replaced.
+const maxRequestIdLength = 128
+
+// RequestIdInterceptor propagates the x-request-id metadata of the caller or assigns a
+// new id, sends it back in the response header and puts a logger tagged with it into ctx.
+func RequestIdInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var requestId string
+		md, _ := metadata.FromIncomingContext(ctx)
+		if ids := md.Get(logger.RequestIdHeader); len(ids) > 0 && len(ids[0]) <= maxRequestIdLength {
+			requestId = ids[0]
+		}
+		if requestId == "" {
+			requestId = logger.NewRequestId()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(logger.RequestIdHeader, requestId))
+
+		return handler(logger.WithLogger(ctx, log.With("requestId", requestId, "grpcMethod", info.FullMethod)), req)
+	}
+}
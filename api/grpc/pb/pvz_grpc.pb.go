@@ -0,0 +1,311 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: pvz.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PvzService_OpenPvz_FullMethodName              = "/pvz.v1.PvzService/OpenPvz"
+	PvzService_GetPvzListWithFilter_FullMethodName = "/pvz.v1.PvzService/GetPvzListWithFilter"
+	PvzService_StartReception_FullMethodName       = "/pvz.v1.PvzService/StartReception"
+	PvzService_CloseReception_FullMethodName       = "/pvz.v1.PvzService/CloseReception"
+	PvzService_AddProduct_FullMethodName           = "/pvz.v1.PvzService/AddProduct"
+	PvzService_DeleteProduct_FullMethodName        = "/pvz.v1.PvzService/DeleteProduct"
+)
+
+// PvzServiceClient is the client API for PvzService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PvzServiceClient interface {
+	OpenPvz(ctx context.Context, in *OpenPvzRequest, opts ...grpc.CallOption) (*Pvz, error)
+	GetPvzListWithFilter(ctx context.Context, in *GetPvzListRequest, opts ...grpc.CallOption) (*GetPvzListResponse, error)
+	StartReception(ctx context.Context, in *StartReceptionRequest, opts ...grpc.CallOption) (*Reception, error)
+	CloseReception(ctx context.Context, in *CloseReceptionRequest, opts ...grpc.CallOption) (*Reception, error)
+	AddProduct(ctx context.Context, in *AddProductRequest, opts ...grpc.CallOption) (*Product, error)
+	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error)
+}
+
+type pvzServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPvzServiceClient(cc grpc.ClientConnInterface) PvzServiceClient {
+	return &pvzServiceClient{cc}
+}
+
+func (c *pvzServiceClient) OpenPvz(ctx context.Context, in *OpenPvzRequest, opts ...grpc.CallOption) (*Pvz, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Pvz)
+	err := c.cc.Invoke(ctx, PvzService_OpenPvz_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pvzServiceClient) GetPvzListWithFilter(ctx context.Context, in *GetPvzListRequest, opts ...grpc.CallOption) (*GetPvzListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPvzListResponse)
+	err := c.cc.Invoke(ctx, PvzService_GetPvzListWithFilter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pvzServiceClient) StartReception(ctx context.Context, in *StartReceptionRequest, opts ...grpc.CallOption) (*Reception, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reception)
+	err := c.cc.Invoke(ctx, PvzService_StartReception_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pvzServiceClient) CloseReception(ctx context.Context, in *CloseReceptionRequest, opts ...grpc.CallOption) (*Reception, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reception)
+	err := c.cc.Invoke(ctx, PvzService_CloseReception_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pvzServiceClient) AddProduct(ctx context.Context, in *AddProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Product)
+	err := c.cc.Invoke(ctx, PvzService_AddProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pvzServiceClient) DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProductResponse)
+	err := c.cc.Invoke(ctx, PvzService_DeleteProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PvzServiceServer is the server API for PvzService service.
+// All implementations must embed UnimplementedPvzServiceServer
+// for forward compatibility.
+type PvzServiceServer interface {
+	OpenPvz(context.Context, *OpenPvzRequest) (*Pvz, error)
+	GetPvzListWithFilter(context.Context, *GetPvzListRequest) (*GetPvzListResponse, error)
+	StartReception(context.Context, *StartReceptionRequest) (*Reception, error)
+	CloseReception(context.Context, *CloseReceptionRequest) (*Reception, error)
+	AddProduct(context.Context, *AddProductRequest) (*Product, error)
+	DeleteProduct(context.Context, *DeleteProductRequest) (*DeleteProductResponse, error)
+	mustEmbedUnimplementedPvzServiceServer()
+}
+
+// UnimplementedPvzServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPvzServiceServer struct{}
+
+func (UnimplementedPvzServiceServer) OpenPvz(context.Context, *OpenPvzRequest) (*Pvz, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OpenPvz not implemented")
+}
+func (UnimplementedPvzServiceServer) GetPvzListWithFilter(context.Context, *GetPvzListRequest) (*GetPvzListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPvzListWithFilter not implemented")
+}
+func (UnimplementedPvzServiceServer) StartReception(context.Context, *StartReceptionRequest) (*Reception, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartReception not implemented")
+}
+func (UnimplementedPvzServiceServer) CloseReception(context.Context, *CloseReceptionRequest) (*Reception, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseReception not implemented")
+}
+func (UnimplementedPvzServiceServer) AddProduct(context.Context, *AddProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddProduct not implemented")
+}
+func (UnimplementedPvzServiceServer) DeleteProduct(context.Context, *DeleteProductRequest) (*DeleteProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProduct not implemented")
+}
+func (UnimplementedPvzServiceServer) mustEmbedUnimplementedPvzServiceServer() {}
+func (UnimplementedPvzServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafePvzServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PvzServiceServer will
+// result in compilation errors.
+type UnsafePvzServiceServer interface {
+	mustEmbedUnimplementedPvzServiceServer()
+}
+
+func RegisterPvzServiceServer(s grpc.ServiceRegistrar, srv PvzServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPvzServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PvzService_ServiceDesc, srv)
+}
+
+func _PvzService_OpenPvz_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OpenPvzRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PvzServiceServer).OpenPvz(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PvzService_OpenPvz_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PvzServiceServer).OpenPvz(ctx, req.(*OpenPvzRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PvzService_GetPvzListWithFilter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPvzListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PvzServiceServer).GetPvzListWithFilter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PvzService_GetPvzListWithFilter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PvzServiceServer).GetPvzListWithFilter(ctx, req.(*GetPvzListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PvzService_StartReception_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartReceptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PvzServiceServer).StartReception(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PvzService_StartReception_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PvzServiceServer).StartReception(ctx, req.(*StartReceptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PvzService_CloseReception_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseReceptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PvzServiceServer).CloseReception(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PvzService_CloseReception_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PvzServiceServer).CloseReception(ctx, req.(*CloseReceptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PvzService_AddProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PvzServiceServer).AddProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PvzService_AddProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PvzServiceServer).AddProduct(ctx, req.(*AddProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PvzService_DeleteProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PvzServiceServer).DeleteProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PvzService_DeleteProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PvzServiceServer).DeleteProduct(ctx, req.(*DeleteProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PvzService_ServiceDesc is the grpc.ServiceDesc for PvzService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PvzService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pvz.v1.PvzService",
+	HandlerType: (*PvzServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "OpenPvz",
+			Handler:    _PvzService_OpenPvz_Handler,
+		},
+		{
+			MethodName: "GetPvzListWithFilter",
+			Handler:    _PvzService_GetPvzListWithFilter_Handler,
+		},
+		{
+			MethodName: "StartReception",
+			Handler:    _PvzService_StartReception_Handler,
+		},
+		{
+			MethodName: "CloseReception",
+			Handler:    _PvzService_CloseReception_Handler,
+		},
+		{
+			MethodName: "AddProduct",
+			Handler:    _PvzService_AddProduct_Handler,
+		},
+		{
+			MethodName: "DeleteProduct",
+			Handler:    _PvzService_DeleteProduct_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pvz.proto",
+}
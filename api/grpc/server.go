@@ -0,0 +1,143 @@
+package grpc
+
+//go:generate protoc -I proto --go_out=pb --go_opt=paths=source_relative --go-grpc_out=pb --go-grpc_opt=paths=source_relative pvz.proto
+
+import (
+	"avito_test/api/grpc/pb"
+	"avito_test/domain"
+	"avito_test/pkg/clientip"
+	"avito_test/usecases"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type Server struct {
+	pb.UnimplementedPvzServiceServer
+	Pvz       usecases.Pvz
+	Reception usecases.Reception
+	Product   usecases.Product
+}
+
+func NewServer(pvz usecases.Pvz, reception usecases.Reception, product usecases.Product) *Server {
+	return &Server{Pvz: pvz, Reception: reception, Product: product}
+}
+
+func NewGRPCServer(s *Server, audit usecases.Audit, proxies clientip.TrustedProxies, log *slog.Logger) *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		RequestIdInterceptor(log),
+		AuditInterceptor(audit, proxies),
+		AuthInterceptor(MethodRoles),
+	))
+	pb.RegisterPvzServiceServer(grpcServer, s)
+	return grpcServer
+}
+
+func (s *Server) OpenPvz(ctx context.Context, req *pb.OpenPvzRequest) (*pb.Pvz, error) {
+	if req.GetCity() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Invalid City")
+	}
+
+	pvz, err := s.Pvz.OpenPvz(ctx, req.GetCity())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toPbPvz(pvz), nil
+}
+
+func (s *Server) GetPvzListWithFilter(ctx context.Context, req *pb.GetPvzListRequest) (*pb.GetPvzListResponse, error) {
+	var startDate, endDate *time.Time
+	if req.GetStartDate() != nil {
+		t := req.GetStartDate().AsTime()
+		startDate = &t
+	}
+	if req.GetEndDate() != nil {
+		t := req.GetEndDate().AsTime()
+		endDate = &t
+	}
+
+	page, limit := int(req.GetPage()), int(req.GetLimit())
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = usecases.DefaultPvzListLimit
+	}
+
+	pvzPage, err := s.Pvz.GetPvzListWithFilter(ctx, usecases.PvzFilter{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Page:      page,
+		Limit:     limit,
+		Cursor:    req.GetCursor(),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &pb.GetPvzListResponse{
+		Items:      make([]*pb.PvzWithReceptions, 0, len(pvzPage.Items)),
+		Total:      int32(pvzPage.Total),
+		NextCursor: pvzPage.NextCursor,
+	}
+	for _, item := range pvzPage.Items {
+		resp.Items = append(resp.Items, toPbPvzWithReceptions(item))
+	}
+	return resp, nil
+}
+
+func (s *Server) StartReception(ctx context.Context, req *pb.StartReceptionRequest) (*pb.Reception, error) {
+	if req.GetPvzId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "pvzId is required")
+	}
+
+	kind := req.GetKind()
+	if kind == "" {
+		kind = domain.ReceptionKindDelivery
+	}
+	reception, err := s.Reception.StartReception(ctx, int(req.GetPvzId()), kind)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toPbReception(reception), nil
+}
+
+func (s *Server) CloseReception(ctx context.Context, req *pb.CloseReceptionRequest) (*pb.Reception, error) {
+	reception, err := s.Reception.CloseReception(ctx, int(req.GetPvzId()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toPbReception(reception), nil
+}
+
+func (s *Server) AddProduct(ctx context.Context, req *pb.AddProductRequest) (*pb.Product, error) {
+	if req.GetType() == "" || req.GetPvzId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Type and pvzId are required")
+	}
+
+	product, err := s.Product.AddProduct(ctx, usecases.NewProduct{
+		Type:              req.GetType(),
+		Barcode:           strings.TrimSpace(req.GetBarcode()),
+		OrderNumber:       strings.TrimSpace(req.GetOrderNumber()),
+		ReturnReason:      req.GetReturnReason(),
+		OriginalProductId: int(req.GetOriginalProductId()),
+	}, int(req.GetPvzId()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toPbProduct(product), nil
+}
+
+func (s *Server) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	if err := s.Product.DeleteProduct(ctx, int(req.GetPvzId())); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &pb.DeleteProductResponse{}, nil
+}
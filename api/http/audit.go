@@ -0,0 +1,58 @@
+package http
+
+import (
+	"avito_test/api/http/types"
+	"avito_test/usecases"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type Audit struct {
+	Service usecases.Audit
+}
+
+func NewAuditHandler(service usecases.Audit) *Audit {
+	return &Audit{Service: service}
+}
+
+func (a *Audit) ListAuditHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateListAuditHandlerRequest(r)
+	if errors.Is(err, types.ErrInvalidPvzId) {
+		http.Error(w, "Invalid pvzId", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Invalid request parameters", http.StatusBadRequest)
+		return
+	}
+
+	page, err := a.Service.List(r.Context(), usecases.AuditFilter{
+		ActorId: req.ActorId,
+		Role:    req.Role,
+		Action:  req.Action,
+		PvzId:   req.PvzId,
+		From:    req.From,
+		To:      req.To,
+		Page:    req.Page,
+		Limit:   req.Limit,
+	})
+	switch {
+	case errors.Is(err, usecases.ErrTimeout):
+		serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		return
+	case err != nil:
+		serverError(w, r, err, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if err := json.NewEncoder(w).Encode(page.Items); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+	}
+}
+
+func (a *Audit) WithAuditHandlers(r chi.Router) {
+	r.Get("/audit", a.ListAuditHandler)
+}
@@ -34,7 +34,7 @@ func (u *User) DummyLoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	token, err := u.Service.GetToken("1", req.Role)
 
-	types.AuthError(w, err, types.LoginHandlerResponse{Token: token})
+	types.AuthError(w, r, err, types.LoginHandlerResponse{Token: token})
 }
 
 func (u *User) RegisterHandler(w http.ResponseWriter, r *http.Request) {
@@ -55,18 +55,21 @@ func (u *User) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := u.Service.Register(req.Email, req.Password, req.Role)
-	if errors.Is(err, repository.ErrEmailAlreadyExists) {
+	user, err := u.Service.Register(r.Context(), req.Email, req.Password, req.Role)
+	if errors.Is(err, usecases.ErrTimeout) {
+		serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		return
+	} else if errors.Is(err, repository.ErrEmailAlreadyExists) {
 		http.Error(w, "Email already exists", http.StatusBadRequest)
 		return
 	} else if err != nil {
-		http.Error(w, "Internal Error", http.StatusInternalServerError)
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(user); err != nil {
-		http.Error(w, "Internal Error", http.StatusInternalServerError)
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		return
 	}
 }
@@ -78,9 +81,9 @@ func (u *User) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := u.Service.Login(req.Email, req.Password)
+	token, err := u.Service.Login(r.Context(), req.Email, req.Password)
 
-	types.AuthError(w, err, types.LoginHandlerResponse{Token: token})
+	types.AuthError(w, r, err, types.LoginHandlerResponse{Token: token})
 }
 
 func (u *User) WithUserHandlers(r chi.Router) {
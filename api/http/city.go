@@ -0,0 +1,121 @@
+package http
+
+import (
+	"avito_test/api/http/types"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type City struct {
+	Service usecases.City
+}
+
+func NewCityHandler(service usecases.City) *City {
+	return &City{Service: service}
+}
+
+func (c *City) CreateCityHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateCreateCityHandlerRequest(r)
+	if errors.Is(err, types.ErrInvalidCity) {
+		http.Error(w, "City name is required", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	city, err := c.Service.CreateCity(r.Context(), req.Name)
+	if err != nil {
+		cityError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(city); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *City) ListCitiesHandler(w http.ResponseWriter, r *http.Request) {
+	cities, err := c.Service.ListCities(r.Context())
+	if err != nil {
+		cityError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(cities); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *City) UpdateCityHandler(w http.ResponseWriter, r *http.Request) {
+	cityId, err := strconv.Atoi(chi.URLParam(r, "cityId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	req, err := types.CreateUpdateCityHandlerRequest(r)
+	if errors.Is(err, types.ErrInvalidCity) {
+		http.Error(w, "City name must not be empty", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	city, err := c.Service.UpdateCity(r.Context(), cityId, req.Name, req.Enabled)
+	if err != nil {
+		cityError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(city); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *City) DeleteCityHandler(w http.ResponseWriter, r *http.Request) {
+	cityId, err := strconv.Atoi(chi.URLParam(r, "cityId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Service.DeleteCity(r.Context(), cityId); err != nil {
+		cityError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func cityError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrTimeout):
+		serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+	case errors.Is(err, repository.NotFound):
+		http.Error(w, "City not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrCityAlreadyExists):
+		http.Error(w, "City already exists", http.StatusConflict)
+	case errors.Is(err, repository.ErrCityInUse):
+		http.Error(w, "City has pvz, disable it instead", http.StatusConflict)
+	default:
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+	}
+}
+
+func (c *City) WithCityHandlers(r chi.Router) {
+	r.Get("/cities", c.ListCitiesHandler)
+	r.Post("/cities", c.CreateCityHandler)
+	r.Patch("/cities/{cityId}", c.UpdateCityHandler)
+	r.Delete("/cities/{cityId}", c.DeleteCityHandler)
+}
@@ -0,0 +1,12 @@
+package http
+
+import (
+	"avito_test/pkg/logger"
+	"net/http"
+)
+
+// serverError answers with a 5xx status and logs the error behind it with the request logger.
+func serverError(w http.ResponseWriter, r *http.Request, err error, message string, code int) {
+	logger.FromContext(r.Context()).Error(message, "status", code, "method", r.Method, "path", r.URL.Path, "error", err)
+	http.Error(w, message, code)
+}
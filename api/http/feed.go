@@ -0,0 +1,102 @@
+package http
+
+import (
+	"avito_test/pkg"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// heartbeatInterval keeps idle streams from being closed by proxies and load balancers.
+const heartbeatInterval = 15 * time.Second
+
+type Feed struct {
+	Service usecases.ReceptionFeed
+}
+
+func NewFeedHandler(service usecases.ReceptionFeed) *Feed {
+	return &Feed{Service: service}
+}
+
+// EventsHandler streams the intake progress of a PVZ as Server-Sent Events; the SSE
+// event name is the progress type, the id is the outbox event id and the data is the
+// progress as JSON.
+func (f *Feed) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	pvzId, err := strconv.Atoi(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	// A reconnecting EventSource sends the id of the last event it got.
+	var lastEventId int
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		if lastEventId, err = strconv.Atoi(header); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	progress, err := f.Service.Subscribe(r.Context(), pvzId, lastEventId)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Pvz not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	_ = rc.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	// The client reconnects with Last-Event-ID, to another instance if this one stops,
+	// and is replayed what it missed.
+	draining := pkg.Draining(r.Context())
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-draining:
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case p, ok := <-progress:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(p)
+			if err != nil {
+				return
+			}
+			if p.EventId != 0 {
+				_, _ = fmt.Fprintf(w, "id: %d\n", p.EventId)
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", p.Type, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
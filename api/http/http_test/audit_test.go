@@ -0,0 +1,159 @@
+package http_test
+
+import (
+	http2 "avito_test/api/http"
+	"avito_test/domain"
+	"avito_test/pkg/clientip"
+	"avito_test/usecases"
+	"avito_test/usecases/mocks"
+	"bytes"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	mockAudit := new(mocks.Audit)
+	var entries []domain.AuditEntry
+	mockAudit.On("Record", mock.Anything).Run(func(args mock.Arguments) {
+		entries = append(entries, args.Get(0).(domain.AuditEntry))
+	}).Return(nil)
+
+	// httptest requests come from 192.0.2.1.
+	proxies, err := clientip.ParseTrustedProxies([]string{"192.0.2.1", "10.0.0.0/8"})
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	r.Use(http2.AuditMiddleware(r, mockAudit, proxies))
+	r.Post("/login", func(w http.ResponseWriter, r *http.Request) {})
+	r.Route("/", func(r chi.Router) {
+		r.With(http2.AuthMiddleware([]string{"employee"})).Post("/receptions", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"pvzId": "3"}`, string(body))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 7, "pvzId": 3}`))
+		})
+		r.With(http2.AuthMiddleware([]string{"employee"})).Post("/pvz/{pvzId}/delete_last_product", func(w http.ResponseWriter, r *http.Request) {})
+		r.With(http2.AuthMiddleware([]string{"employee"})).Get("/pvz/{pvzId}/overdue", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	token, err := generateTestToken("42", "employee")
+	require.NoError(t, err)
+	moderatorToken, err := generateTestToken("9", "moderator")
+	require.NoError(t, err)
+	send := func(method, path, body, token, remoteAddr string) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.5, 10.0.0.1")
+		if remoteAddr != "" {
+			req.RemoteAddr = remoteAddr
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send("POST", "/receptions", `{"pvzId": "3"}`, token, "")
+	send("POST", "/pvz/5/delete_last_product", ``, moderatorToken, "")
+	send("POST", "/login", `{"email": "a@b.c", "password": "qwerty"}`, "", "")
+	send("GET", "/pvz/5/overdue", ``, token, "")
+	send("POST", "/unknown", ``, token, "")
+	// A caller that is not a trusted proxy cannot pick the recorded IP.
+	send("POST", "/receptions", `{"pvzId": "3"}`, token, "198.51.100.9:5555")
+
+	require.Len(t, entries, 3)
+	started := entries[0]
+	assert.Equal(t, domain.AuditReceptionStart, started.Action)
+	assert.Equal(t, "42", started.ActorId)
+	assert.Equal(t, "employee", started.Role)
+	assert.Equal(t, map[string]string{domain.AuditTargetReception: "7"}, started.TargetIds)
+	assert.JSONEq(t, `{"pvzId": "3"}`, string(started.Payload))
+	assert.Equal(t, http.StatusCreated, started.Status)
+	assert.Equal(t, "203.0.113.5", started.IP)
+
+	refused := entries[1]
+	assert.Equal(t, domain.AuditProductDelete, refused.Action)
+	assert.Equal(t, "9", refused.ActorId)
+	assert.Equal(t, "moderator", refused.Role)
+	assert.Equal(t, map[string]string{domain.AuditTargetPvz: "5"}, refused.TargetIds)
+	assert.Equal(t, http.StatusForbidden, refused.Status)
+
+	assert.Equal(t, "198.51.100.9", entries[2].IP)
+}
+
+func TestAuditHandler_ListAudit(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		mockSetup     func(*mocks.Audit)
+		expectedCode  int
+		expectedTotal string
+	}{
+		{
+			name:  "Defaults",
+			query: "",
+			mockSetup: func(m *mocks.Audit) {
+				m.On("List", usecases.AuditFilter{Page: 1, Limit: 10}).
+					Return(usecases.AuditPage{Items: []domain.AuditEntry{{Id: 1}}, Total: 1}, nil)
+			},
+			expectedCode:  http.StatusOK,
+			expectedTotal: "1",
+		},
+		{
+			name:  "Filters",
+			query: "?actorId=42&role=employee&action=product.add&pvzId=3&from=2025-01-01T00:00:00Z&page=2&limit=500",
+			mockSetup: func(m *mocks.Audit) {
+				from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+				m.On("List", mock.MatchedBy(func(f usecases.AuditFilter) bool {
+					return f.ActorId == "42" && f.Role == "employee" && f.Action == domain.AuditProductAdd && f.PvzId == 3 &&
+						f.From != nil && f.From.Equal(from) && f.To == nil && f.Page == 2 && f.Limit == 100
+				})).Return(usecases.AuditPage{Items: []domain.AuditEntry{}, Total: 120}, nil)
+			},
+			expectedCode:  http.StatusOK,
+			expectedTotal: "120",
+		},
+		{
+			name:         "Invalid pvzId",
+			query:        "?pvzId=abc",
+			mockSetup:    func(m *mocks.Audit) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid date",
+			query:        "?to=yesterday",
+			mockSetup:    func(m *mocks.Audit) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "Storage failure",
+			query: "",
+			mockSetup: func(m *mocks.Audit) {
+				m.On("List", mock.Anything).Return(usecases.AuditPage{}, errors.New("db down"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Audit)
+			tt.mockSetup(mockService)
+			handler := http2.NewAuditHandler(mockService)
+
+			req := httptest.NewRequest("GET", "/audit"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			handler.WithAuditHandlers(r)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Equal(t, tt.expectedTotal, rec.Header().Get("X-Total-Count"))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
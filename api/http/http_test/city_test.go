@@ -0,0 +1,123 @@
+package http_test
+
+import (
+	http2 "avito_test/api/http"
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases/mocks"
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCityHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		requestBody  string
+		mockSetup    func(*mocks.City)
+		expectedCode int
+	}{
+		{
+			name:   "Create city",
+			method: "POST",
+			url:    "/cities",
+			// Surrounding spaces are trimmed before the name reaches the service.
+			requestBody: `{"name": " Новосибирск "}`,
+			mockSetup: func(m *mocks.City) {
+				m.On("CreateCity", "Новосибирск").Return(domain.City{Id: 4, Name: "Новосибирск", Enabled: true}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "Create duplicate city",
+			method:      "POST",
+			url:         "/cities",
+			requestBody: `{"name": "Казань"}`,
+			mockSetup: func(m *mocks.City) {
+				m.On("CreateCity", "Казань").Return(domain.City{}, repository.ErrCityAlreadyExists)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Create without name",
+			method:       "POST",
+			url:          "/cities",
+			requestBody:  `{"name": ""}`,
+			mockSetup:    func(m *mocks.City) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "List cities",
+			method: "GET",
+			url:    "/cities",
+			mockSetup: func(m *mocks.City) {
+				m.On("ListCities").Return([]domain.City{{Id: 1, Name: "Москва", Enabled: true}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "Disable city",
+			method:      "PATCH",
+			url:         "/cities/3",
+			requestBody: `{"enabled": false}`,
+			mockSetup: func(m *mocks.City) {
+				m.On("UpdateCity", 3, (*string)(nil), mock.MatchedBy(func(enabled *bool) bool {
+					return enabled != nil && !*enabled
+				})).Return(domain.City{Id: 3, Name: "Казань", Enabled: false}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "Update missing city",
+			method:      "PATCH",
+			url:         "/cities/9",
+			requestBody: `{"enabled": true}`,
+			mockSetup: func(m *mocks.City) {
+				m.On("UpdateCity", 9, (*string)(nil), mock.Anything).Return(domain.City{}, repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "Delete city with pvz",
+			method: "DELETE",
+			url:    "/cities/1",
+			mockSetup: func(m *mocks.City) {
+				m.On("DeleteCity", 1).Return(repository.ErrCityInUse)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "Delete city",
+			method: "DELETE",
+			url:    "/cities/4",
+			mockSetup: func(m *mocks.City) {
+				m.On("DeleteCity", 4).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.City)
+			tt.mockSetup(mockService)
+			handler := http2.NewCityHandler(mockService)
+
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			handler.WithCityHandlers(r)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
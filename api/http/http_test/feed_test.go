@@ -0,0 +1,73 @@
+package http_test
+
+import (
+	http2 "avito_test/api/http"
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFeedHandler_Events(t *testing.T) {
+	progress := make(chan domain.ReceptionProgress, 2)
+	progress <- domain.ReceptionProgress{Type: "snapshot", ReceptionId: 3, Status: "in_progress", Counts: map[string]int{}}
+	progress <- domain.ReceptionProgress{EventId: 10, Type: domain.EventProductAdded, ReceptionId: 3, Status: "in_progress",
+		ProductId: 4, ProductType: "shoes", Counts: map[string]int{"shoes": 1}, Total: 1}
+	close(progress)
+
+	mockService := new(mocks.ReceptionFeed)
+	mockService.On("Subscribe", 1, 0).Return(progress, nil)
+	mockService.On("Subscribe", 2, 0).Return(nil, repository.NotFound)
+	handler := http2.NewFeedHandler(mockService)
+
+	r := chi.NewRouter()
+	r.Get("/pvz/{pvzId}/events", handler.EventsHandler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/pvz/1/events", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "event: snapshot\n"+
+		`data: {"type":"snapshot","receptionId":3,"status":"in_progress","counts":{},"total":0}`+"\n\n"+
+		"id: 10\n"+
+		"event: product.added\n"+
+		`data: {"eventId":10,"type":"product.added","receptionId":3,"status":"in_progress","productId":4,"productType":"shoes","counts":{"shoes":1},"total":1}`+"\n\n",
+		rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/pvz/2/events", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/pvz/abc/events", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/pvz/1/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestFeedHandler_Events_Resume(t *testing.T) {
+	progress := make(chan domain.ReceptionProgress)
+	close(progress)
+	mockService := new(mocks.ReceptionFeed)
+	mockService.On("Subscribe", 1, 10).Return(progress, nil)
+	handler := http2.NewFeedHandler(mockService)
+
+	r := chi.NewRouter()
+	r.Get("/pvz/{pvzId}/events", handler.EventsHandler)
+	req := httptest.NewRequest("GET", "/pvz/1/events", nil)
+	req.Header.Set("Last-Event-ID", "10")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
@@ -2,11 +2,31 @@ package http_test
 
 import (
 	http2 "avito_test/api/http"
+	"avito_test/config"
+	"avito_test/domain"
+	"avito_test/pkg/logger"
+	"avito_test/repository/prometheus"
+	"avito_test/usecases/mocks"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
+	client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -56,3 +76,134 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 
 	assert.Equal(t, http.StatusForbidden, rec.Code)
 }
+
+func TestTimeoutMiddleware(t *testing.T) {
+	timeouts := config.Timeouts{
+		Default: time.Minute,
+		Routes:  map[string]time.Duration{"GET /pvz/{pvzId}": time.Second},
+	}
+
+	var remaining time.Duration
+	r := chi.NewRouter()
+	r.Use(http2.TimeoutMiddleware(r, timeouts))
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		assert.True(t, ok)
+		remaining = time.Until(deadline)
+	}
+	r.Get("/pvz/{pvzId}", handler)
+	r.Get("/pvz", handler)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pvz/1", nil))
+	assert.LessOrEqual(t, remaining, time.Second)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pvz", nil))
+	assert.Greater(t, remaining, time.Second)
+}
+
+func TestRequestIdMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	r := chi.NewRouter()
+	r.Use(http2.RequestIdMiddleware(slog.New(slog.NewJSONHandler(&buf, nil))))
+	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("pong")
+	})
+
+	req := httptest.NewRequest("GET", "/ping", nil)
+	req.Header.Set(logger.RequestIdHeader, "req-1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, "req-1", rec.Header().Get(logger.RequestIdHeader))
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "pong", record["msg"])
+	assert.Equal(t, "req-1", record["requestId"])
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/ping", nil))
+	assert.Len(t, rec.Header().Get(logger.RequestIdHeader), 32)
+}
+
+func TestServerErrorsAreLogged(t *testing.T) {
+	var buf bytes.Buffer
+	mockService := new(mocks.Pvz)
+	mockService.On("OpenPvz", "Москва").Return(domain.Pvz{}, errors.New("connection reset"))
+	handler := http2.NewPvzHandler(mockService)
+
+	r := chi.NewRouter()
+	r.Use(http2.RequestIdMiddleware(slog.New(slog.NewJSONHandler(&buf, nil))))
+	r.Post("/pvz", handler.OpenPvzHandler)
+
+	req := httptest.NewRequest("POST", "/pvz", bytes.NewBufferString(`{"city": "Москва"}`))
+	req.Header.Set(logger.RequestIdHeader, "req-2")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "req-2", record["requestId"])
+	assert.Equal(t, "connection reset", record["error"])
+	assert.EqualValues(t, http.StatusInternalServerError, record["status"])
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	r := chi.NewRouter()
+	r.Use(http2.TracingMiddleware(r))
+	r.Get("/pvz/{pvzId}", func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, trace.SpanFromContext(r.Context()).SpanContext().IsValid())
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest("GET", "/pvz/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /pvz/{pvzId}", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pvz/1", nil))
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.False(t, spans[1].Parent().IsValid())
+}
+
+func TestPrometheusMiddleware(t *testing.T) {
+	registry := client.NewRegistry()
+	metrics := prometheus.NewMetrics(registry, []float64{0.5, 1})
+
+	r := chi.NewRouter()
+	r.Use(http2.PrometheusMiddleware(r, metrics))
+	r.Get("/pvz/{pvzId}/receptions", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/pvz/1/receptions", "/pvz/2/receptions", "/pvz/3/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	expected := `
+# HELP http_requests_total Total number of HTTP requests
+# TYPE http_requests_total counter
+http_requests_total{method="GET",path="/pvz/{pvzId}/receptions",status="OK"} 2
+http_requests_total{method="GET",path="unmatched",status="Not Found"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "http_requests_total"))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "http_response_time_seconds" {
+			assert.Len(t, family.GetMetric()[0].GetHistogram().GetBucket(), 2)
+		}
+	}
+}
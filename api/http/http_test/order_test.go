@@ -0,0 +1,139 @@
+package http_test
+
+import (
+	http2 "avito_test/api/http"
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"avito_test/usecases/mocks"
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOrderHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		requestBody  string
+		mockSetup    func(*mocks.Order)
+		expectedCode int
+	}{
+		{
+			name:        "Create order",
+			method:      "POST",
+			url:         "/pvz/1/orders",
+			requestBody: `{"orderNumber": " ORD-1 "}`,
+			mockSetup: func(m *mocks.Order) {
+				m.On("CreateOrder", 1, "ORD-1").Return(domain.OrderWithProducts{
+					Order:    domain.Order{Id: 7, PvzId: 1, OrderNumber: "ORD-1", Status: domain.OrderStatusAccepted},
+					Products: []domain.Product{{Id: 3}},
+				}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "Create order without products in stock",
+			method:      "POST",
+			url:         "/pvz/1/orders",
+			requestBody: `{"orderNumber": "ORD-404"}`,
+			mockSetup: func(m *mocks.Order) {
+				m.On("CreateOrder", 1, "ORD-404").Return(domain.OrderWithProducts{}, usecases.ErrOrderEmpty)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Create duplicate order",
+			method:      "POST",
+			url:         "/pvz/1/orders",
+			requestBody: `{"orderNumber": "ORD-1"}`,
+			mockSetup: func(m *mocks.Order) {
+				m.On("CreateOrder", 1, "ORD-1").Return(domain.OrderWithProducts{}, repository.ErrOrderAlreadyExists)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Create order without number",
+			method:       "POST",
+			url:          "/pvz/1/orders",
+			requestBody:  `{}`,
+			mockSetup:    func(m *mocks.Order) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "List ready orders",
+			method: "GET",
+			url:    "/pvz/1/orders?status=ready_for_pickup",
+			mockSetup: func(m *mocks.Order) {
+				m.On("GetOrders", 1, domain.OrderStatusReadyForPickup).Return([]domain.Order{{Id: 7}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "List orders with unknown status",
+			method:       "GET",
+			url:          "/pvz/1/orders?status=lost",
+			mockSetup:    func(m *mocks.Order) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Get missing order",
+			method: "GET",
+			url:    "/orders/9",
+			mockSetup: func(m *mocks.Order) {
+				m.On("GetOrder", 9).Return(domain.OrderWithProducts{}, repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:        "Issue order",
+			method:      "POST",
+			url:         "/orders/7/status",
+			requestBody: `{"status": "issued"}`,
+			mockSetup: func(m *mocks.Order) {
+				m.On("ChangeOrderStatus", 7, domain.OrderStatusIssued).Return(domain.Order{Id: 7, Status: domain.OrderStatusIssued}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "Issue order that is not ready",
+			method:      "POST",
+			url:         "/orders/7/status",
+			requestBody: `{"status": "issued"}`,
+			mockSetup: func(m *mocks.Order) {
+				m.On("ChangeOrderStatus", 7, domain.OrderStatusIssued).Return(domain.Order{}, usecases.ErrInvalidTransition)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Unknown status",
+			method:       "POST",
+			url:          "/orders/7/status",
+			requestBody:  `{"status": "lost"}`,
+			mockSetup:    func(m *mocks.Order) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Order)
+			tt.mockSetup(mockService)
+			handler := http2.NewOrderHandler(mockService)
+
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			handler.WithOrderHandlers(r)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
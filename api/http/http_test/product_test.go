@@ -8,6 +8,7 @@ import (
 	"avito_test/usecases"
 	"avito_test/usecases/mocks"
 	"bytes"
+	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -26,18 +27,83 @@ func TestProductHandler_AddProduct(t *testing.T) {
 			name:        "Success add product",
 			requestBody: `{"type": "электроника", "pvzId": "1"}`,
 			mockSetup: func(m *mocks.Product) {
-				m.On("AddProduct", "электроника", 1).Return(testutils.MockProduct(), nil)
+				m.On("AddProduct", usecases.NewProduct{Type: "электроника"}, 1).Return(testutils.MockProduct(), nil)
 			},
 			expectedCode: http.StatusCreated,
 		},
+		{
+			name:        "Success add product by code",
+			requestBody: `{"type": "electronics", "pvzId": "1"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("AddProduct", usecases.NewProduct{Type: "electronics"}, 1).Return(testutils.MockProduct(), nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "Unknown product type",
+			requestBody: `{"type": "apple", "pvzId": "1"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("AddProduct", usecases.NewProduct{Type: "apple"}, 1).Return(domain.Product{}, usecases.ErrInvalidProductType)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Barcode already in stock",
+			requestBody: `{"type": "shoes", "pvzId": "1", "barcode": " 4601234567890 ", "orderNumber": "ORD-1"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("AddProduct", usecases.NewProduct{Type: "shoes", Barcode: "4601234567890", OrderNumber: "ORD-1"}, 1).
+					Return(domain.Product{}, repository.ErrBarcodeAlreadyExists)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:        "Pvz is full",
+			requestBody: `{"type": "shoes", "pvzId": "1"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("AddProduct", usecases.NewProduct{Type: "shoes"}, 1).Return(domain.Product{}, usecases.ErrCapacityExceeded)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "Customer return item",
+			requestBody: `{"type": "shoes", "pvzId": "1", "returnReason": "defective", "originalProductId": "3"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("AddProduct", usecases.NewProduct{Type: "shoes", ReturnReason: "defective", OriginalProductId: 3}, 1).
+					Return(domain.Product{Id: 7, Type: "shoes", OrderNumber: "ORD-1", ReturnReason: "defective", OriginalProductId: 3}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Unknown return reason",
+			requestBody:  `{"type": "shoes", "pvzId": "1", "returnReason": "bored", "orderNumber": "ORD-1"}`,
+			mockSetup:    func(m *mocks.Product) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Return item without reference",
+			requestBody: `{"type": "shoes", "pvzId": "1", "returnReason": "defective"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("AddProduct", usecases.NewProduct{Type: "shoes", ReturnReason: "defective"}, 1).
+					Return(domain.Product{}, usecases.ErrReturnDetailsRequired)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:        "Reception closed",
 			requestBody: `{"type": "электроника", "pvzId": "1"}`,
 			mockSetup: func(m *mocks.Product) {
-				m.On("AddProduct", "электроника", 1).Return(domain.Product{}, usecases.ErrAlreadyClosed)
+				m.On("AddProduct", usecases.NewProduct{Type: "электроника"}, 1).Return(domain.Product{}, usecases.ErrAlreadyClosed)
 			},
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name:        "Request timeout",
+			requestBody: `{"type": "электроника", "pvzId": "1"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("AddProduct", usecases.NewProduct{Type: "электроника"}, 1).Return(domain.Product{}, usecases.ErrTimeout)
+			},
+			expectedCode: http.StatusGatewayTimeout,
+		},
 	}
 
 	for _, tt := range tests {
@@ -104,6 +170,112 @@ func TestProductHandler_DeleteProduct(t *testing.T) {
 	}
 }
 
+func TestProductHandler_DeleteProductById(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockSetup    func(*mocks.Product)
+		expectedCode int
+	}{
+		{
+			name:        "Success delete product",
+			requestBody: `{"productId": "5", "reason": "mis_scan"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("DeleteProductById", 1, 5, "mis_scan").Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Missing reason",
+			requestBody:  `{"productId": "5"}`,
+			mockSetup:    func(m *mocks.Product) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Reserved reason",
+			requestBody:  `{"productId": "5", "reason": "last_scan"}`,
+			mockSetup:    func(m *mocks.Product) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Product not in reception",
+			requestBody: `{"productId": "5", "reason": "damaged"}`,
+			mockSetup: func(m *mocks.Product) {
+				m.On("DeleteProductById", 1, 5, "damaged").Return(repository.ErrProductNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Product)
+			tt.mockSetup(mockService)
+			handler := http2.NewProductHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/pvz/1/delete_product", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/pvz/{pvzId}/delete_product", handler.DeleteProductByIdHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductHandler_GetProductByBarcode(t *testing.T) {
+	tests := []struct {
+		name         string
+		barcode      string
+		mockLocation domain.ProductLocation
+		mockErr      error
+		expectedCode int
+	}{
+		{
+			name:    "Product in stock",
+			barcode: "4601234567890",
+			mockLocation: domain.ProductLocation{
+				Product:     domain.Product{Id: 5, Type: "shoes", TypeName: "обувь", Barcode: "4601234567890"},
+				ReceptionId: 2,
+				PvzId:       1,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Unknown barcode",
+			barcode:      "0000000000000",
+			mockErr:      repository.NotFound,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Product)
+			mockService.On("GetProductByBarcode", tt.barcode).Return(tt.mockLocation, tt.mockErr)
+			handler := http2.NewProductHandler(mockService)
+
+			req := httptest.NewRequest("GET", "/products/barcode/"+tt.barcode, nil)
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/products/barcode/{barcode}", handler.GetProductByBarcodeHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			if tt.mockErr == nil {
+				var response domain.ProductLocation
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tt.mockLocation, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestProductHandler_AddProduct_InvalidJSON(t *testing.T) {
 	handler := &http2.Product{Service: new(mocks.Product)}
 
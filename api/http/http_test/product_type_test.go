@@ -0,0 +1,150 @@
+package http_test
+
+import (
+	http2 "avito_test/api/http"
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases/mocks"
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProductTypeHandler_CreateProductType(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockSetup    func(*mocks.ProductType)
+		expectedCode int
+	}{
+		{
+			name:        "Create product type",
+			requestBody: `{"code": "books", "name": " книги "}`,
+			mockSetup: func(m *mocks.ProductType) {
+				m.On("CreateProductType", "books", "книги", 0).Return(domain.ProductType{Code: "books", Name: "книги", StorageDays: domain.DefaultStorageDays}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "Duplicate product type",
+			requestBody: `{"code": "shoes", "name": "обувь", "storageDays": 14}`,
+			mockSetup: func(m *mocks.ProductType) {
+				m.On("CreateProductType", "shoes", "обувь", 14).Return(domain.ProductType{}, repository.ErrProductTypeAlreadyExists)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Missing name",
+			requestBody:  `{"code": "books"}`,
+			mockSetup:    func(m *mocks.ProductType) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Negative storage days",
+			requestBody:  `{"code": "books", "name": "книги", "storageDays": -1}`,
+			mockSetup:    func(m *mocks.ProductType) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Code is not latin",
+			requestBody:  `{"code": "книги", "name": "книги"}`,
+			mockSetup:    func(m *mocks.ProductType) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.ProductType)
+			tt.mockSetup(mockService)
+			handler := http2.NewProductTypeHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/product_types", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/product_types", handler.CreateProductTypeHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductTypeHandler_ListProductTypes(t *testing.T) {
+	mockService := new(mocks.ProductType)
+	handler := http2.NewProductTypeHandler(mockService)
+
+	expected := []domain.ProductType{{Code: "clothes", Name: "одежда"}, {Code: "shoes", Name: "обувь"}}
+	mockService.On("ListProductTypes").Return(expected, nil)
+
+	req := httptest.NewRequest("GET", "/product_types", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListProductTypesHandler(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response []domain.ProductType
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, expected, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductTypeHandler_SetStorageDays(t *testing.T) {
+	tests := []struct {
+		name         string
+		code         string
+		requestBody  string
+		mockSetup    func(*mocks.ProductType)
+		expectedCode int
+	}{
+		{
+			name:        "Set storage days",
+			code:        "shoes",
+			requestBody: `{"storageDays": 14}`,
+			mockSetup: func(m *mocks.ProductType) {
+				m.On("SetStorageDays", "shoes", 14).Return(domain.ProductType{Code: "shoes", Name: "обувь", StorageDays: 14}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Zero storage days",
+			code:         "shoes",
+			requestBody:  `{"storageDays": 0}`,
+			mockSetup:    func(m *mocks.ProductType) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Unknown product type",
+			code:        "books",
+			requestBody: `{"storageDays": 14}`,
+			mockSetup: func(m *mocks.ProductType) {
+				m.On("SetStorageDays", "books", 14).Return(domain.ProductType{}, repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.ProductType)
+			tt.mockSetup(mockService)
+			handler := http2.NewProductTypeHandler(mockService)
+
+			req := httptest.NewRequest("PATCH", "/product_types/"+tt.code, bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Patch("/product_types/{code}", handler.SetStorageDaysHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
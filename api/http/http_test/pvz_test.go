@@ -4,6 +4,7 @@ import (
 	http2 "avito_test/api/http"
 	"avito_test/domain"
 	"avito_test/pkg/testutils"
+	"avito_test/repository"
 	"avito_test/usecases"
 	"avito_test/usecases/mocks"
 	"bytes"
@@ -11,7 +12,6 @@ import (
 	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -33,8 +33,24 @@ func TestPvzHandler_OpenPvz(t *testing.T) {
 			expectedCode: http.StatusCreated,
 		},
 		{
-			name:         "Invalid city",
-			requestBody:  `{"city": "Новосибирск"}`,
+			name:        "Unknown city",
+			requestBody: `{"city": "Новосибирск"}`,
+			mockSetup: func(m *mocks.Pvz) {
+				m.On("OpenPvz", "Новосибирск").Return(domain.Pvz{}, usecases.ErrInvalidCity)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Disabled city",
+			requestBody: `{"city": "Казань"}`,
+			mockSetup: func(m *mocks.Pvz) {
+				m.On("OpenPvz", "Казань").Return(domain.Pvz{}, usecases.ErrCityDisabled)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Empty city",
+			requestBody:  `{"city": ""}`,
 			mockSetup:    func(m *mocks.Pvz) {},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -77,8 +93,8 @@ func TestPvzHandler_GetPvzList(t *testing.T) {
 		},
 	}
 
-	mockService.On("GetPvzListWithFilter", mock.Anything, mock.Anything, 1, 10).
-		Return(expectedReceptions, nil)
+	mockService.On("GetPvzListWithFilter", usecases.PvzFilter{Page: 1, Limit: 10}).
+		Return(usecases.PvzPage{Items: expectedReceptions, Total: 25, NextCursor: "next"}, nil)
 
 	req := httptest.NewRequest("GET", "/pvz?page=1&limit=10", nil)
 	rec := httptest.NewRecorder()
@@ -88,6 +104,8 @@ func TestPvzHandler_GetPvzList(t *testing.T) {
 	r.ServeHTTP(rec, req)
 
 	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "25", rec.Header().Get("X-Total-Count"))
+	assert.Equal(t, "next", rec.Header().Get("X-Next-Cursor"))
 
 	var response []usecases.PvzWithReceptions
 	err := json.NewDecoder(rec.Body).Decode(&response)
@@ -98,6 +116,86 @@ func TestPvzHandler_GetPvzList(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestPvzHandler_GetPvzList_InvalidCursor(t *testing.T) {
+	mockService := new(mocks.Pvz)
+	handler := http2.NewPvzHandler(mockService)
+
+	mockService.On("GetPvzListWithFilter", usecases.PvzFilter{Page: 1, Limit: 10, Cursor: "garbage"}).
+		Return(usecases.PvzPage{}, usecases.ErrInvalidCursor)
+
+	req := httptest.NewRequest("GET", "/pvz?cursor=garbage", nil)
+	rec := httptest.NewRecorder()
+
+	handler.GetPvzListHandler(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPvzHandler_SetCapacity(t *testing.T) {
+	tests := []struct {
+		name         string
+		pvzId        string
+		requestBody  string
+		mockSetup    func(*mocks.Pvz)
+		expectedCode int
+	}{
+		{
+			name:        "Set capacity",
+			pvzId:       "1",
+			requestBody: `{"capacity": 100, "typeCapacity": {"shoes": 10}}`,
+			mockSetup: func(m *mocks.Pvz) {
+				m.On("SetCapacity", 1, 100, map[string]int{"shoes": 10}).
+					Return(domain.Pvz{Id: 1, Capacity: 100, TypeCapacity: map[string]int{"shoes": 10}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Negative capacity",
+			pvzId:        "1",
+			requestBody:  `{"capacity": -1}`,
+			mockSetup:    func(m *mocks.Pvz) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Unknown product type",
+			pvzId:       "1",
+			requestBody: `{"typeCapacity": {"apple": 5}}`,
+			mockSetup: func(m *mocks.Pvz) {
+				m.On("SetCapacity", 1, 0, map[string]int{"apple": 5}).Return(domain.Pvz{}, usecases.ErrInvalidProductType)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Pvz not found",
+			pvzId:       "2",
+			requestBody: `{"capacity": 100}`,
+			mockSetup: func(m *mocks.Pvz) {
+				m.On("SetCapacity", 2, 100, map[string]int(nil)).Return(domain.Pvz{}, repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Pvz)
+			tt.mockSetup(mockService)
+			handler := http2.NewPvzHandler(mockService)
+
+			req := httptest.NewRequest("PATCH", "/pvz/"+tt.pvzId+"/capacity", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Patch("/pvz/{pvzId}/capacity", handler.SetCapacityHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestPvzHandler_OpenPvz_DBError(t *testing.T) {
 	mockService := new(mocks.Pvz)
 	handler := http2.NewPvzHandler(mockService)
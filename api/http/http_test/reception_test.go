@@ -4,11 +4,13 @@ import (
 	http2 "avito_test/api/http"
 	"avito_test/domain"
 	"avito_test/pkg/testutils"
+	"avito_test/repository"
 	"avito_test/usecases"
 	"avito_test/usecases/mocks"
 	"bytes"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -25,15 +27,30 @@ func TestReceptionHandler_StartReception(t *testing.T) {
 			name:        "Success start reception",
 			requestBody: `{"pvzId": "1"}`,
 			mockSetup: func(m *mocks.Reception) {
-				m.On("StartReception", 1).Return(testutils.MockReception(), nil)
+				m.On("StartReception", 1, domain.ReceptionKindDelivery).Return(testutils.MockReception(), nil)
 			},
 			expectedCode: http.StatusCreated,
 		},
+		{
+			name:        "Start customer return",
+			requestBody: `{"pvzId": "1", "kind": "customer_return"}`,
+			mockSetup: func(m *mocks.Reception) {
+				m.On("StartReception", 1, domain.ReceptionKindCustomerReturn).
+					Return(domain.Reception{Id: 2, PvzId: 1, Status: "in_progress", Kind: domain.ReceptionKindCustomerReturn}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Unknown reception kind",
+			requestBody:  `{"pvzId": "1", "kind": "transfer"}`,
+			mockSetup:    func(m *mocks.Reception) {},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:        "Unclosed reception exists",
 			requestBody: `{"pvzId": "1"}`,
 			mockSetup: func(m *mocks.Reception) {
-				m.On("StartReception", 1).Return(domain.Reception{}, usecases.ErrUnclosedReception)
+				m.On("StartReception", 1, domain.ReceptionKindDelivery).Return(domain.Reception{}, usecases.ErrUnclosedReception)
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -102,3 +119,108 @@ func TestReceptionHandler_CloseReception(t *testing.T) {
 		})
 	}
 }
+
+func TestReceptionHandler_GetReceptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		mockSetup    func(*mocks.Reception)
+		expectedCode int
+	}{
+		{
+			name: "Success with filter",
+			url:  "/pvz/1/receptions?status=closed&startDate=2025-01-01T00:00:00Z",
+			mockSetup: func(m *mocks.Reception) {
+				m.On("GetReceptions", 1, mock.MatchedBy(func(f usecases.ReceptionFilter) bool {
+					return f.Status == "closed" && f.StartDate != nil && f.EndDate == nil
+				})).Return([]domain.Reception{testutils.MockReception()}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid status",
+			url:          "/pvz/1/receptions?status=open",
+			mockSetup:    func(m *mocks.Reception) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Pvz not found",
+			url:  "/pvz/1/receptions",
+			mockSetup: func(m *mocks.Reception) {
+				m.On("GetReceptions", 1, usecases.ReceptionFilter{}).Return([]domain.Reception(nil), repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Reception)
+			tt.mockSetup(mockService)
+			handler := http2.NewReceptionHandler(mockService)
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/pvz/{pvzId}/receptions", handler.GetReceptionsHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionHandler_GetReception(t *testing.T) {
+	tests := []struct {
+		name         string
+		receptionId  string
+		mockSetup    func(*mocks.Reception)
+		expectedCode int
+	}{
+		{
+			name:        "Success",
+			receptionId: "1",
+			mockSetup: func(m *mocks.Reception) {
+				m.On("GetReception", 1).Return(domain.ReceptionWithProducts{
+					Reception: testutils.MockReception(),
+					Products:  []domain.Product{testutils.MockProduct()},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "Not found",
+			receptionId: "2",
+			mockSetup: func(m *mocks.Reception) {
+				m.On("GetReception", 2).Return(domain.ReceptionWithProducts{}, repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Invalid id",
+			receptionId:  "abc",
+			mockSetup:    func(m *mocks.Reception) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Reception)
+			tt.mockSetup(mockService)
+			handler := http2.NewReceptionHandler(mockService)
+
+			req := httptest.NewRequest("GET", "/receptions/"+tt.receptionId, nil)
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/receptions/{receptionId}", handler.GetReceptionHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
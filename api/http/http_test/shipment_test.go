@@ -0,0 +1,180 @@
+package http_test
+
+import (
+	http2 "avito_test/api/http"
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"avito_test/usecases/mocks"
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestShipmentHandler_CreateShipment(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockSetup    func(*mocks.Shipment)
+		expectedCode int
+	}{
+		{
+			name:        "Ship every overdue product",
+			requestBody: ``,
+			mockSetup: func(m *mocks.Shipment) {
+				m.On("CreateShipment", 1, []int(nil)).Return(domain.ReturnShipmentWithProducts{
+					Shipment: domain.ReturnShipment{Id: 1, PvzId: 1},
+					Products: []domain.Product{{Id: 3}},
+				}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "Ship selected products",
+			requestBody: `{"productIds": [3, 5]}`,
+			mockSetup: func(m *mocks.Shipment) {
+				m.On("CreateShipment", 1, []int{3, 5}).Return(domain.ReturnShipmentWithProducts{
+					Shipment: domain.ReturnShipment{Id: 1, PvzId: 1},
+				}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "Nothing overdue",
+			requestBody: ``,
+			mockSetup: func(m *mocks.Shipment) {
+				m.On("CreateShipment", 1, []int(nil)).Return(domain.ReturnShipmentWithProducts{}, usecases.ErrShipmentEmpty)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Product is not overdue",
+			requestBody: `{"productIds": [4]}`,
+			mockSetup: func(m *mocks.Shipment) {
+				m.On("CreateShipment", 1, []int{4}).Return(domain.ReturnShipmentWithProducts{}, usecases.ErrNotOverdue)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Invalid body",
+			requestBody:  `{"productIds": "all"}`,
+			mockSetup:    func(m *mocks.Shipment) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Shipment)
+			tt.mockSetup(mockService)
+			handler := http2.NewShipmentHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/pvz/1/return_shipments", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/pvz/{pvzId}/return_shipments", handler.CreateShipmentHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShipmentHandler_GetOverdue(t *testing.T) {
+	tests := []struct {
+		name         string
+		pvzId        string
+		mockSetup    func(*mocks.Shipment)
+		expectedCode int
+	}{
+		{
+			name:  "Success",
+			pvzId: "1",
+			mockSetup: func(m *mocks.Shipment) {
+				m.On("GetOverdue", 1).Return([]domain.Product{{Id: 3}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "Pvz not found",
+			pvzId: "2",
+			mockSetup: func(m *mocks.Shipment) {
+				m.On("GetOverdue", 2).Return([]domain.Product(nil), repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Invalid id",
+			pvzId:        "abc",
+			mockSetup:    func(m *mocks.Shipment) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Shipment)
+			tt.mockSetup(mockService)
+			handler := http2.NewShipmentHandler(mockService)
+
+			req := httptest.NewRequest("GET", "/pvz/"+tt.pvzId+"/overdue", nil)
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/pvz/{pvzId}/overdue", handler.GetOverdueHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShipmentHandler_GetShipment(t *testing.T) {
+	tests := []struct {
+		name         string
+		shipmentId   string
+		mockSetup    func(*mocks.Shipment)
+		expectedCode int
+	}{
+		{
+			name:       "Success",
+			shipmentId: "1",
+			mockSetup: func(m *mocks.Shipment) {
+				m.On("GetShipment", 1).Return(domain.ReturnShipmentWithProducts{Shipment: domain.ReturnShipment{Id: 1}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:       "Not found",
+			shipmentId: "2",
+			mockSetup: func(m *mocks.Shipment) {
+				m.On("GetShipment", 2).Return(domain.ReturnShipmentWithProducts{}, repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Shipment)
+			tt.mockSetup(mockService)
+			handler := http2.NewShipmentHandler(mockService)
+
+			req := httptest.NewRequest("GET", "/return_shipments/"+tt.shipmentId, nil)
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/return_shipments/{shipmentId}", handler.GetShipmentHandler)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
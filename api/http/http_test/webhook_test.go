@@ -0,0 +1,166 @@
+package http_test
+
+import (
+	http2 "avito_test/api/http"
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"avito_test/usecases/mocks"
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockSetup    func(*mocks.Webhook)
+		expectedCode int
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"url": " https://partner.example/hook ", "eventTypes": ["product.added"], "city": "Москва"}`,
+			mockSetup: func(m *mocks.Webhook) {
+				m.On("CreateWebhook", domain.Webhook{
+					URL:        "https://partner.example/hook",
+					EventTypes: []string{domain.EventProductAdded},
+					City:       "Москва",
+				}).Return(domain.Webhook{Id: 1, URL: "https://partner.example/hook", Secret: "s3cr3t"}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Missing url",
+			requestBody:  `{"eventTypes": ["product.added"]}`,
+			mockSetup:    func(m *mocks.Webhook) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Negative pvzId",
+			requestBody:  `{"url": "https://partner.example/hook", "pvzId": -1}`,
+			mockSetup:    func(m *mocks.Webhook) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Unknown event type",
+			requestBody: `{"url": "https://partner.example/hook", "eventTypes": ["pvz.closed"]}`,
+			mockSetup: func(m *mocks.Webhook) {
+				m.On("CreateWebhook", domain.Webhook{URL: "https://partner.example/hook", EventTypes: []string{"pvz.closed"}}).
+					Return(domain.Webhook{}, usecases.ErrInvalidWebhookFilter)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid url",
+			requestBody: `{"url": "ftp://partner.example"}`,
+			mockSetup: func(m *mocks.Webhook) {
+				m.On("CreateWebhook", domain.Webhook{URL: "ftp://partner.example"}).
+					Return(domain.Webhook{}, usecases.ErrInvalidWebhookURL)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Webhook)
+			tt.mockSetup(mockService)
+			handler := http2.NewWebhookHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/webhooks", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			handler.WithWebhookHandlers(r)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_Deliveries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		mockSetup    func(*mocks.Webhook)
+		expectedCode int
+	}{
+		{
+			name:   "Delivery log",
+			method: "GET",
+			path:   "/webhooks/1/deliveries",
+			mockSetup: func(m *mocks.Webhook) {
+				m.On("ListDeliveries", 1).Return([]domain.WebhookDelivery{{Id: 4, WebhookId: 1}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Delivery log of unknown webhook",
+			method: "GET",
+			path:   "/webhooks/2/deliveries",
+			mockSetup: func(m *mocks.Webhook) {
+				m.On("ListDeliveries", 2).Return([]domain.WebhookDelivery(nil), repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "Redeliver",
+			method: "POST",
+			path:   "/webhooks/1/deliveries/4/redeliver",
+			mockSetup: func(m *mocks.Webhook) {
+				m.On("Redeliver", 1, 4).Return(domain.WebhookDelivery{Id: 4, Status: domain.DeliveryStatusDelivered}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Redeliver unknown delivery",
+			method: "POST",
+			path:   "/webhooks/1/deliveries/9/redeliver",
+			mockSetup: func(m *mocks.Webhook) {
+				m.On("Redeliver", 1, 9).Return(domain.WebhookDelivery{}, repository.NotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "Delete",
+			method: "DELETE",
+			path:   "/webhooks/1",
+			mockSetup: func(m *mocks.Webhook) {
+				m.On("DeleteWebhook", 1).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Invalid id",
+			method:       "POST",
+			path:         "/webhooks/1/deliveries/abc/redeliver",
+			mockSetup:    func(m *mocks.Webhook) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.Webhook)
+			tt.mockSetup(mockService)
+			handler := http2.NewWebhookHandler(mockService)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			handler.WithWebhookHandlers(r)
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -2,15 +2,84 @@ package http
 
 import (
 	"avito_test/config"
+	"avito_test/domain"
+	"avito_test/pkg/auth"
+	"avito_test/pkg/clientip"
+	"avito_test/pkg/logger"
+	"avito_test/pkg/tracing"
 	"avito_test/repository/prometheus"
+	"avito_test/usecases"
+	"bytes"
 	"context"
-	"fmt"
-	"github.com/golang-jwt/jwt/v5"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 )
 
+// TracingMiddleware continues the trace of a caller that sent a W3C traceparent header, or
+// starts a new one, with a server span named after the matched route.
+func TracingMiddleware(routes chi.Routes) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			pattern := routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+			name := r.Method
+			if pattern != "" {
+				name += " " + pattern
+			}
+
+			ctx, span := tracing.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(pattern),
+				semconv.URLPath(r.URL.Path),
+			))
+			defer span.End()
+
+			rw := &responseRecorder{w, http.StatusOK}
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(rw.status))
+			if rw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rw.status))
+			}
+		})
+	}
+}
+
+// maxRequestIdLength bounds a client supplied X-Request-ID; longer ones are replaced.
+const maxRequestIdLength = 128
+
+// RequestIdMiddleware propagates the X-Request-ID of the caller or assigns a new one,
+// returns it in the response and puts a logger tagged with it into the request context.
+func RequestIdMiddleware(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestId := r.Header.Get(logger.RequestIdHeader)
+			if requestId == "" || len(requestId) > maxRequestIdLength {
+				requestId = logger.NewRequestId()
+			}
+			w.Header().Set(logger.RequestIdHeader, requestId)
+
+			requestLog := log.With("requestId", requestId)
+			start := time.Now()
+			rw := &responseRecorder{w, http.StatusOK}
+			next.ServeHTTP(rw, r.WithContext(logger.WithLogger(r.Context(), requestLog)))
+
+			requestLog.Debug("request handled", "method", r.Method, "path", r.URL.Path, "status", rw.status,
+				"duration", time.Since(start))
+		})
+	}
+}
+
 func AuthMiddleware(requiredRoles []string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -21,65 +90,199 @@ func AuthMiddleware(requiredRoles []string) func(next http.Handler) http.Handler
 			}
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return []byte(config.Secret), nil
-			})
-
-			if err != nil || !token.Valid {
+			claims, err := auth.ParseToken(tokenString)
+			if err != nil {
 				http.Error(w, "Forbidden", http.StatusForbidden)
 				return
 			}
+			auth.SetActor(r.Context(), claims)
 
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
+			if !auth.RoleAllowed(claims.Role, requiredRoles) {
 				http.Error(w, "Forbidden", http.StatusForbidden)
 				return
 			}
 
-			id, idOk := claims["id"].(string)
-			role, roleOk := claims["role"].(string)
-			if !idOk || !roleOk {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+			ctx := context.WithValue(r.Context(), "user_info", map[string]interface{}{
+				"id":   claims.Id,
+				"role": claims.Role,
+			})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func TimeoutMiddleware(routes chi.Routes, timeouts config.Timeouts) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pattern := routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+			timeout := timeouts.For(r.Method + " " + pattern)
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			if len(requiredRoles) > 0 && !roleAllowed(role, requiredRoles) {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Limits of what AuditMiddleware reads: the request body is summarized by the audit
+// service, the response only to find the id of a created object.
+const (
+	maxAuditRequestBody  = 64 << 10
+	maxAuditResponseBody = 4 << 10
+)
+
+type auditAction struct {
+	name string
+	// createdId names the id field of the response in the entry targets.
+	createdId string
+}
+
+var auditActions = map[string]auditAction{
+	"POST /pvz":                              {domain.AuditPvzOpen, domain.AuditTargetPvz},
+	"POST /receptions":                       {domain.AuditReceptionStart, domain.AuditTargetReception},
+	"POST /pvz/{pvzId}/close_last_reception": {domain.AuditReceptionClose, domain.AuditTargetReception},
+	"POST /products":                         {domain.AuditProductAdd, domain.AuditTargetProduct},
+	"POST /pvz/{pvzId}/delete_last_product":  {domain.AuditProductDelete, ""},
+	"POST /pvz/{pvzId}/delete_product":       {domain.AuditProductDelete, ""},
+	"POST /register":                         {domain.AuditUserRegister, domain.AuditTargetUser},
+}
+
+// notAudited are the POST routes that change nothing.
+var notAudited = map[string]bool{
+	"POST /login":      true,
+	"POST /dummyLogin": true,
+}
+
+// AuditMiddleware records every POST, PUT, PATCH and DELETE call to a known route,
+// including the ones refused by AuthMiddleware, which tells it the caller. The client IP
+// is taken from X-Forwarded-For only for calls coming from one of the proxies.
+func AuditMiddleware(routes chi.Routes, audit usecases.Audit, proxies clientip.TrustedProxies) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pattern := routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+			route := r.Method + " " + pattern
+			if !isMutating(r.Method) || pattern == "" || notAudited[route] {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "user_info", map[string]interface{}{
-				"id":   id,
-				"role": role,
-			})
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxAuditRequestBody))
+			if err != nil {
+				http.Error(w, "Bad request", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+			ctx, actor := auth.WithActor(r.Context())
+			rw := &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			action, ok := auditActions[route]
+			if !ok {
+				action = auditAction{name: route, createdId: "id"}
+			}
+			entry := domain.AuditEntry{
+				ActorId:   actor.Id,
+				Role:      actor.Role,
+				Action:    action.name,
+				TargetIds: make(map[string]string),
+				Payload:   body,
+				Status:    rw.status,
+				IP:        proxies.ClientIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For")),
+			}
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				for i, key := range rctx.URLParams.Keys {
+					if key != "*" {
+						entry.TargetIds[key] = rctx.URLParams.Values[i]
+					}
+				}
+			}
+			if id := createdId(rw); action.createdId != "" && id != "" {
+				entry.TargetIds[action.createdId] = id
+			}
+
+			// The call is over either way, so a client gone or a deadline passed must not lose the entry.
+			if err := audit.Record(context.WithoutCancel(ctx), entry); err != nil {
+				logger.FromContext(ctx).Error("failed to record audit entry", "route", route, "error", err)
+			}
 		})
 	}
 }
 
-func roleAllowed(role string, allowedRoles []string) bool {
-	for _, r := range allowedRoles {
-		if r == role {
-			return true
-		}
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
 	}
-	return false
 }
 
-func PrometheusMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		rw := &responseRecorder{w, http.StatusOK}
+// createdId reads the top-level id of a successful JSON response.
+func createdId(rw *auditRecorder) string {
+	if rw.status < 200 || rw.status >= 300 {
+		return ""
+	}
+	var resp struct {
+		Id json.Number `json:"id"`
+	}
+	if err := json.Unmarshal(rw.body.Bytes(), &resp); err != nil {
+		return ""
+	}
+	return resp.Id.String()
+}
+
+// auditRecorder keeps the status and the start of the response body.
+type auditRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *auditRecorder) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.status = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *auditRecorder) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	if room := maxAuditResponseBody - rw.body.Len(); room > 0 {
+		rw.body.Write(b[:min(len(b), room)])
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *auditRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// PrometheusMiddleware labels requests by route pattern rather than path, so ids in the
+// path do not add series.
+func PrometheusMiddleware(routes chi.Routes, metrics *prometheus.Metrics) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseRecorder{w, http.StatusOK}
 
-		next.ServeHTTP(rw, r)
+			next.ServeHTTP(rw, r)
 
-		duration := time.Since(start)
-		prometheus.RecordHTTPRequest(r.Method, r.URL.Path, rw.status, duration)
-	})
+			route := routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+			if route == "" {
+				route = prometheus.UnmatchedRoute
+			}
+			metrics.RecordHTTPRequest(r.Method, route, rw.status, time.Since(start))
+		})
+	}
 }
 
 type responseRecorder struct {
@@ -91,3 +294,8 @@ func (rw *responseRecorder) WriteHeader(code int) {
 	rw.status = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap lets http.ResponseController reach the Flusher of streaming responses.
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"avito_test/api/http/types"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type Order struct {
+	Service usecases.Order
+}
+
+func NewOrderHandler(service usecases.Order) *Order {
+	return &Order{Service: service}
+}
+
+func (o *Order) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateCreateOrderHandlerRequest(r)
+	switch {
+	case errors.Is(err, types.ErrOrderNumberRequired):
+		http.Error(w, "OrderNumber is required", http.StatusBadRequest)
+		return
+	case errors.Is(err, types.ErrCodeTooLong):
+		http.Error(w, "OrderNumber must be at most 64 characters", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	order, err := o.Service.CreateOrder(r.Context(), req.PvzId, req.OrderNumber)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Pvz not found", http.StatusNotFound)
+		case errors.Is(err, usecases.ErrOrderEmpty):
+			http.Error(w, "No products with this order number in stock", http.StatusBadRequest)
+		case errors.Is(err, repository.ErrOrderAlreadyExists):
+			http.Error(w, "Order already exists", http.StatusConflict)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(order); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (o *Order) GetOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateListOrdersHandlerRequest(r)
+	if errors.Is(err, types.ErrInvalidStatus) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Invalid request parameters", http.StatusBadRequest)
+		return
+	}
+
+	orders, err := o.Service.GetOrders(r.Context(), req.PvzId, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Pvz not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(orders); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (o *Order) GetOrderHandler(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(chi.URLParam(r, "orderId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	order, err := o.Service.GetOrder(r.Context(), orderId)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Order not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(order); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (o *Order) ChangeOrderStatusHandler(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(chi.URLParam(r, "orderId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	req, err := types.CreateChangeOrderStatusHandlerRequest(r)
+	if errors.Is(err, types.ErrInvalidStatus) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	order, err := o.Service.ChangeOrderStatus(r.Context(), orderId, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Order not found", http.StatusNotFound)
+		case errors.Is(err, usecases.ErrInvalidTransition):
+			http.Error(w, "Order cannot move to this status", http.StatusConflict)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(order); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (o *Order) WithOrderHandlers(r chi.Router) {
+	r.Post("/pvz/{pvzId}/orders", o.CreateOrderHandler)
+	r.Get("/pvz/{pvzId}/orders", o.GetOrdersHandler)
+	r.Get("/orders/{orderId}", o.GetOrderHandler)
+	r.Post("/orders/{orderId}/status", o.ChangeOrderStatusHandler)
+}
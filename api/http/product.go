@@ -3,7 +3,6 @@ package http
 import (
 	"avito_test/api/http/types"
 	"avito_test/repository"
-	"avito_test/repository/prometheus"
 	"avito_test/usecases"
 	"encoding/json"
 	"errors"
@@ -25,6 +24,12 @@ func (p *Product) AddProductHandler(w http.ResponseWriter, r *http.Request) {
 	if errors.Is(err, types.ErrTypePvzIdRequired) {
 		http.Error(w, "Type and pvzId are required", http.StatusBadRequest)
 		return
+	} else if errors.Is(err, types.ErrCodeTooLong) {
+		http.Error(w, "Barcode and orderNumber must be at most 64 characters", http.StatusBadRequest)
+		return
+	} else if errors.Is(err, types.ErrInvalidReason) {
+		http.Error(w, "Invalid return reason", http.StatusBadRequest)
+		return
 	} else if err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
@@ -35,23 +40,49 @@ func (p *Product) AddProductHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
-	product, err := p.Service.AddProduct(req.Type, pvzId)
+	var originalProductId int
+	if req.OriginalProductId != "" {
+		if originalProductId, err = strconv.Atoi(req.OriginalProductId); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	product, err := p.Service.AddProduct(r.Context(), usecases.NewProduct{
+		Type:              req.Type,
+		Barcode:           req.Barcode,
+		OrderNumber:       req.OrderNumber,
+		ReturnReason:      req.ReturnReason,
+		OriginalProductId: originalProductId,
+	}, pvzId)
 	if err != nil {
 		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.ErrBarcodeAlreadyExists):
+			http.Error(w, "Product with this barcode is already in stock", http.StatusConflict)
+		case errors.Is(err, usecases.ErrInvalidProductType):
+			http.Error(w, "Invalid product type", http.StatusBadRequest)
+		case errors.Is(err, usecases.ErrReturnDetailsRequired):
+			http.Error(w, "Return items need a reason and an order or product reference", http.StatusBadRequest)
+		case errors.Is(err, usecases.ErrUnexpectedReturnDetails):
+			http.Error(w, "Return details are only accepted in customer returns", http.StatusBadRequest)
+		case errors.Is(err, usecases.ErrInvalidReturnReference):
+			http.Error(w, "Original product is not an issued item of the order", http.StatusBadRequest)
+		case errors.Is(err, usecases.ErrCapacityExceeded):
+			http.Error(w, "Pvz capacity exceeded", http.StatusUnprocessableEntity)
 		case errors.Is(err, repository.NotFound):
 			http.Error(w, "Pvz not found", http.StatusBadRequest)
 		case errors.Is(err, usecases.ErrAlreadyClosed):
 			http.Error(w, "Reception already closed", http.StatusBadRequest)
 		default:
-			http.Error(w, "Internal Error", http.StatusInternalServerError)
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		}
 		return
 	}
-	prometheus.RecordProductAdded()
 
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(product); err != nil {
-		http.Error(w, "Internal Error", http.StatusInternalServerError)
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		return
 	}
 }
@@ -64,15 +95,19 @@ func (p *Product) DeleteProductHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
-	err = p.Service.DeleteProduct(pvzIdInt)
+	err = p.Service.DeleteProduct(r.Context(), pvzIdInt)
 	if err != nil {
 		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
 		case errors.Is(err, repository.NotFound):
 			http.Error(w, "Pvz not found", http.StatusBadRequest)
 		case errors.Is(err, usecases.ErrAlreadyClosed):
 			http.Error(w, "Reception already closed", http.StatusBadRequest)
+		case errors.Is(err, repository.ErrProductNotFound):
+			http.Error(w, "No products in reception", http.StatusBadRequest)
 		default:
-			http.Error(w, "Internal Error", http.StatusInternalServerError)
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		}
 		return
 	}
@@ -80,7 +115,73 @@ func (p *Product) DeleteProductHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func (p *Product) DeleteProductByIdHandler(w http.ResponseWriter, r *http.Request) {
+	pvzId, err := strconv.Atoi(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	req, err := types.CreateDeleteProductHandlerRequest(r)
+	switch {
+	case errors.Is(err, types.ErrProductIdReasonRequired):
+		http.Error(w, "ProductId and reason are required", http.StatusBadRequest)
+		return
+	case errors.Is(err, types.ErrInvalidReason):
+		http.Error(w, "Invalid reason", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	productId, err := strconv.Atoi(req.ProductId)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	err = p.Service.DeleteProductById(r.Context(), pvzId, productId, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Pvz not found", http.StatusBadRequest)
+		case errors.Is(err, usecases.ErrAlreadyClosed):
+			http.Error(w, "Reception already closed", http.StatusBadRequest)
+		case errors.Is(err, repository.ErrProductNotFound):
+			http.Error(w, "Product not found in reception", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (p *Product) GetProductByBarcodeHandler(w http.ResponseWriter, r *http.Request) {
+	location, err := p.Service.GetProductByBarcode(r.Context(), chi.URLParam(r, "barcode"))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Product not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(location); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
 func (p *Product) WithProductHandlers(r chi.Router) {
 	r.Post("/products", p.AddProductHandler)
 	r.Post("/pvz/{pvzId}/delete_last_product", p.DeleteProductHandler)
+	r.Post("/pvz/{pvzId}/delete_product", p.DeleteProductByIdHandler)
 }
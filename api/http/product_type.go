@@ -0,0 +1,105 @@
+package http
+
+import (
+	"avito_test/api/http/types"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type ProductType struct {
+	Service usecases.ProductType
+}
+
+func NewProductTypeHandler(service usecases.ProductType) *ProductType {
+	return &ProductType{Service: service}
+}
+
+func (p *ProductType) CreateProductTypeHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateCreateProductTypeHandlerRequest(r)
+	switch {
+	case errors.Is(err, types.ErrCodeNameRequired):
+		http.Error(w, "Code and name are required", http.StatusBadRequest)
+		return
+	case errors.Is(err, types.ErrInvalidProductTypeCode):
+		http.Error(w, "Code must consist of lowercase latin letters, digits and underscores", http.StatusBadRequest)
+		return
+	case errors.Is(err, types.ErrInvalidStorageDays):
+		http.Error(w, "StorageDays must be positive", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	productType, err := p.Service.CreateProductType(r.Context(), req.Code, req.Name, req.StorageDays)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.ErrProductTypeAlreadyExists):
+			http.Error(w, "Product type with this code or name already exists", http.StatusConflict)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(productType); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (p *ProductType) ListProductTypesHandler(w http.ResponseWriter, r *http.Request) {
+	productTypes, err := p.Service.ListProductTypes(r.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(productTypes); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (p *ProductType) SetStorageDaysHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateSetStorageDaysHandlerRequest(r)
+	switch {
+	case errors.Is(err, types.ErrInvalidStorageDays):
+		http.Error(w, "StorageDays must be positive", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	productType, err := p.Service.SetStorageDays(r.Context(), req.Code, req.StorageDays)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, usecases.ErrInvalidStorageDays):
+			http.Error(w, "StorageDays must be positive", http.StatusBadRequest)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Product type not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(productType); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -2,12 +2,13 @@ package http
 
 import (
 	"avito_test/api/http/types"
-	"avito_test/repository/prometheus"
+	"avito_test/repository"
 	"avito_test/usecases"
 	"encoding/json"
 	"errors"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"strconv"
 )
 
 type Pvz struct {
@@ -25,18 +26,63 @@ func (p *Pvz) OpenPvzHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	} else if err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
 	}
 
-	pvz, err := p.Service.OpenPvz(req.City)
-	if err != nil {
-		http.Error(w, "Internal Error", http.StatusInternalServerError)
+	pvz, err := p.Service.OpenPvz(r.Context(), req.City)
+	switch {
+	case errors.Is(err, usecases.ErrInvalidCity):
+		http.Error(w, "Invalid City", http.StatusBadRequest)
+		return
+	case errors.Is(err, usecases.ErrCityDisabled):
+		http.Error(w, "City is disabled", http.StatusBadRequest)
+		return
+	case errors.Is(err, usecases.ErrTimeout):
+		serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		return
+	case err != nil:
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		return
 	}
-	prometheus.RecordPVZCreated()
 
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(pvz); err != nil {
-		http.Error(w, "Internal Error", http.StatusInternalServerError)
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (p *Pvz) SetCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateSetCapacityHandlerRequest(r)
+	if errors.Is(err, types.ErrInvalidCapacity) {
+		http.Error(w, "Capacity must not be negative and type limits must be positive", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	pvz, err := p.Service.SetCapacity(r.Context(), req.PvzId, req.Capacity, req.TypeCapacity)
+	switch {
+	case errors.Is(err, usecases.ErrInvalidCapacity):
+		http.Error(w, "Capacity must not be negative and type limits must be positive", http.StatusBadRequest)
+		return
+	case errors.Is(err, usecases.ErrInvalidProductType):
+		http.Error(w, "Invalid product type", http.StatusBadRequest)
+		return
+	case errors.Is(err, repository.NotFound):
+		http.Error(w, "Pvz not found", http.StatusNotFound)
+		return
+	case errors.Is(err, usecases.ErrTimeout):
+		serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		return
+	case err != nil:
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(pvz); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		return
 	}
 }
@@ -48,15 +94,32 @@ func (p *Pvz) GetPvzListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pvzList, err := p.Service.GetPvzListWithFilter(req.StartDate, req.EndDate, req.Page, req.Limit)
-	if err != nil {
-		http.Error(w, "Internal error", http.StatusInternalServerError)
+	page, err := p.Service.GetPvzListWithFilter(r.Context(), usecases.PvzFilter{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Page:      req.Page,
+		Limit:     req.Limit,
+		Cursor:    req.Cursor,
+	})
+	switch {
+	case errors.Is(err, usecases.ErrInvalidCursor):
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	case errors.Is(err, usecases.ErrTimeout):
+		serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		return
+	case err != nil:
+		serverError(w, r, err, "Internal error", http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(pvzList); err != nil {
-		http.Error(w, "Internal Error", http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(page.Items); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 	}
 }
 
@@ -3,7 +3,6 @@ package http
 import (
 	"avito_test/api/http/types"
 	"avito_test/repository"
-	"avito_test/repository/prometheus"
 	"avito_test/usecases"
 	"encoding/json"
 	"errors"
@@ -22,7 +21,10 @@ func NewReceptionHandler(service usecases.Reception) *Reception {
 
 func (rec *Reception) StartReceptionHandler(w http.ResponseWriter, r *http.Request) {
 	req, err := types.CreateStartReceptionHandlerRequest(r)
-	if err != nil {
+	if errors.Is(err, types.ErrInvalidReceptionKind) {
+		http.Error(w, "Invalid reception kind", http.StatusBadRequest)
+		return
+	} else if err != nil {
 		http.Error(w, "pvzId is required", http.StatusBadRequest)
 		return
 	}
@@ -32,9 +34,13 @@ func (rec *Reception) StartReceptionHandler(w http.ResponseWriter, r *http.Reque
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
-	reception, err := rec.Service.StartReception(pvzId)
+	reception, err := rec.Service.StartReception(r.Context(), pvzId, req.Kind)
 	if err != nil {
 		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, usecases.ErrInvalidReceptionKind):
+			http.Error(w, "Invalid reception kind", http.StatusBadRequest)
 		case errors.Is(err, repository.NotFound):
 			http.Error(w, "Pvz not found", http.StatusBadRequest)
 		case errors.Is(err, types.ErrPvzIdRequired):
@@ -42,14 +48,14 @@ func (rec *Reception) StartReceptionHandler(w http.ResponseWriter, r *http.Reque
 		case errors.Is(err, usecases.ErrUnclosedReception):
 			http.Error(w, "Unclosed reception", http.StatusBadRequest)
 		default:
-			http.Error(w, "Internal Error", http.StatusInternalServerError)
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		}
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(reception); err != nil {
-		http.Error(w, "Internal Error", http.StatusInternalServerError)
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		return
 	}
 }
@@ -62,27 +68,112 @@ func (rec *Reception) CloseReceptionHandler(w http.ResponseWriter, r *http.Reque
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
-	reception, err := rec.Service.CloseReception(pvzIdInt)
+	reception, err := rec.Service.CloseReception(r.Context(), pvzIdInt)
 	if err != nil {
 		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
 		case errors.Is(err, repository.NotFound):
 			http.Error(w, "Pvz not found", http.StatusBadRequest)
 		case errors.Is(err, usecases.ErrAlreadyClosed):
 			http.Error(w, "Reception already closed", http.StatusBadRequest)
 		default:
-			http.Error(w, "Internal Error", http.StatusInternalServerError)
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		}
 		return
 	}
-	prometheus.RecordReceptionCreated()
 
 	if err := json.NewEncoder(w).Encode(reception); err != nil {
-		http.Error(w, "Internal Error", http.StatusInternalServerError)
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (rec *Reception) GetReceptionsHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateListReceptionsHandlerRequest(r)
+	if errors.Is(err, types.ErrInvalidStatus) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Invalid request parameters", http.StatusBadRequest)
+		return
+	}
+
+	receptions, err := rec.Service.GetReceptions(r.Context(), req.PvzId, usecases.ReceptionFilter{
+		Status:    req.Status,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Pvz not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(receptions); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (rec *Reception) GetReceptionHandler(w http.ResponseWriter, r *http.Request) {
+	receptionId, err := strconv.Atoi(chi.URLParam(r, "receptionId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	reception, err := rec.Service.GetReception(r.Context(), receptionId)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Reception not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(reception); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (rec *Reception) GetRemovalsHandler(w http.ResponseWriter, r *http.Request) {
+	receptionId, err := strconv.Atoi(chi.URLParam(r, "receptionId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	removals, err := rec.Service.GetRemovals(r.Context(), receptionId)
+	if errors.Is(err, usecases.ErrTimeout) {
+		serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		return
+	} else if err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(removals); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
 		return
 	}
 }
 
 func (rec *Reception) WithReceptionHandlers(r chi.Router) {
 	r.Post("/receptions", rec.StartReceptionHandler)
+	r.Get("/receptions/{receptionId}", rec.GetReceptionHandler)
+	r.Get("/receptions/{receptionId}/removals", rec.GetRemovalsHandler)
+	r.Get("/pvz/{pvzId}/receptions", rec.GetReceptionsHandler)
 	r.Post("/pvz/{pvzId}/close_last_reception", rec.CloseReceptionHandler)
 }
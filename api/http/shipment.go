@@ -0,0 +1,109 @@
+package http
+
+import (
+	"avito_test/api/http/types"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type Shipment struct {
+	Service usecases.Shipment
+}
+
+func NewShipmentHandler(service usecases.Shipment) *Shipment {
+	return &Shipment{Service: service}
+}
+
+func (s *Shipment) GetOverdueHandler(w http.ResponseWriter, r *http.Request) {
+	pvzId, err := strconv.Atoi(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	products, err := s.Service.GetOverdue(r.Context(), pvzId)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Pvz not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(products); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *Shipment) CreateShipmentHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateCreateShipmentHandlerRequest(r)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	shipment, err := s.Service.CreateShipment(r.Context(), req.PvzId, req.ProductIds)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Pvz not found", http.StatusNotFound)
+		case errors.Is(err, usecases.ErrShipmentEmpty):
+			http.Error(w, "No overdue products to ship", http.StatusBadRequest)
+		case errors.Is(err, usecases.ErrNotOverdue):
+			http.Error(w, "Product is not overdue at this pvz", http.StatusConflict)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(shipment); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *Shipment) GetShipmentHandler(w http.ResponseWriter, r *http.Request) {
+	shipmentId, err := strconv.Atoi(chi.URLParam(r, "shipmentId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	shipment, err := s.Service.GetShipment(r.Context(), shipmentId)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrTimeout):
+			serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+		case errors.Is(err, repository.NotFound):
+			http.Error(w, "Shipment not found", http.StatusNotFound)
+		default:
+			serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(shipment); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *Shipment) WithShipmentHandlers(r chi.Router) {
+	r.Get("/pvz/{pvzId}/overdue", s.GetOverdueHandler)
+	r.Post("/pvz/{pvzId}/return_shipments", s.CreateShipmentHandler)
+	r.Get("/return_shipments/{shipmentId}", s.GetShipmentHandler)
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxAuditListLimit = 100
+
+type ListAuditHandlerRequest struct {
+	ActorId string
+	Role    string
+	Action  string
+	PvzId   int
+	From    *time.Time
+	To      *time.Time
+	Page    int
+	Limit   int
+}
+
+func CreateListAuditHandlerRequest(r *http.Request) (*ListAuditHandlerRequest, error) {
+	query := r.URL.Query()
+	req := ListAuditHandlerRequest{
+		ActorId: query.Get("actorId"),
+		Role:    query.Get("role"),
+		Action:  query.Get("action"),
+		Page:    1,
+		Limit:   10,
+	}
+
+	var err error
+	if pvzIdStr := query.Get("pvzId"); pvzIdStr != "" {
+		if req.PvzId, err = strconv.Atoi(pvzIdStr); err != nil || req.PvzId <= 0 {
+			return nil, ErrInvalidPvzId
+		}
+	}
+	if req.From, err = parseTimeParam(query.Get("from"), "from"); err != nil {
+		return nil, err
+	}
+	if req.To, err = parseTimeParam(query.Get("to"), "to"); err != nil {
+		return nil, err
+	}
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		req.Page = p
+	}
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		req.Limit = min(l, maxAuditListLimit)
+	}
+	return &req, nil
+}
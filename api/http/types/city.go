@@ -0,0 +1,43 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type CreateCityHandlerRequest struct {
+	Name string `json:"name"`
+}
+
+func CreateCreateCityHandlerRequest(r *http.Request) (*CreateCityHandlerRequest, error) {
+	var req CreateCityHandlerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, ErrInvalidCity
+	}
+	return &req, nil
+}
+
+type UpdateCityHandlerRequest struct {
+	Name    *string `json:"name"`
+	Enabled *bool   `json:"enabled"`
+}
+
+func CreateUpdateCityHandlerRequest(r *http.Request) (*UpdateCityHandlerRequest, error) {
+	var req UpdateCityHandlerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, ErrInvalidCity
+		}
+		req.Name = &name
+	}
+	return &req, nil
+}
@@ -1,24 +1,44 @@
 package types
 
 import (
+	"avito_test/pkg/logger"
 	"avito_test/repository"
+	"avito_test/usecases"
 	"encoding/json"
 	"errors"
 	"net/http"
 )
 
 var (
-	ErrInvalidJSON           = errors.New("invalid json")
-	ErrEmailPasswordRequired = errors.New("email and password are required")
-	ErrInvalidEmail          = errors.New("invalid email")
-	ErrInvalidRole           = errors.New("invalid role")
-	ErrInvalidCity           = errors.New("invalid city")
-	ErrPvzIdRequired         = errors.New("pvzId is required")
-	ErrTypePvzIdRequired     = errors.New("type and pvzId are required")
+	ErrInvalidJSON             = errors.New("invalid json")
+	ErrEmailPasswordRequired   = errors.New("email and password are required")
+	ErrInvalidEmail            = errors.New("invalid email")
+	ErrInvalidRole             = errors.New("invalid role")
+	ErrInvalidCity             = errors.New("invalid city")
+	ErrPvzIdRequired           = errors.New("pvzId is required")
+	ErrTypePvzIdRequired       = errors.New("type and pvzId are required")
+	ErrInvalidStatus           = errors.New("invalid status")
+	ErrProductIdReasonRequired = errors.New("productId and reason are required")
+	ErrInvalidReason           = errors.New("invalid reason")
+	ErrCodeNameRequired        = errors.New("code and name are required")
+	ErrInvalidProductTypeCode  = errors.New("invalid product type code")
+	ErrCodeTooLong             = errors.New("barcode and orderNumber must be at most 64 characters")
+	ErrOrderNumberRequired     = errors.New("orderNumber is required")
+	ErrInvalidReceptionKind    = errors.New("invalid reception kind")
+	ErrInvalidStorageDays      = errors.New("storageDays must be positive")
+	ErrInvalidCapacity         = errors.New("capacity must not be negative and typeCapacity values must be positive")
+	ErrURLRequired             = errors.New("url is required")
+	ErrInvalidPvzId            = errors.New("invalid pvzId")
 )
 
-func AuthError(w http.ResponseWriter, err error, resp any) {
-	if errors.Is(err, repository.NotFound) {
+// AuthError answers a login attempt; 5xx failures are logged with the request logger.
+func AuthError(w http.ResponseWriter, r *http.Request, err error, resp any) {
+	log := logger.FromContext(r.Context())
+	if errors.Is(err, usecases.ErrTimeout) {
+		log.Error("Request timeout", "status", http.StatusGatewayTimeout, "method", r.Method, "path", r.URL.Path, "error", err)
+		http.Error(w, "Request timeout", http.StatusGatewayTimeout)
+		return
+	} else if errors.Is(err, repository.NotFound) {
 		http.Error(w, "invalid email", http.StatusUnauthorized)
 		return
 	} else if err != nil {
@@ -26,6 +46,7 @@ func AuthError(w http.ResponseWriter, err error, resp any) {
 		return
 	}
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error("Internal Error", "status", http.StatusInternalServerError, "method", r.Method, "path", r.URL.Path, "error", err)
 		http.Error(w, "Internal Error", http.StatusInternalServerError)
 		return
 	}
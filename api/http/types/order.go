@@ -0,0 +1,72 @@
+package types
+
+import (
+	"avito_test/domain"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type CreateOrderHandlerRequest struct {
+	PvzId       int    `json:"-"`
+	OrderNumber string `json:"orderNumber"`
+}
+
+func CreateCreateOrderHandlerRequest(r *http.Request) (*CreateOrderHandlerRequest, error) {
+	var req CreateOrderHandlerRequest
+	var err error
+
+	req.PvzId, err = strconv.Atoi(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		return nil, ErrPvzIdRequired
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	req.OrderNumber = strings.TrimSpace(req.OrderNumber)
+	if req.OrderNumber == "" {
+		return nil, ErrOrderNumberRequired
+	}
+	if len(req.OrderNumber) > maxProductCodeLength {
+		return nil, ErrCodeTooLong
+	}
+	return &req, nil
+}
+
+type ListOrdersHandlerRequest struct {
+	PvzId  int
+	Status string
+}
+
+func CreateListOrdersHandlerRequest(r *http.Request) (*ListOrdersHandlerRequest, error) {
+	var req ListOrdersHandlerRequest
+	var err error
+
+	req.PvzId, err = strconv.Atoi(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		return nil, ErrPvzIdRequired
+	}
+
+	req.Status = r.URL.Query().Get("status")
+	if req.Status != "" && !domain.IsOrderStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+	return &req, nil
+}
+
+type ChangeOrderStatusHandlerRequest struct {
+	Status string `json:"status"`
+}
+
+func CreateChangeOrderStatusHandlerRequest(r *http.Request) (*ChangeOrderStatusHandlerRequest, error) {
+	var req ChangeOrderStatusHandlerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	if !domain.IsOrderStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+	return &req, nil
+}
@@ -1,13 +1,22 @@
 package types
 
 import (
+	"avito_test/domain"
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
+// maxProductCodeLength matches the width of the barcode and order_number columns.
+const maxProductCodeLength = 64
+
 type AddProductHandlerRequest struct {
-	Type  string `json:"type"`
-	PvzId string `json:"pvzId"`
+	Type              string `json:"type"`
+	PvzId             string `json:"pvzId"`
+	Barcode           string `json:"barcode"`
+	OrderNumber       string `json:"orderNumber"`
+	ReturnReason      string `json:"returnReason"`
+	OriginalProductId string `json:"originalProductId"`
 }
 
 func CreateAddProductHandlerRequest(r *http.Request) (*AddProductHandlerRequest, error) {
@@ -18,5 +27,43 @@ func CreateAddProductHandlerRequest(r *http.Request) (*AddProductHandlerRequest,
 	if req.Type == "" || req.PvzId == "" {
 		return nil, ErrTypePvzIdRequired
 	}
+	req.Barcode = strings.TrimSpace(req.Barcode)
+	req.OrderNumber = strings.TrimSpace(req.OrderNumber)
+	if len(req.Barcode) > maxProductCodeLength || len(req.OrderNumber) > maxProductCodeLength {
+		return nil, ErrCodeTooLong
+	}
+	if req.ReturnReason != "" && !domain.IsReturnReason(req.ReturnReason) {
+		return nil, ErrInvalidReason
+	}
+	return &req, nil
+}
+
+type DeleteProductHandlerRequest struct {
+	ProductId string `json:"productId"`
+	Reason    string `json:"reason"`
+}
+
+func CreateDeleteProductHandlerRequest(r *http.Request) (*DeleteProductHandlerRequest, error) {
+	var req DeleteProductHandlerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	if req.ProductId == "" || req.Reason == "" {
+		return nil, ErrProductIdReasonRequired
+	}
+	if !IsSupportedRemovalReason(req.Reason) {
+		return nil, ErrInvalidReason
+	}
 	return &req, nil
 }
+
+// IsSupportedRemovalReason reports whether a client may pass the reason code;
+// last_scan is reserved for the LIFO delete_last_product endpoint.
+func IsSupportedRemovalReason(reason string) bool {
+	switch reason {
+	case domain.RemovalReasonMisScan, domain.RemovalReasonDuplicate, domain.RemovalReasonDamaged, domain.RemovalReasonWrongPvz:
+		return true
+	default:
+		return false
+	}
+}
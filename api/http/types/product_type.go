@@ -0,0 +1,53 @@
+package types
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var productTypeCode = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type CreateProductTypeHandlerRequest struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	StorageDays int    `json:"storageDays"`
+}
+
+func CreateCreateProductTypeHandlerRequest(r *http.Request) (*CreateProductTypeHandlerRequest, error) {
+	var req CreateProductTypeHandlerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Code == "" || req.Name == "" {
+		return nil, ErrCodeNameRequired
+	}
+	if !productTypeCode.MatchString(req.Code) {
+		return nil, ErrInvalidProductTypeCode
+	}
+	if req.StorageDays < 0 {
+		return nil, ErrInvalidStorageDays
+	}
+	return &req, nil
+}
+
+type SetStorageDaysHandlerRequest struct {
+	Code        string `json:"-"`
+	StorageDays int    `json:"storageDays"`
+}
+
+func CreateSetStorageDaysHandlerRequest(r *http.Request) (*SetStorageDaysHandlerRequest, error) {
+	var req SetStorageDaysHandlerRequest
+	req.Code = chi.URLParam(r, "code")
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	if req.StorageDays <= 0 {
+		return nil, ErrInvalidStorageDays
+	}
+	return &req, nil
+}
@@ -1,8 +1,10 @@
 package types
 
 import (
+	"avito_test/usecases"
 	"encoding/json"
 	"fmt"
+	"github.com/go-chi/chi/v5"
 	"net/http"
 	"strconv"
 	"time"
@@ -17,17 +19,46 @@ func CreateOpenPvzHandlerRequest(r *http.Request) (*OpenPvzHandlerRequest, error
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return nil, ErrInvalidJSON
 	}
-	if req.City != "Москва" && req.City != "Санкт-Петербург" && req.City != "Казань" {
+	if req.City == "" {
 		return nil, ErrInvalidCity
 	}
 	return &req, nil
 }
 
+type SetCapacityHandlerRequest struct {
+	PvzId        int            `json:"-"`
+	Capacity     int            `json:"capacity"`
+	TypeCapacity map[string]int `json:"typeCapacity"`
+}
+
+func CreateSetCapacityHandlerRequest(r *http.Request) (*SetCapacityHandlerRequest, error) {
+	var req SetCapacityHandlerRequest
+	var err error
+
+	req.PvzId, err = strconv.Atoi(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		return nil, ErrPvzIdRequired
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	if req.Capacity < 0 {
+		return nil, ErrInvalidCapacity
+	}
+	for _, limit := range req.TypeCapacity {
+		if limit <= 0 {
+			return nil, ErrInvalidCapacity
+		}
+	}
+	return &req, nil
+}
+
 type ListPvzHandlerRequest struct {
 	StartDate *time.Time
 	EndDate   *time.Time
 	Page      int
 	Limit     int
+	Cursor    string
 }
 
 func CreateListPvzHandlerRequest(r *http.Request) (*ListPvzHandlerRequest, error) {
@@ -36,32 +67,37 @@ func CreateListPvzHandlerRequest(r *http.Request) (*ListPvzHandlerRequest, error
 	endDateStr := r.URL.Query().Get("endDate")
 	pageStr := r.URL.Query().Get("page")
 	limitStr := r.URL.Query().Get("limit")
+	req.Cursor = r.URL.Query().Get("cursor")
 
-	if startDateStr != "" {
-		startTime, err := time.Parse(time.RFC3339, startDateStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid startDate format, expected RFC3339")
-		}
-		req.StartDate = &startTime
+	var err error
+	if req.StartDate, err = parseTimeParam(startDateStr, "startDate"); err != nil {
+		return nil, err
 	}
-	if endDateStr != "" {
-		endTime, err := time.Parse(time.RFC3339, endDateStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid endDate format, expected RFC3339")
-		}
-		req.EndDate = &endTime
+	if req.EndDate, err = parseTimeParam(endDateStr, "endDate"); err != nil {
+		return nil, err
 	}
 	req.Page = 1
-	req.Limit = 10
+	req.Limit = usecases.DefaultPvzListLimit
 	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
 			req.Page = p
 		}
 	}
 	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
 			req.Limit = l
 		}
 	}
 	return &req, nil
 }
+
+func parseTimeParam(value, name string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s format, expected RFC3339", name)
+	}
+	return &t, nil
+}
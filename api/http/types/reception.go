@@ -1,12 +1,17 @@
 package types
 
 import (
+	"avito_test/domain"
 	"encoding/json"
+	"github.com/go-chi/chi/v5"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type StartReceptionHandlerRequest struct {
 	PvzId string `json:"pvzId"`
+	Kind  string `json:"kind"`
 }
 
 func CreateStartReceptionHandlerRequest(r *http.Request) (*StartReceptionHandlerRequest, error) {
@@ -17,5 +22,40 @@ func CreateStartReceptionHandlerRequest(r *http.Request) (*StartReceptionHandler
 	if req.PvzId == "" {
 		return nil, ErrPvzIdRequired
 	}
+	if req.Kind == "" {
+		req.Kind = domain.ReceptionKindDelivery
+	} else if !domain.IsReceptionKind(req.Kind) {
+		return nil, ErrInvalidReceptionKind
+	}
+	return &req, nil
+}
+
+type ListReceptionsHandlerRequest struct {
+	PvzId     int
+	Status    string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+func CreateListReceptionsHandlerRequest(r *http.Request) (*ListReceptionsHandlerRequest, error) {
+	var req ListReceptionsHandlerRequest
+	var err error
+
+	req.PvzId, err = strconv.Atoi(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		return nil, ErrPvzIdRequired
+	}
+
+	req.Status = r.URL.Query().Get("status")
+	if req.Status != "" && req.Status != "in_progress" && req.Status != "closed" {
+		return nil, ErrInvalidStatus
+	}
+
+	if req.StartDate, err = parseTimeParam(r.URL.Query().Get("startDate"), "startDate"); err != nil {
+		return nil, err
+	}
+	if req.EndDate, err = parseTimeParam(r.URL.Query().Get("endDate"), "endDate"); err != nil {
+		return nil, err
+	}
 	return &req, nil
 }
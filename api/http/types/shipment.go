@@ -0,0 +1,30 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type CreateShipmentHandlerRequest struct {
+	PvzId      int   `json:"-"`
+	ProductIds []int `json:"productIds"`
+}
+
+// CreateCreateShipmentHandlerRequest accepts an empty body, which ships every overdue product.
+func CreateCreateShipmentHandlerRequest(r *http.Request) (*CreateShipmentHandlerRequest, error) {
+	var req CreateShipmentHandlerRequest
+	var err error
+
+	req.PvzId, err = strconv.Atoi(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		return nil, ErrPvzIdRequired
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return nil, ErrInvalidJSON
+	}
+	return &req, nil
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"avito_test/domain"
 	"avito_test/repository"
 	"bytes"
 	"errors"
@@ -157,8 +158,8 @@ func TestCreateOpenPvzHandlerRequest(t *testing.T) {
 			},
 		},
 		{
-			name:    "Invalid city",
-			body:    `{"city": "Новосибирск"}`,
+			name:    "Empty city",
+			body:    `{"city": ""}`,
 			wantErr: true,
 		},
 	}
@@ -193,8 +194,23 @@ func TestCreateStartReceptionHandlerRequest(t *testing.T) {
 			wantErr: false,
 			expected: StartReceptionHandlerRequest{
 				PvzId: "123",
+				Kind:  domain.ReceptionKindDelivery,
 			},
 		},
+		{
+			name:    "Customer return",
+			body:    `{"pvzId": "123", "kind": "customer_return"}`,
+			wantErr: false,
+			expected: StartReceptionHandlerRequest{
+				PvzId: "123",
+				Kind:  domain.ReceptionKindCustomerReturn,
+			},
+		},
+		{
+			name:    "Unknown kind",
+			body:    `{"pvzId": "123", "kind": "transfer"}`,
+			wantErr: true,
+		},
 		{
 			name:    "Missing pvzId",
 			body:    `{}`,
@@ -259,6 +275,46 @@ func TestCreateAddProductHandlerRequest(t *testing.T) {
 	}
 }
 
+func TestCreateCreateProductTypeHandlerRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantErr  error
+		expected CreateProductTypeHandlerRequest
+	}{
+		{
+			name:     "Valid request",
+			body:     `{"code": "home_goods", "name": " товары для дома "}`,
+			expected: CreateProductTypeHandlerRequest{Code: "home_goods", Name: "товары для дома"},
+		},
+		{
+			name:    "Missing code",
+			body:    `{"name": "книги"}`,
+			wantErr: ErrCodeNameRequired,
+		},
+		{
+			name:    "Uppercase code",
+			body:    `{"code": "Books", "name": "книги"}`,
+			wantErr: ErrInvalidProductTypeCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/product_types", bytes.NewBufferString(tt.body))
+
+			got, err := CreateCreateProductTypeHandlerRequest(req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, *got)
+		})
+	}
+}
+
 func TestAuthError(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -280,7 +336,7 @@ func TestAuthError(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rec := httptest.NewRecorder()
-			AuthError(rec, tt.err, nil)
+			AuthError(rec, httptest.NewRequest("POST", "/login", nil), tt.err, nil)
 			assert.Equal(t, tt.expected, rec.Code)
 		})
 	}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type CreateWebhookHandlerRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"eventTypes"`
+	PvzId      int      `json:"pvzId"`
+	City       string   `json:"city"`
+}
+
+func CreateCreateWebhookHandlerRequest(r *http.Request) (*CreateWebhookHandlerRequest, error) {
+	var req CreateWebhookHandlerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if req.URL == "" {
+		return nil, ErrURLRequired
+	}
+	if req.PvzId < 0 {
+		return nil, ErrInvalidPvzId
+	}
+	req.City = strings.TrimSpace(req.City)
+	return &req, nil
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"avito_test/api/http/types"
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type Webhook struct {
+	Service usecases.Webhook
+}
+
+func NewWebhookHandler(service usecases.Webhook) *Webhook {
+	return &Webhook{Service: service}
+}
+
+func (h *Webhook) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := types.CreateCreateWebhookHandlerRequest(r)
+	if errors.Is(err, types.ErrURLRequired) {
+		http.Error(w, "Webhook url is required", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.Service.CreateWebhook(r.Context(), domain.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		PvzId:      req.PvzId,
+		City:       req.City,
+	})
+	if err != nil {
+		webhookError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(webhook); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Webhook) ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.Service.ListWebhooks(r.Context())
+	if err != nil {
+		webhookError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(webhooks); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Webhook) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.Service.GetWebhook(r.Context(), webhookId)
+	if err != nil {
+		webhookError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(webhook); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Webhook) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteWebhook(r.Context(), webhookId); err != nil {
+		webhookError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Webhook) ListDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := h.Service.ListDeliveries(r.Context(), webhookId)
+	if err != nil {
+		webhookError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// RedeliverHandler answers 200 even if the partner rejects the delivery again;
+// the outcome is in the returned delivery.
+func (h *Webhook) RedeliverHandler(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	deliveryId, err := strconv.Atoi(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.Service.Redeliver(r.Context(), webhookId, deliveryId)
+	if err != nil {
+		webhookError(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(delivery); err != nil {
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func webhookError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrTimeout):
+		serverError(w, r, err, "Request timeout", http.StatusGatewayTimeout)
+	case errors.Is(err, repository.NotFound):
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvalidWebhookURL):
+		http.Error(w, "Webhook url must be an absolute http or https url", http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrInvalidWebhookFilter):
+		http.Error(w, "Unknown event type, pvz or city in filters", http.StatusBadRequest)
+	default:
+		serverError(w, r, err, "Internal Error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Webhook) WithWebhookHandlers(r chi.Router) {
+	r.Get("/webhooks", h.ListWebhooksHandler)
+	r.Post("/webhooks", h.CreateWebhookHandler)
+	r.Get("/webhooks/{webhookId}", h.GetWebhookHandler)
+	r.Delete("/webhooks/{webhookId}", h.DeleteWebhookHandler)
+	r.Get("/webhooks/{webhookId}/deliveries", h.ListDeliveriesHandler)
+	r.Post("/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver", h.RedeliverHandler)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"log"
 	"os"
+	"time"
 )
 
 const (
@@ -12,11 +13,99 @@ const (
 )
 
 type HTTPConfig struct {
+	Address  string   `yaml:"address"`
+	Timeouts Timeouts `yaml:"timeouts"`
+}
+
+// Timeouts holds request deadlines; Routes is keyed by "METHOD /pattern" as registered in chi.
+type Timeouts struct {
+	Default time.Duration            `yaml:"default"`
+	Routes  map[string]time.Duration `yaml:"routes"`
+}
+
+func (t Timeouts) For(route string) time.Duration {
+	if timeout, ok := t.Routes[route]; ok {
+		return timeout
+	}
+	return t.Default
+}
+
+type GRPCConfig struct {
 	Address string `yaml:"address"`
 }
 
+// PrometheusConfig sets the port of the metrics server and the buckets, in seconds, of
+// the response time histogram; empty Buckets keep the defaults.
 type PrometheusConfig struct {
-	Port uint16 `yaml:"port"`
+	Port    uint16    `yaml:"port" env-default:"9000"`
+	Buckets []float64 `yaml:"buckets"`
+}
+
+// OverdueConfig sets how often products past their storage period are marked overdue.
+type OverdueConfig struct {
+	Interval time.Duration `yaml:"interval" env-default:"1h"`
+}
+
+// OutboxConfig sets how outbox events are relayed. Publisher is one of log, file or http;
+// FilePath is used by file and URL and Timeout by http. Every consumer of the events is
+// relayed by one instance at a time, which holds its lease for Lease after each event,
+// so Lease should be well above Timeout. A failed event is retried like a webhook delivery:
+// the n-th retry waits Backoff*2^(n-1), at most MaxBackoff, up to MaxAttempts attempts.
+type OutboxConfig struct {
+	Interval    time.Duration `yaml:"interval" env-default:"1s"`
+	BatchSize   int           `yaml:"batchSize" env-default:"100"`
+	Lease       time.Duration `yaml:"lease" env-default:"30s"`
+	MaxAttempts int           `yaml:"maxAttempts" env-default:"20"`
+	Backoff     time.Duration `yaml:"backoff" env-default:"1s"`
+	MaxBackoff  time.Duration `yaml:"maxBackoff" env-default:"5m"`
+	Publisher   string        `yaml:"publisher" env-default:"log"`
+	FilePath    string        `yaml:"filePath"`
+	URL         string        `yaml:"url"`
+	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
+}
+
+// WebhookConfig sets how often due webhook deliveries are sent and how failed ones are retried:
+// the n-th retry waits Backoff*2^(n-1), at most MaxBackoff. An instance claims a batch of
+// deliveries for Lease, so Lease should be well above BatchSize*Timeout.
+type WebhookConfig struct {
+	Interval    time.Duration `yaml:"interval" env-default:"1s"`
+	BatchSize   int           `yaml:"batchSize" env-default:"50"`
+	Lease       time.Duration `yaml:"lease" env-default:"5m"`
+	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
+	MaxAttempts int           `yaml:"maxAttempts" env-default:"8"`
+	Backoff     time.Duration `yaml:"backoff" env-default:"10s"`
+	MaxBackoff  time.Duration `yaml:"maxBackoff" env-default:"1h"`
+}
+
+// ShutdownConfig bounds how long the servers drain in-flight calls on SIGTERM; stopping
+// the workers and closing the storage get as long again. ReadinessDelay is how long the
+// service keeps serving while /ready already fails, before the drain starts.
+type ShutdownConfig struct {
+	ReadinessDelay time.Duration `yaml:"readinessDelay" env-default:"5s"`
+	Timeout        time.Duration `yaml:"timeout" env-default:"15s"`
+}
+
+// LogConfig sets the lowest level of the JSON log: debug, info, warn or error.
+type LogConfig struct {
+	Level string `yaml:"level" env-default:"info"`
+}
+
+// TracingConfig sets where spans go. Exporter is one of none, stdout or otlp; Endpoint is
+// the host:port of the OTLP gRPC collector, reached without TLS when Insecure is set.
+// SampleRatio is the share of traces started here that are recorded.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"serviceName" env-default:"pvz"`
+	SampleRatio float64 `yaml:"sampleRatio" env-default:"1"`
+}
+
+// ProxiesConfig lists the addresses or CIDR networks of the proxies in front of the service.
+// X-Forwarded-For is believed only for calls coming from them, and the client is the
+// rightmost forwarded address that is not one of them.
+type ProxiesConfig struct {
+	Trusted []string `yaml:"trusted"`
 }
 
 type Postgres struct {
@@ -29,10 +118,25 @@ type Postgres struct {
 	MigrationPath string `yaml:"migrationPath"`
 }
 
+type Sqlite struct {
+	Path          string `yaml:"path"`
+	MigrationPath string `yaml:"migrationPath"`
+}
+
 type AppConfig struct {
+	Storage          string `yaml:"storage"`
 	HTTPConfig       `yaml:"http"`
+	GRPCConfig       `yaml:"grpc"`
 	Postgres         `yaml:"postgres"`
+	Sqlite           `yaml:"sqlite"`
 	PrometheusConfig `yaml:"prometheus"`
+	OverdueConfig    `yaml:"overdue"`
+	OutboxConfig     `yaml:"outbox"`
+	WebhookConfig    `yaml:"webhooks"`
+	LogConfig        `yaml:"log"`
+	TracingConfig    `yaml:"tracing"`
+	ShutdownConfig   `yaml:"shutdown"`
+	ProxiesConfig    `yaml:"proxies"`
 }
 
 type AppFlags struct {
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions of the audited calls. Other mutating calls are recorded under their
+// method and route, e.g. "PATCH /cities/{cityId}".
+const (
+	AuditPvzOpen        = "pvz.open"
+	AuditReceptionStart = "reception.start"
+	AuditReceptionClose = "reception.close"
+	AuditProductAdd     = "product.add"
+	AuditProductDelete  = "product.delete"
+	AuditUserRegister   = "user.register"
+)
+
+// Kinds of objects named in AuditEntry.TargetIds.
+const (
+	AuditTargetPvz       = "pvzId"
+	AuditTargetReception = "receptionId"
+	AuditTargetProduct   = "productId"
+	AuditTargetUser      = "userId"
+)
+
+// AuditEntry records who made a mutating call and how it ended. TargetIds maps the
+// kind of an affected object, like "pvzId", to its id; Payload is a summary of the
+// request without credentials. Status is the HTTP status of the response, gRPC
+// calls are recorded with the matching HTTP status.
+type AuditEntry struct {
+	Id        int               `json:"id"`
+	ActorId   string            `json:"actorId,omitempty"`
+	Role      string            `json:"role,omitempty"`
+	Action    string            `json:"action"`
+	TargetIds map[string]string `json:"targetIds,omitempty"`
+	Payload   json.RawMessage   `json:"payload,omitempty"`
+	Status    int               `json:"status"`
+	IP        string            `json:"ip,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
@@ -0,0 +1,7 @@
+package domain
+
+type City struct {
+	Id      int    `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
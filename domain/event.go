@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventPvzOpened          = "pvz.opened"
+	EventPvzCapacityChanged = "pvz.capacity_changed"
+	EventReceptionStarted   = "reception.started"
+	EventReceptionClosed    = "reception.closed"
+	EventProductAdded       = "product.added"
+	EventProductRemoved     = "product.removed"
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventShipmentCreated    = "shipment.created"
+)
+
+// Event is a state change written to the outbox in the same transaction as the change.
+// Products passing their storage period are the one change without an event: it follows
+// from the storage days of their type, and they leave the PVZ through shipment.created.
+type Event struct {
+	Id        int             `json:"id"`
+	Type      string          `json:"type"`
+	PvzId     int             `json:"pvzId"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// OutboxDelivery is an event queued for one consumer of the outbox; its status is one
+// of the DeliveryStatus constants, and a failed delivery is not retried.
+type OutboxDelivery struct {
+	Consumer      string
+	Event         Event
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	DeliveredAt   *time.Time
+}
+
+// ProductEvent is the payload of product.added and product.removed.
+type ProductEvent struct {
+	ReceptionId int    `json:"receptionId"`
+	ProductId   int    `json:"productId"`
+	Type        string `json:"type"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// ShipmentEvent is the payload of shipment.created.
+type ShipmentEvent struct {
+	ShipmentId int   `json:"shipmentId"`
+	ProductIds []int `json:"productIds"`
+}
+
+// ReceptionProgress is a step of the intake at a PVZ with the running product counts
+// of its reception, keyed by product type code.
+type ReceptionProgress struct {
+	EventId     int            `json:"eventId,omitempty"`
+	Type        string         `json:"type"`
+	ReceptionId int            `json:"receptionId"`
+	Status      string         `json:"status"`
+	ProductId   int            `json:"productId,omitempty"`
+	ProductType string         `json:"productType,omitempty"`
+	Counts      map[string]int `json:"counts"`
+	Total       int            `json:"total"`
+}
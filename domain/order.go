@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// Order statuses. An order is accepted once its products are in stock, becomes
+// ready_for_pickup when the customer is notified and ends as issued, refused
+// by the customer at pickup or returned to the sender.
+const (
+	OrderStatusAccepted       = "accepted"
+	OrderStatusReadyForPickup = "ready_for_pickup"
+	OrderStatusIssued         = "issued"
+	OrderStatusRefused        = "refused"
+	OrderStatusReturned       = "returned"
+)
+
+var orderTransitions = map[string][]string{
+	OrderStatusAccepted:       {OrderStatusReadyForPickup, OrderStatusReturned},
+	OrderStatusReadyForPickup: {OrderStatusIssued, OrderStatusRefused, OrderStatusReturned},
+}
+
+// IsOrderStatus reports whether status is one of the order statuses.
+func IsOrderStatus(status string) bool {
+	switch status {
+	case OrderStatusAccepted, OrderStatusReadyForPickup, OrderStatusIssued, OrderStatusRefused, OrderStatusReturned:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsOrderOpen reports whether an order still holds its number and products; once it
+// is issued, refused or returned, both may go to a new order.
+func IsOrderOpen(status string) bool {
+	return len(orderTransitions[status]) > 0
+}
+
+// CanChangeOrderStatus reports whether an order may move from one status to another.
+func CanChangeOrderStatus(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type Order struct {
+	Id          int       `json:"id"`
+	PvzId       int       `json:"pvzId"`
+	OrderNumber string    `json:"orderNumber"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type OrderWithProducts struct {
+	Order    Order     `json:"order"`
+	Products []Product `json:"products"`
+}
@@ -2,8 +2,44 @@ package domain
 
 import "time"
 
+// Reason codes for a customer return.
+const (
+	ReturnReasonDefective      = "defective"
+	ReturnReasonWrongItem      = "wrong_item"
+	ReturnReasonNotAsDescribed = "not_as_described"
+	ReturnReasonChangedMind    = "changed_mind"
+)
+
+// IsReturnReason reports whether reason is one of the customer return reasons.
+func IsReturnReason(reason string) bool {
+	switch reason {
+	case ReturnReasonDefective, ReturnReasonWrongItem, ReturnReasonNotAsDescribed, ReturnReasonChangedMind:
+		return true
+	default:
+		return false
+	}
+}
+
+// Product is a scanned item. Items of a customer return carry the reason and
+// refer to the original order by OrderNumber, to the issued product by
+// OriginalProductId, or both.
 type Product struct {
-	Id       int       `json:"id"`
-	DateTime time.Time `json:"dateTime"`
-	Type     string    `json:"type"`
+	Id                int        `json:"id"`
+	DateTime          time.Time  `json:"dateTime"`
+	Type              string     `json:"type"`
+	TypeName          string     `json:"typeName"`
+	Barcode           string     `json:"barcode,omitempty"`
+	OrderNumber       string     `json:"orderNumber,omitempty"`
+	IssuedAt          *time.Time `json:"issuedAt,omitempty"`
+	OverdueAt         *time.Time `json:"overdueAt,omitempty"`
+	ShippedAt         *time.Time `json:"shippedAt,omitempty"`
+	ReturnReason      string     `json:"returnReason,omitempty"`
+	OriginalProductId int        `json:"originalProductId,omitempty"`
+}
+
+// ProductLocation tells which reception, and so which PVZ, holds the product.
+type ProductLocation struct {
+	Product     Product `json:"product"`
+	ReceptionId int     `json:"receptionId"`
+	PvzId       int     `json:"pvzId"`
 }
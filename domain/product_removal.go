@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Reason codes for removing a product from an open reception.
+const (
+	RemovalReasonLastScan  = "last_scan"
+	RemovalReasonMisScan   = "mis_scan"
+	RemovalReasonDuplicate = "duplicate"
+	RemovalReasonDamaged   = "damaged"
+	RemovalReasonWrongPvz  = "wrong_pvz"
+)
+
+type ProductRemoval struct {
+	Id          int       `json:"id"`
+	ReceptionId int       `json:"receptionId"`
+	ProductId   int       `json:"productId"`
+	ProductType string    `json:"productType"`
+	Reason      string    `json:"reason"`
+	RemovedAt   time.Time `json:"removedAt"`
+}
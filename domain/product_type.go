@@ -0,0 +1,13 @@
+package domain
+
+// DefaultStorageDays is the storage period of a product type created without one.
+const DefaultStorageDays = 7
+
+// ProductType is an entry of the product catalog: Code is the stable English
+// identifier stored with products, Name is the localized display name.
+// StorageDays is how long a parcel of the type may wait for pickup.
+type ProductType struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	StorageDays int    `json:"storageDays"`
+}
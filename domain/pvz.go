@@ -6,4 +6,25 @@ type Pvz struct {
 	Id               int       `json:"id"`
 	RegistrationDate time.Time `json:"registrationDate"`
 	City             string    `json:"city"`
+	// Capacity caps the products in stock at the PVZ; zero means unlimited.
+	Capacity int `json:"capacity,omitempty"`
+	// TypeCapacity optionally caps the products of single types, keyed by type code.
+	TypeCapacity map[string]int `json:"typeCapacity,omitempty"`
+}
+
+// Occupancy counts the products in stock at a PVZ, in total and per type code.
+type Occupancy struct {
+	Total  int            `json:"total"`
+	ByType map[string]int `json:"byType"`
+}
+
+// Fits reports whether one more product of productType stays within the capacity of pvz.
+func (o Occupancy) Fits(pvz Pvz, productType string) bool {
+	if pvz.Capacity > 0 && o.Total >= pvz.Capacity {
+		return false
+	}
+	if limit, ok := pvz.TypeCapacity[productType]; ok && o.ByType[productType] >= limit {
+		return false
+	}
+	return true
 }
@@ -2,9 +2,22 @@ package domain
 
 import "time"
 
+// Reception kinds: a delivery brings stock from the sender, a customer_return
+// takes back items a customer brought to the PVZ.
+const (
+	ReceptionKindDelivery       = "delivery"
+	ReceptionKindCustomerReturn = "customer_return"
+)
+
+// IsReceptionKind reports whether kind is one of the reception kinds.
+func IsReceptionKind(kind string) bool {
+	return kind == ReceptionKindDelivery || kind == ReceptionKindCustomerReturn
+}
+
 type Reception struct {
 	Id        int       `json:"id"`
 	StartDate time.Time `json:"startDate"`
 	PvzId     int       `json:"pvzId"`
 	Status    string    `json:"status"`
+	Kind      string    `json:"kind"`
 }
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// ReturnShipment is an outbound batch of overdue products sent back to the sender.
+type ReturnShipment struct {
+	Id        int       `json:"id"`
+	PvzId     int       `json:"pvzId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ReturnShipmentWithProducts struct {
+	Shipment ReturnShipment `json:"shipment"`
+	Products []Product      `json:"products"`
+}
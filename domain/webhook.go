@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookEventTypes are the events partners can subscribe to.
+var WebhookEventTypes = []string{EventReceptionStarted, EventReceptionClosed, EventProductAdded, EventProductRemoved}
+
+// Webhook is a partner subscription. Empty filters match everything; the secret
+// signs every delivery and is only shown when the webhook is created.
+type Webhook struct {
+	Id         int       `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"eventTypes,omitempty"`
+	PvzId      int       `json:"pvzId,omitempty"`
+	City       string    `json:"city,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Matches reports whether the event of a PVZ in city passes the webhook filters.
+func (w Webhook) Matches(event Event, city string) bool {
+	if len(w.EventTypes) > 0 && !slices.Contains(w.EventTypes, event.Type) {
+		return false
+	}
+	if w.PvzId != 0 && w.PvzId != event.PvzId {
+		return false
+	}
+	return w.City == "" || w.City == city
+}
+
+// WebhookDelivery is one event queued for one webhook; Payload is the exact request body.
+type WebhookDelivery struct {
+	Id             int             `json:"id"`
+	WebhookId      int             `json:"webhookId"`
+	EventId        int             `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastError      string          `json:"lastError,omitempty"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
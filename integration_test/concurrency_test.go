@@ -0,0 +1,129 @@
+//go:build integration
+
+package integration_test
+
+import (
+	"avito_test/config"
+	"avito_test/domain"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/pkg/testutils"
+	"avito_test/repository/postgreSQL"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestReceptionInvariantsUnderConcurrency(t *testing.T) {
+	storage, err := postgres_connect.NewPostgresStorage(config.Postgres{
+		Host:     os.Getenv("TEST_DB_HOST"),
+		Port:     5432,
+		User:     os.Getenv("TEST_DB_USER"),
+		Password: os.Getenv("TEST_DB_PASSWORD"),
+		DBName:   os.Getenv("TEST_DB_NAME"),
+		SSLMode:  "disable",
+	})
+	require.NoError(t, err)
+
+	txManager := postgreSQL.NewTxManager(storage)
+	pvzRepo := postgreSQL.NewPvzRepo(storage)
+	receptionRepo := postgreSQL.NewReceptionRepo(storage)
+	productRepo := postgreSQL.NewProductRepo(storage)
+
+	metrics := testutils.Metrics()
+	pvzService := service.NewPvzService(pvzRepo, postgreSQL.NewCityRepo(storage), postgreSQL.NewProductTypeRepo(storage), txManager, metrics)
+	receptionService := service.NewReceptionService(receptionRepo, pvzRepo, txManager, metrics)
+	productService := service.NewProductService(productRepo, postgreSQL.NewProductTypeRepo(storage), receptionRepo, pvzRepo, txManager, metrics)
+
+	ctx := context.Background()
+	const workers = 20
+
+	for round := 0; round < 5; round++ {
+		pvz, err := pvzService.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+
+		var started, unclosed int
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := receptionService.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					started++
+				case errors.Is(err, usecases.ErrUnclosedReception):
+					unclosed++
+				default:
+					t.Errorf("unexpected start error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, started)
+		assert.Equal(t, workers-1, unclosed)
+
+		var added []int
+		var closed int
+		for i := 0; i < workers; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				product, err := productService.AddProduct(ctx, usecases.NewProduct{Type: "обувь"}, pvz.Id)
+				if err == nil {
+					mu.Lock()
+					added = append(added, product.Id)
+					mu.Unlock()
+				} else if !errors.Is(err, usecases.ErrAlreadyClosed) {
+					t.Errorf("unexpected add error: %v", err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				_, err := receptionService.CloseReception(ctx, pvz.Id)
+				if err == nil {
+					mu.Lock()
+					closed++
+					mu.Unlock()
+				} else if !errors.Is(err, usecases.ErrAlreadyClosed) {
+					t.Errorf("unexpected close error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, closed)
+
+		var inProgress int
+		err = storage.Db.QueryRow(
+			`SELECT COUNT(*) FROM receptions WHERE pvz_id = $1 AND status = 'in_progress'`, pvz.Id,
+		).Scan(&inProgress)
+		require.NoError(t, err)
+		assert.Zero(t, inProgress)
+
+		var linked int
+		err = storage.Db.QueryRow(`
+			SELECT COUNT(*) FROM reception_products rp
+			JOIN receptions r ON r.id = rp.reception_id
+			WHERE r.pvz_id = $1`, pvz.Id,
+		).Scan(&linked)
+		require.NoError(t, err)
+		assert.Equal(t, len(added), linked)
+
+		var orphans int
+		err = storage.Db.QueryRow(`
+			SELECT COUNT(*) FROM products p
+			LEFT JOIN reception_products rp ON rp.product_id = p.id
+			WHERE rp.product_id IS NULL`,
+		).Scan(&orphans)
+		require.NoError(t, err)
+		assert.Zero(t, orphans)
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"avito_test/config"
 	"avito_test/domain"
 	"avito_test/pkg/postgres_connect"
+	"avito_test/pkg/testutils"
 	"avito_test/repository/postgreSQL"
 	"avito_test/usecases"
 	"avito_test/usecases/service"
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -54,15 +56,17 @@ func (s *IntegrationTestSuite) SetupSuite() {
 
 	s.cleanDatabase(storage)
 
+	txManager := postgreSQL.NewTxManager(storage)
 	userRepo := postgreSQL.NewUserRepo(storage)
 	pvzRepo := postgreSQL.NewPvzRepo(storage)
 	receptionRepo := postgreSQL.NewReceptionRepo(storage)
 	productRepo := postgreSQL.NewProductRepo(storage)
 
+	metrics := testutils.Metrics()
 	userService := service.NewUserService(userRepo)
-	pvzService := service.NewPvzService(pvzRepo)
-	receptionService := service.NewReceptionService(receptionRepo, pvzRepo)
-	productService := service.NewProductService(productRepo, receptionRepo, pvzRepo)
+	pvzService := service.NewPvzService(pvzRepo, postgreSQL.NewCityRepo(storage), postgreSQL.NewProductTypeRepo(storage), txManager, metrics)
+	receptionService := service.NewReceptionService(receptionRepo, pvzRepo, txManager, metrics)
+	productService := service.NewProductService(productRepo, postgreSQL.NewProductTypeRepo(storage), receptionRepo, pvzRepo, txManager, metrics)
 
 	userHandler := http2.NewUserHandler(userService)
 	pvzHandler := http2.NewPvzHandler(pvzService)
@@ -94,12 +98,12 @@ func (s *IntegrationTestSuite) cleanDatabase(storage *postgres_connect.PostgresS
 }
 
 func (s *IntegrationTestSuite) createTestUserAndGetToken(service usecases.User) string {
-	_, err := service.Register("moderator@test.com", "password123", "moderator")
+	_, err := service.Register(context.Background(), "moderator@test.com", "password123", "moderator")
 	if err != nil {
 		s.T().Fatalf("failed to create test user: %s", err)
 	}
 
-	token, err := service.Login("moderator@test.com", "password123")
+	token, err := service.Login(context.Background(), "moderator@test.com", "password123")
 	if err != nil {
 		s.T().Fatalf("failed to get test token: %s", err)
 	}
@@ -1,15 +1,32 @@
 package main
 
 import (
+	"avito_test/api/grpc"
 	"avito_test/api/http"
 	"avito_test/config"
+	"avito_test/domain"
 	"avito_test/pkg"
+	"avito_test/pkg/broker"
+	"avito_test/pkg/clientip"
+	"avito_test/pkg/logger"
 	"avito_test/pkg/postgres_connect"
+	"avito_test/pkg/publisher"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/pkg/tracing"
+	"avito_test/repository"
+	"avito_test/repository/memory"
 	"avito_test/repository/postgreSQL"
 	"avito_test/repository/prometheus"
+	"avito_test/repository/sqlite"
+	"avito_test/usecases"
 	"avito_test/usecases/service"
+	"context"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"log"
+	"log/slog"
+	nethttp "net/http"
+	"os"
 )
 
 func main() {
@@ -17,44 +34,268 @@ func main() {
 	var cfg config.AppConfig
 	config.MustLoad(appFlags.ConfigPath, &cfg)
 
-	prometheus.InitPrometheus()
+	Logger, err := logger.New(os.Stdout, cfg.LogConfig.Level)
+	if err != nil {
+		log.Fatalf("failed creating logger: %s", err.Error())
+	}
+	// Records of the stdlib log package and of code without a request context go here too.
+	slog.SetDefault(Logger)
+	ctx := logger.WithLogger(context.Background(), Logger)
+
+	Lifecycle := pkg.NewLifecycle(Logger, cfg.ShutdownConfig.ReadinessDelay, cfg.ShutdownConfig.Timeout)
 
-	storage, err := postgres_connect.NewPostgresStorage(cfg.Postgres)
+	shutdownTracing, err := tracing.New(ctx, cfg.TracingConfig, os.Stdout)
 	if err != nil {
-		log.Fatalf("failed creating Postgres: %s", err.Error())
+		fatal("failed creating tracer", err, "exporter", cfg.TracingConfig.Exporter)
 	}
+	Lifecycle.OnClose("tracing", shutdownTracing)
 
-	UserRepo := postgreSQL.NewUserRepo(storage)
-	UserService := service.NewUserService(UserRepo)
+	Registry := prometheus.NewRegistry()
+	Metrics := prometheus.NewMetrics(Registry, cfg.PrometheusConfig.Buckets)
+
+	Repos, err := newRepositories(cfg)
+	if err != nil {
+		fatal("failed creating storage", err, "storage", cfg.Storage)
+	}
+	if Repos.Close != nil {
+		Lifecycle.OnClose("storage", func(context.Context) error { return Repos.Close() })
+	}
+	Registry.MustRegister(prometheus.NewOpenReceptions(Repos.Reception.CountOpenReceptions))
+
+	UserService := service.NewUserService(Repos.User)
 	UserHandlers := http.NewUserHandler(UserService)
 
-	PvzRepo := postgreSQL.NewPvzRepo(storage)
-	PvzService := service.NewPvzService(PvzRepo)
+	PvzService := service.NewPvzService(Repos.Pvz, Repos.City, Repos.ProductType, Repos.TxManager, Metrics)
 	PvzHandlers := http.NewPvzHandler(PvzService)
 
-	ReceptionRepo := postgreSQL.NewReceptionRepo(storage)
-	ReceptionService := service.NewReceptionService(ReceptionRepo, PvzRepo)
+	ReceptionService := service.NewReceptionService(Repos.Reception, Repos.Pvz, Repos.TxManager, Metrics)
 	ReceptionHandlers := http.NewReceptionHandler(ReceptionService)
 
-	ProductRepo := postgreSQL.NewProductRepo(storage)
-	ProductService := service.NewProductService(ProductRepo, ReceptionRepo, PvzRepo)
+	ProductService := service.NewProductService(Repos.Product, Repos.ProductType, Repos.Reception, Repos.Pvz, Repos.TxManager, Metrics)
 	ProductHandlers := http.NewProductHandler(ProductService)
 
+	CityService := service.NewCityService(Repos.City)
+	CityHandlers := http.NewCityHandler(CityService)
+
+	OrderService := service.NewOrderService(Repos.Order, Repos.Pvz, Repos.TxManager)
+	OrderHandlers := http.NewOrderHandler(OrderService)
+
+	ProductTypeService := service.NewProductTypeService(Repos.ProductType)
+	ProductTypeHandlers := http.NewProductTypeHandler(ProductTypeService)
+
+	ShipmentService := service.NewShipmentService(Repos.Shipment, Repos.Order, Repos.Pvz, Repos.TxManager)
+	ShipmentHandlers := http.NewShipmentHandler(ShipmentService)
+	Lifecycle.Go("overdue marker", func(ctx context.Context) {
+		ShipmentService.RunOverdueMarker(ctx, cfg.OverdueConfig.Interval)
+	})
+
+	WebhookService := service.NewWebhookService(Repos.Webhook, Repos.Pvz, Repos.City,
+		publisher.NewWebhookSender(cfg.WebhookConfig.Timeout),
+		service.RetryPolicy{
+			MaxAttempts: cfg.WebhookConfig.MaxAttempts,
+			Backoff:     cfg.WebhookConfig.Backoff,
+			MaxBackoff:  cfg.WebhookConfig.MaxBackoff,
+		},
+		cfg.WebhookConfig.BatchSize, cfg.WebhookConfig.Lease)
+	WebhookHandlers := http.NewWebhookHandler(WebhookService)
+	Lifecycle.Go("webhook deliveries", func(ctx context.Context) {
+		WebhookService.RunDeliveries(ctx, cfg.WebhookConfig.Interval)
+	})
+
+	AuditService := service.NewAuditService(Repos.Audit)
+	AuditHandlers := http.NewAuditHandler(AuditService)
+
+	Publisher, err := newPublisher(cfg.OutboxConfig)
+	if err != nil {
+		fatal("failed creating publisher", err, "publisher", cfg.OutboxConfig.Publisher)
+	}
+
+	Broker := broker.NewBroker()
+	FeedService := service.NewReceptionFeedService(Broker, Repos.Reception, Repos.Pvz, Repos.Outbox)
+	FeedHandlers := http.NewFeedHandler(FeedService)
+
+	// With Postgres every instance hears about every commit through LISTEN/NOTIFY;
+	// the single-node storages feed the broker from the outbox relay instead.
+	consumers := []service.OutboxConsumer{
+		{Name: "publisher", Publisher: Publisher},
+		{Name: "webhooks", Publisher: WebhookService},
+	}
+	if Repos.Events != nil {
+		Lifecycle.Go("event listener", func(ctx context.Context) {
+			handle := func(event domain.Event) { _ = Broker.Publish(ctx, event) }
+			if err := Repos.Events.Listen(ctx, handle); err != nil {
+				Logger.Error("failed to listen for events", "error", err)
+			}
+		})
+	} else {
+		consumers = append(consumers, service.OutboxConsumer{Name: "broker", Publisher: Broker})
+	}
+
+	OutboxRelay, err := service.NewOutboxRelay(Repos.Outbox, consumers,
+		service.RetryPolicy{
+			MaxAttempts: cfg.OutboxConfig.MaxAttempts,
+			Backoff:     cfg.OutboxConfig.Backoff,
+			MaxBackoff:  cfg.OutboxConfig.MaxBackoff,
+		},
+		cfg.OutboxConfig.BatchSize, cfg.OutboxConfig.Lease)
+	if err != nil {
+		fatal("failed creating outbox relay", err)
+	}
+	Lifecycle.Go("outbox relay", func(ctx context.Context) {
+		OutboxRelay.Run(ctx, cfg.OutboxConfig.Interval)
+	})
+
+	TrustedProxies, err := clientip.ParseTrustedProxies(cfg.ProxiesConfig.Trusted)
+	if err != nil {
+		fatal("failed parsing trusted proxies", err)
+	}
+
 	r := chi.NewRouter()
-	r.Use(http.PrometheusMiddleware)
+	r.Use(http.TracingMiddleware(r))
+	r.Use(http.RequestIdMiddleware(Logger))
+	r.Use(http.PrometheusMiddleware(r, Metrics))
+	r.Use(http.TimeoutMiddleware(r, cfg.HTTPConfig.Timeouts))
+	r.Use(http.AuditMiddleware(r, AuditService, TrustedProxies))
 	UserHandlers.WithUserHandlers(r)
 
 	r.Route("/", func(r chi.Router) {
 		r.With(http.AuthMiddleware([]string{"moderator"})).Post("/pvz", PvzHandlers.OpenPvzHandler)
 		r.With(http.AuthMiddleware([]string{"employee", "moderator"})).Get("/pvz", PvzHandlers.GetPvzListHandler)
+		r.With(http.AuthMiddleware([]string{"employee", "moderator"})).Get("/pvz/{pvzId}/events", FeedHandlers.EventsHandler)
+		r.With(http.AuthMiddleware([]string{"moderator"})).Patch("/pvz/{pvzId}/capacity", PvzHandlers.SetCapacityHandler)
+		r.With(http.AuthMiddleware([]string{"moderator"})).Post("/product_types", ProductTypeHandlers.CreateProductTypeHandler)
+		r.With(http.AuthMiddleware([]string{"employee", "moderator"})).Get("/product_types", ProductTypeHandlers.ListProductTypesHandler)
+		r.With(http.AuthMiddleware([]string{"moderator"})).Patch("/product_types/{code}", ProductTypeHandlers.SetStorageDaysHandler)
+		r.With(http.AuthMiddleware([]string{"employee", "moderator"})).Get("/products/barcode/{barcode}", ProductHandlers.GetProductByBarcodeHandler)
+		r.With(http.AuthMiddleware([]string{"moderator"})).Group(func(r chi.Router) {
+			CityHandlers.WithCityHandlers(r)
+			WebhookHandlers.WithWebhookHandlers(r)
+			AuditHandlers.WithAuditHandlers(r)
+		})
 		r.With(http.AuthMiddleware([]string{"employee"})).Group(func(r chi.Router) {
 			ReceptionHandlers.WithReceptionHandlers(r)
 			ProductHandlers.WithProductHandlers(r)
+			OrderHandlers.WithOrderHandlers(r)
+			ShipmentHandlers.WithShipmentHandlers(r)
 		})
 	})
 
-	log.Printf("Starting server on %s", cfg.Address)
-	if err := pkg.CreateAndRunServer(r, cfg.Address); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	grpcServer := grpc.NewGRPCServer(grpc.NewServer(PvzService, ReceptionService, ProductService), AuditService, TrustedProxies, Logger)
+
+	// Metrics and readiness are served until the API servers have drained.
+	metrics := nethttp.NewServeMux()
+	metrics.Handle("/metrics", prometheus.Handler(Registry))
+	metrics.HandleFunc("/ready", Lifecycle.ReadyHandler)
+	Lifecycle.AddServer("metrics", pkg.NewHTTPServer(metrics, fmt.Sprintf(":%d", cfg.PrometheusConfig.Port)))
+	Lifecycle.AddServer("http", pkg.NewHTTPServer(r, cfg.HTTPConfig.Address))
+	Lifecycle.AddServer("grpc", pkg.NewGRPCServer(grpcServer, cfg.GRPCConfig.Address))
+
+	if err := Lifecycle.Run(ctx); err != nil {
+		fatal("stopped with errors", err)
+	}
+}
+
+// fatal logs the error with the default logger and exits.
+func fatal(msg string, err error, args ...any) {
+	slog.Error(msg, append(args, "error", err)...)
+	os.Exit(1)
+}
+
+type repositories struct {
+	TxManager   repository.TxManager
+	User        repository.User
+	Pvz         repository.Pvz
+	Reception   repository.Reception
+	Product     repository.Product
+	City        repository.City
+	ProductType repository.ProductType
+	Order       repository.Order
+	Shipment    repository.Shipment
+	Outbox      repository.Outbox
+	Webhook     repository.Webhook
+	Audit       repository.Audit
+	// Events is only set for storages shared between instances.
+	Events repository.EventStream
+	// Close releases the storage; it is nil for memory.
+	Close func() error
+}
+
+func newRepositories(cfg config.AppConfig) (repositories, error) {
+	switch cfg.Storage {
+	case "memory":
+		storage := memory.NewStorage()
+		return repositories{
+			TxManager:   memory.NewTxManager(storage),
+			User:        memory.NewUserRepo(storage),
+			Pvz:         memory.NewPvzRepo(storage),
+			Reception:   memory.NewReceptionRepo(storage),
+			Product:     memory.NewProductRepo(storage),
+			City:        memory.NewCityRepo(storage),
+			ProductType: memory.NewProductTypeRepo(storage),
+			Order:       memory.NewOrderRepo(storage),
+			Shipment:    memory.NewShipmentRepo(storage),
+			Outbox:      memory.NewOutboxRepo(storage),
+			Webhook:     memory.NewWebhookRepo(storage),
+			Audit:       memory.NewAuditRepo(storage),
+		}, nil
+	case "sqlite":
+		storage, err := sqlite_connect.NewSqliteStorage(cfg.Sqlite)
+		if err != nil {
+			return repositories{}, err
+		}
+		return repositories{
+			TxManager:   sqlite.NewTxManager(storage),
+			User:        sqlite.NewUserRepo(storage),
+			Pvz:         sqlite.NewPvzRepo(storage),
+			Reception:   sqlite.NewReceptionRepo(storage),
+			Product:     sqlite.NewProductRepo(storage),
+			City:        sqlite.NewCityRepo(storage),
+			ProductType: sqlite.NewProductTypeRepo(storage),
+			Order:       sqlite.NewOrderRepo(storage),
+			Shipment:    sqlite.NewShipmentRepo(storage),
+			Outbox:      sqlite.NewOutboxRepo(storage),
+			Webhook:     sqlite.NewWebhookRepo(storage),
+			Audit:       sqlite.NewAuditRepo(storage),
+			Close:       storage.Db.Close,
+		}, nil
+	case "", "postgres":
+		storage, err := postgres_connect.NewPostgresStorage(cfg.Postgres)
+		if err != nil {
+			return repositories{}, err
+		}
+		return repositories{
+			TxManager:   postgreSQL.NewTxManager(storage),
+			User:        postgreSQL.NewUserRepo(storage),
+			Pvz:         postgreSQL.NewPvzRepo(storage),
+			Reception:   postgreSQL.NewReceptionRepo(storage),
+			Product:     postgreSQL.NewProductRepo(storage),
+			City:        postgreSQL.NewCityRepo(storage),
+			ProductType: postgreSQL.NewProductTypeRepo(storage),
+			Order:       postgreSQL.NewOrderRepo(storage),
+			Shipment:    postgreSQL.NewShipmentRepo(storage),
+			Outbox:      postgreSQL.NewOutboxRepo(storage),
+			Webhook:     postgreSQL.NewWebhookRepo(storage),
+			Audit:       postgreSQL.NewAuditRepo(storage),
+			Events:      postgreSQL.NewEventListener(storage),
+			Close:       storage.Db.Close,
+		}, nil
+	default:
+		return repositories{}, fmt.Errorf("unknown storage %q", cfg.Storage)
+	}
+}
+
+func newPublisher(cfg config.OutboxConfig) (usecases.Publisher, error) {
+	switch cfg.Publisher {
+	case "", "log":
+		return publisher.NewLogPublisher(os.Stdout), nil
+	case "file":
+		return publisher.NewFilePublisher(cfg.FilePath)
+	case "http":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("outbox url is not set")
+		}
+		return publisher.NewHTTPPublisher(cfg.URL, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown publisher %q", cfg.Publisher)
 	}
 }
@@ -0,0 +1,20 @@
+package auth
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a context carrying an empty Claims that SetActor fills in once a
+// token is parsed. Middleware running before authentication uses it to learn who made
+// the call after the handler returns.
+func WithActor(ctx context.Context) (context.Context, *Claims) {
+	actor := &Claims{}
+	return context.WithValue(ctx, actorKey{}, actor), actor
+}
+
+// SetActor records the claims in the Claims placed by WithActor, if there is one.
+func SetActor(ctx context.Context, claims Claims) {
+	if actor, ok := ctx.Value(actorKey{}).(*Claims); ok {
+		*actor = claims
+	}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"avito_test/config"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Claims struct {
+	Id   string
+	Role string
+}
+
+func ParseToken(tokenString string) (Claims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.Secret), nil
+	})
+
+	if err != nil || !token.Valid {
+		return Claims{}, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return Claims{}, ErrInvalidToken
+	}
+
+	id, idOk := claims["id"].(string)
+	role, roleOk := claims["role"].(string)
+	if !idOk || !roleOk {
+		return Claims{}, ErrInvalidToken
+	}
+	return Claims{Id: id, Role: role}, nil
+}
+
+func RoleAllowed(role string, allowedRoles []string) bool {
+	if len(allowedRoles) == 0 {
+		return true
+	}
+	for _, r := range allowedRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
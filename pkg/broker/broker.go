@@ -0,0 +1,61 @@
+package broker
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/logger"
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before events are dropped for it.
+const subscriberBuffer = 64
+
+// Broker fans events out to the in-process subscribers of their PVZ.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan domain.Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[int]map[chan domain.Event]struct{})}
+}
+
+// Subscribe returns the events of the PVZ and a function that ends the subscription and closes the channel.
+func (b *Broker) Subscribe(pvzId int) (<-chan domain.Event, func()) {
+	ch := make(chan domain.Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[pvzId] == nil {
+		b.subscribers[pvzId] = make(map[chan domain.Event]struct{})
+	}
+	b.subscribers[pvzId][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers[pvzId], ch)
+			if len(b.subscribers[pvzId]) == 0 {
+				delete(b.subscribers, pvzId)
+			}
+			close(ch)
+		})
+	}
+}
+
+// Publish never blocks: a subscriber whose buffer is full misses the event.
+func (b *Broker) Publish(ctx context.Context, event domain.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[event.PvzId] {
+		select {
+		case ch <- event:
+		default:
+			logger.FromContext(ctx).Warn("dropped event for a slow subscriber", "eventId", event.Id, "pvzId", event.PvzId)
+		}
+	}
+	return nil
+}
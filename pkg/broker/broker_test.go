@@ -0,0 +1,36 @@
+package broker
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+	first, unsubscribeFirst := b.Subscribe(1)
+	second, unsubscribeSecond := b.Subscribe(1)
+	other, unsubscribeOther := b.Subscribe(2)
+	defer unsubscribeOther()
+
+	assert.NoError(t, b.Publish(context.Background(), domain.Event{Id: 1, PvzId: 1}))
+	assert.Equal(t, 1, (<-first).Id)
+	assert.Equal(t, 1, (<-second).Id)
+	assert.Empty(t, other)
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	_, open := <-first
+	assert.False(t, open)
+
+	assert.NoError(t, b.Publish(context.Background(), domain.Event{Id: 2, PvzId: 1}))
+	assert.Equal(t, 2, (<-second).Id)
+
+	// A subscriber that does not keep up misses events instead of blocking publishers.
+	for i := 0; i < subscriberBuffer+10; i++ {
+		assert.NoError(t, b.Publish(context.Background(), domain.Event{Id: 3 + i, PvzId: 1}))
+	}
+	assert.Len(t, second, subscriberBuffer)
+	unsubscribeSecond()
+}
@@ -0,0 +1,74 @@
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies lists the networks of the proxies in front of the service. Only a call
+// that comes from one of them has its X-Forwarded-For believed, as anyone else can set it.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies accepts addresses and CIDR networks.
+func ParseTrustedProxies(proxies []string) (TrustedProxies, error) {
+	trusted := make(TrustedProxies, 0, len(proxies))
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			trusted = append(trusted, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		trusted = append(trusted, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return trusted, nil
+}
+
+// ClientIP returns the address of the client behind remote, the host:port the call came
+// from, given the values of its X-Forwarded-For. Walking the hops from the right, it takes
+// the first one that is not a trusted proxy; hops left of it could have been made up by
+// the client. When remote is not a trusted proxy the forwarded hops are ignored.
+func (t TrustedProxies) ClientIP(remote string, forwarded []string) string {
+	client := remote
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		client = host
+	}
+	if !t.trusts(client) {
+		return client
+	}
+
+	hops := strings.Split(strings.Join(forwarded, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// A trusted proxy only appends addresses, so whatever is left is the client's to forge.
+			return client
+		}
+		client = hop
+		if !t.trusts(hop) {
+			return client
+		}
+	}
+	return client
+}
+
+func (t TrustedProxies) trusts(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
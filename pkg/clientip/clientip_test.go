@@ -0,0 +1,80 @@
+package clientip_test
+
+import (
+	"avito_test/pkg/clientip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	trusted, err := clientip.ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{
+			name:   "no proxy",
+			remote: "203.0.113.5:4242",
+			want:   "203.0.113.5",
+		},
+		{
+			name:      "spoofed header from an untrusted peer is ignored",
+			remote:    "203.0.113.5:4242",
+			forwarded: []string{"198.51.100.7"},
+			want:      "203.0.113.5",
+		},
+		{
+			name:      "rightmost hop that is not a trusted proxy",
+			remote:    "192.0.2.1:4242",
+			forwarded: []string{"198.51.100.7, 203.0.113.5, 10.0.0.2"},
+			want:      "203.0.113.5",
+		},
+		{
+			name:      "hops split across headers",
+			remote:    "192.0.2.1:4242",
+			forwarded: []string{"198.51.100.7", "203.0.113.5"},
+			want:      "203.0.113.5",
+		},
+		{
+			name:      "every hop is a trusted proxy",
+			remote:    "192.0.2.1:4242",
+			forwarded: []string{"10.0.0.3, 10.0.0.2"},
+			want:      "10.0.0.3",
+		},
+		{
+			name:      "garbage left of the trusted hops",
+			remote:    "192.0.2.1:4242",
+			forwarded: []string{"unknown, 10.0.0.2"},
+			want:      "10.0.0.2",
+		},
+		{
+			name:      "trusted peer without the header",
+			remote:    "[2001:db8::1]:4242",
+			forwarded: nil,
+			want:      "2001:db8::1",
+		},
+		{
+			name:   "remote without a port",
+			remote: "bufconn",
+			want:   "bufconn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, trusted.ClientIP(tt.remote, tt.forwarded))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := clientip.ParseTrustedProxies([]string{"10.0.0.0/8", "::1"})
+	assert.NoError(t, err)
+	_, err = clientip.ParseTrustedProxies([]string{"proxy.local"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"net"
+)
+
+// GRPCServer is a Server for a grpc.Server; calls still running when the drain deadline
+// passes are cancelled.
+type GRPCServer struct {
+	server   *grpc.Server
+	addr     string
+	listener net.Listener
+}
+
+func NewGRPCServer(server *grpc.Server, addr string) *GRPCServer {
+	return &GRPCServer{server: server, addr: addr}
+}
+
+func (s *GRPCServer) Listen() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	return nil
+}
+
+func (s *GRPCServer) Serve() error {
+	return s.server.Serve(s.listener)
+}
+
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	// The listener is closed here too in case Serve was never called.
+	defer closeListener(s.listener)
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
@@ -1,15 +1,70 @@
 package pkg
 
 import (
-	"github.com/go-chi/chi/v5"
+	"context"
+	"errors"
+	"net"
 	"net/http"
 )
 
-func CreateAndRunServer(r chi.Router, addr string) error {
-	httpServer := &http.Server{
+type drainingKey struct{}
+
+// Draining returns a channel closed once the HTTPServer serving the request of ctx starts
+// to drain. Streams that never end on their own, like the reception feed, return on it
+// so the drain does not wait for them; outside an HTTPServer it is nil and never ready.
+func Draining(ctx context.Context) <-chan struct{} {
+	draining, _ := ctx.Value(drainingKey{}).(chan struct{})
+	return draining
+}
+
+// HTTPServer is a Server for an http.Handler. Streams are told to close when the drain
+// starts; requests still running when the drain deadline passes are dropped.
+type HTTPServer struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+func NewHTTPServer(handler http.Handler, addr string) *HTTPServer {
+	draining := make(chan struct{})
+	server := &http.Server{
 		Addr:    addr,
-		Handler: r,
+		Handler: handler,
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), drainingKey{}, draining)
+		},
+	}
+	server.RegisterOnShutdown(func() { close(draining) })
+	return &HTTPServer{server: server}
+}
+
+func (s *HTTPServer) Listen() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
 	}
+	s.listener = listener
+	return nil
+}
+
+func (s *HTTPServer) Serve() error {
+	if err := s.server.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
 
-	return httpServer.ListenAndServe()
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	// The listener is closed here too in case Serve was never called.
+	defer closeListener(s.listener)
+	if err := s.server.Shutdown(ctx); err != nil {
+		_ = s.server.Close()
+		return err
+	}
+	return nil
+}
+
+func closeListener(listener net.Listener) {
+	if listener != nil {
+		_ = listener.Close()
+	}
 }
@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Server is a listener run by Lifecycle. Listen binds the address, so connections are
+// accepted from then on; Serve then blocks until Shutdown is called; Shutdown waits for
+// in-flight calls until ctx is done and then drops the rest. Shutdown may also be called
+// on a server that never served.
+type Server interface {
+	Listen() error
+	Serve() error
+	Shutdown(ctx context.Context) error
+}
+
+type namedServer struct {
+	name string
+	Server
+}
+
+type worker struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+type closer struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// Lifecycle starts the servers and background workers of the service and, on SIGINT or
+// SIGTERM, stops them in order: it reports not ready, waits for load balancers to notice,
+// drains the servers, stops the workers and then runs the closers, like closing the
+// database, in reverse order.
+type Lifecycle struct {
+	log            *slog.Logger
+	readinessDelay time.Duration
+	drainTimeout   time.Duration
+	ready          atomic.Bool
+
+	servers []namedServer
+	workers []worker
+	closers []closer
+}
+
+// NewLifecycle keeps serving for readinessDelay after reporting not ready, so the
+// failing probe is seen before the listeners close, and then drains for drainTimeout.
+func NewLifecycle(log *slog.Logger, readinessDelay, drainTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{log: log, readinessDelay: readinessDelay, drainTimeout: drainTimeout}
+}
+
+// AddServer registers a server; servers are drained in reverse order of registration.
+func (l *Lifecycle) AddServer(name string, server Server) {
+	l.servers = append(l.servers, namedServer{name, server})
+}
+
+// Go registers a background worker. It runs until its ctx is cancelled, which happens
+// once the servers are drained, so requests still in flight can rely on it.
+func (l *Lifecycle) Go(name string, run func(ctx context.Context)) {
+	l.workers = append(l.workers, worker{name, run})
+}
+
+// OnClose registers a func run after the workers have stopped.
+func (l *Lifecycle) OnClose(name string, close func(ctx context.Context) error) {
+	l.closers = append(l.closers, closer{name, close})
+}
+
+// Ready reports whether the service accepts traffic: it is false before Run has bound
+// the addresses of all servers and while shutting down.
+func (l *Lifecycle) Ready() bool {
+	return l.ready.Load()
+}
+
+// ReadyHandler answers readiness probes with 200, or 503 while the service is not ready.
+func (l *Lifecycle) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	if !l.Ready() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ready"))
+}
+
+// serve binds the servers, reports ready and serves until ctx is done or a server
+// fails. It reports not ready again before returning; if an address cannot be bound it
+// returns at once without ever reporting ready.
+func (l *Lifecycle) serve(ctx context.Context) error {
+	for _, s := range l.servers {
+		if err := s.Listen(); err != nil {
+			err = fmt.Errorf("%s server: %w", s.name, err)
+			l.log.Error("shutting down after a server failed to listen", "error", err)
+			return err
+		}
+	}
+
+	failed := make(chan error, len(l.servers))
+	for _, s := range l.servers {
+		go func() {
+			l.log.Info("starting server", "server", s.name)
+			if err := s.Serve(); err != nil {
+				failed <- fmt.Errorf("%s server: %w", s.name, err)
+			}
+		}()
+	}
+	l.ready.Store(true)
+
+	var err error
+	select {
+	case <-ctx.Done():
+		l.log.Info("shutting down")
+	case err = <-failed:
+		l.log.Error("shutting down after a server failure", "error", err)
+	}
+	l.ready.Store(false)
+	if l.readinessDelay > 0 {
+		l.log.Info("waiting for the readiness probe to fail", "delay", l.readinessDelay)
+		time.Sleep(l.readinessDelay)
+	}
+	return err
+}
+
+// Run blocks until ctx is done, a signal arrives or a server fails, and then shuts down.
+// It returns the error of a failed server joined with those of the steps that failed.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Workers outlive the signal: they are cancelled after the servers are drained.
+	workerCtx, stopWorkers := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	for _, w := range l.workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			w.run(workerCtx)
+			l.log.Debug("worker stopped", "worker", w.name)
+		}()
+	}
+
+	runErr := l.serve(signalCtx)
+
+	drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.drainTimeout)
+	defer cancel()
+	for i := len(l.servers) - 1; i >= 0; i-- {
+		if err := l.servers[i].Shutdown(drainCtx); err != nil {
+			l.log.Warn("server did not drain in time", "server", l.servers[i].name, "error", err)
+			runErr = errors.Join(runErr, err)
+		}
+	}
+
+	// Stopping the workers and closing get a deadline of their own, so a slow drain
+	// does not keep the database from being closed or spans from being flushed.
+	stopCtx, cancelStop := context.WithTimeout(context.WithoutCancel(ctx), l.drainTimeout)
+	defer cancelStop()
+
+	stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-stopCtx.Done():
+		l.log.Warn("workers did not stop in time")
+	}
+
+	for i := len(l.closers) - 1; i >= 0; i-- {
+		if err := l.closers[i].close(stopCtx); err != nil {
+			l.log.Error("failed to close", "name", l.closers[i].name, "error", err)
+			runErr = errors.Join(runErr, err)
+		}
+	}
+	l.log.Info("stopped")
+	return runErr
+}
@@ -0,0 +1,233 @@
+package pkg_test
+
+import (
+	"avito_test/pkg"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// steps records the order in which the lifecycle stops things.
+type steps struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (s *steps) add(step string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.list = append(s.list, step)
+}
+
+func (s *steps) get() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.list...)
+}
+
+type fakeServer struct {
+	name      string
+	steps     *steps
+	lifecycle *pkg.Lifecycle
+	serveErr  error
+	stop      chan struct{}
+}
+
+func newFakeServer(name string, steps *steps, lifecycle *pkg.Lifecycle) *fakeServer {
+	return &fakeServer{name: name, steps: steps, lifecycle: lifecycle, stop: make(chan struct{})}
+}
+
+func (s *fakeServer) Listen() error {
+	return nil
+}
+
+func (s *fakeServer) Serve() error {
+	if s.serveErr != nil {
+		return s.serveErr
+	}
+	<-s.stop
+	return nil
+}
+
+func (s *fakeServer) Shutdown(ctx context.Context) error {
+	if s.lifecycle.Ready() {
+		s.steps.add(s.name + " drained while ready")
+	}
+	s.steps.add("drain " + s.name)
+	close(s.stop)
+	return nil
+}
+
+func newLifecycle() (*pkg.Lifecycle, *steps) {
+	steps := &steps{}
+	lifecycle := pkg.NewLifecycle(slog.Default(), 0, time.Second)
+	lifecycle.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		steps.add("stop worker")
+	})
+	lifecycle.OnClose("tracing", func(context.Context) error {
+		steps.add("close tracing")
+		return nil
+	})
+	lifecycle.OnClose("storage", func(context.Context) error {
+		steps.add("close storage")
+		return nil
+	})
+	return lifecycle, steps
+}
+
+func TestLifecycle_Run(t *testing.T) {
+	lifecycle, steps := newLifecycle()
+	lifecycle.AddServer("metrics", newFakeServer("metrics", steps, lifecycle))
+	lifecycle.AddServer("http", newFakeServer("http", steps, lifecycle))
+	assert.False(t, lifecycle.Ready())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- lifecycle.Run(ctx) }()
+
+	require.Eventually(t, lifecycle.Ready, time.Second, time.Millisecond)
+	rec := httptest.NewRecorder()
+	lifecycle.ReadyHandler(rec, httptest.NewRequest("GET", "/ready", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.False(t, lifecycle.Ready())
+	rec = httptest.NewRecorder()
+	lifecycle.ReadyHandler(rec, httptest.NewRequest("GET", "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, []string{"drain http", "drain metrics", "stop worker", "close storage", "close tracing"}, steps.get())
+}
+
+func TestLifecycle_ReadinessDelay(t *testing.T) {
+	steps := &steps{}
+	lifecycle := pkg.NewLifecycle(slog.Default(), 100*time.Millisecond, time.Second)
+	lifecycle.AddServer("http", newFakeServer("http", steps, lifecycle))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- lifecycle.Run(ctx) }()
+	require.Eventually(t, lifecycle.Ready, time.Second, time.Millisecond)
+
+	cancel()
+	require.Eventually(t, func() bool { return !lifecycle.Ready() }, time.Second, time.Millisecond)
+	// The probe fails while the server still serves.
+	assert.Empty(t, steps.get())
+
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"drain http"}, steps.get())
+}
+
+func TestLifecycle_ServerFailure(t *testing.T) {
+	lifecycle, steps := newLifecycle()
+	failing := newFakeServer("http", steps, lifecycle)
+	failing.serveErr = errors.New("connection reset")
+	lifecycle.AddServer("http", failing)
+
+	err := lifecycle.Run(context.Background())
+
+	assert.ErrorContains(t, err, "http server: connection reset")
+	assert.Equal(t, []string{"drain http", "stop worker", "close storage", "close tracing"}, steps.get())
+}
+
+func TestLifecycle_ReadyOnlyOnceListening(t *testing.T) {
+	lifecycle := pkg.NewLifecycle(slog.Default(), 0, time.Second)
+	lifecycle.AddServer("http", pkg.NewHTTPServer(http.NotFoundHandler(), "127.0.0.1:18091"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- lifecycle.Run(ctx) }()
+	require.Eventually(t, lifecycle.Ready, time.Second, time.Millisecond)
+
+	// Ready means the address is bound, not that it will be soon.
+	conn, err := net.Dial("tcp", "127.0.0.1:18091")
+	require.NoError(t, err)
+	_ = conn.Close()
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestLifecycle_ListenFailure(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = taken.Close() }()
+
+	lifecycle, steps := newLifecycle()
+	lifecycle.AddServer("metrics", newFakeServer("metrics", steps, lifecycle))
+	lifecycle.AddServer("http", pkg.NewHTTPServer(http.NotFoundHandler(), taken.Addr().String()))
+
+	err = lifecycle.Run(context.Background())
+
+	assert.ErrorContains(t, err, "http server: listen tcp")
+	assert.False(t, lifecycle.Ready())
+	assert.Equal(t, []string{"drain metrics", "stop worker", "close storage", "close tracing"}, steps.get())
+}
+
+func TestHTTPServer_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := pkg.NewHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}), "127.0.0.1:18089")
+	require.NoError(t, server.Listen())
+	go func() { _ = server.Serve() }()
+
+	var resp *http.Response
+	var reqErr error
+	requested := make(chan struct{})
+	go func() {
+		defer close(requested)
+		resp, reqErr = http.Post("http://127.0.0.1:18089/products", "application/json", nil)
+	}()
+	<-started
+
+	shutdown := make(chan error)
+	go func() { shutdown <- server.Shutdown(context.Background()) }()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	require.NoError(t, <-shutdown)
+	<-requested
+	require.NoError(t, reqErr)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	_ = resp.Body.Close()
+}
+
+func TestHTTPServer_ClosesStreamsOnDrain(t *testing.T) {
+	streaming := make(chan struct{})
+	server := pkg.NewHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_ = http.NewResponseController(w).Flush()
+		close(streaming)
+		select {
+		case <-r.Context().Done():
+		case <-pkg.Draining(r.Context()):
+		}
+	}), "127.0.0.1:18090")
+	require.NoError(t, server.Listen())
+	go func() { _ = server.Serve() }()
+
+	resp, err := http.Get("http://127.0.0.1:18090/pvz/1/events")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	<-streaming
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	started := time.Now()
+	require.NoError(t, server.Shutdown(ctx))
+	assert.Less(t, time.Since(started), time.Second)
+}
@@ -0,0 +1,37 @@
+package logger_test
+
+import (
+	"avito_test/pkg/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.New(&buf, "warn")
+	require.NoError(t, err)
+
+	log.Info("skipped")
+	log.Warn("kept", "pvzId", 1)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "kept", record["msg"])
+	assert.EqualValues(t, 1, record["pvzId"])
+
+	_, err = logger.New(&buf, "verbose")
+	assert.Error(t, err)
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, slog.Default(), logger.FromContext(context.Background()))
+
+	log := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	assert.Same(t, log, logger.FromContext(logger.WithLogger(context.Background(), log)))
+}
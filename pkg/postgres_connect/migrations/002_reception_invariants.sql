@@ -0,0 +1,35 @@
+-- +migrate Up
+UPDATE receptions
+SET status = 'closed'
+WHERE status = 'in_progress'
+  AND id NOT IN (SELECT MAX(id) FROM receptions WHERE status = 'in_progress' GROUP BY pvz_id);
+
+CREATE UNIQUE INDEX receptions_one_in_progress_per_pvz ON receptions (pvz_id) WHERE status = 'in_progress';
+
+-- +migrate StatementBegin
+CREATE FUNCTION reception_products_check_open() RETURNS trigger AS
+$$
+DECLARE
+    rid INT := COALESCE(NEW.reception_id, OLD.reception_id);
+BEGIN
+    IF EXISTS (SELECT 1 FROM receptions WHERE id = rid AND status <> 'in_progress') THEN
+        RAISE EXCEPTION 'reception % is closed', rid USING ERRCODE = 'check_violation';
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER reception_products_open_only
+    BEFORE INSERT OR DELETE
+    ON reception_products
+    FOR EACH ROW
+EXECUTE FUNCTION reception_products_check_open();
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS reception_products_open_only ON reception_products;
+DROP FUNCTION IF EXISTS reception_products_check_open();
+DROP INDEX IF EXISTS receptions_one_in_progress_per_pvz;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE product_removals
+(
+    id           SERIAL PRIMARY KEY,
+    reception_id INT                     NOT NULL REFERENCES receptions (id) ON DELETE CASCADE,
+    product_id   INT                     NOT NULL,
+    product_type VARCHAR(50)             NOT NULL,
+    reason       VARCHAR(50)             NOT NULL,
+    removed_at   TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX product_removals_reception_id ON product_removals (reception_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS product_removals;
@@ -0,0 +1,27 @@
+-- +migrate Up
+CREATE TABLE cities
+(
+    id      SERIAL PRIMARY KEY,
+    name    VARCHAR(100) UNIQUE NOT NULL,
+    enabled BOOLEAN DEFAULT TRUE NOT NULL
+);
+
+INSERT INTO cities (name)
+VALUES ('Москва'),
+       ('Санкт-Петербург'),
+       ('Казань');
+
+INSERT INTO cities (name)
+SELECT DISTINCT city
+FROM pvz
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE pvz DROP CONSTRAINT IF EXISTS pvz_city_check;
+ALTER TABLE pvz
+    ADD CONSTRAINT pvz_city_fkey FOREIGN KEY (city) REFERENCES cities (name) ON UPDATE CASCADE;
+
+-- +migrate Down
+ALTER TABLE pvz DROP CONSTRAINT IF EXISTS pvz_city_fkey;
+ALTER TABLE pvz
+    ADD CONSTRAINT pvz_city_check CHECK (city IN ('Москва', 'Санкт-Петербург', 'Казань')) NOT VALID;
+DROP TABLE IF EXISTS cities;
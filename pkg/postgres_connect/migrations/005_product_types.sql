@@ -0,0 +1,43 @@
+-- +migrate Up
+CREATE TABLE product_types
+(
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL
+);
+
+INSERT INTO product_types (code, name)
+VALUES ('electronics', 'электроника'),
+       ('clothes', 'одежда'),
+       ('shoes', 'обувь');
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_check;
+
+UPDATE products p
+SET type = pt.code
+FROM product_types pt
+WHERE p.type = pt.name;
+
+UPDATE product_removals r
+SET product_type = pt.code
+FROM product_types pt
+WHERE r.product_type = pt.name;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_type_fkey FOREIGN KEY (type) REFERENCES product_types (code);
+
+-- +migrate Down
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_fkey;
+
+UPDATE products p
+SET type = pt.name
+FROM product_types pt
+WHERE p.type = pt.code;
+
+UPDATE product_removals r
+SET product_type = pt.name
+FROM product_types pt
+WHERE r.product_type = pt.code;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_type_check CHECK (type IN ('электроника', 'одежда', 'обувь')) NOT VALID;
+DROP TABLE IF EXISTS product_types;
@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE products
+    ADD COLUMN barcode      VARCHAR(64),
+    ADD COLUMN order_number VARCHAR(64);
+
+CREATE UNIQUE INDEX products_barcode ON products (barcode) WHERE barcode IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS products_barcode;
+ALTER TABLE products
+    DROP COLUMN IF EXISTS order_number,
+    DROP COLUMN IF EXISTS barcode;
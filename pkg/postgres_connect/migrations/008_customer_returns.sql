@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE receptions
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'delivery' CHECK (kind IN ('delivery', 'customer_return'));
+
+CREATE TABLE product_returns
+(
+    product_id          INT         NOT NULL PRIMARY KEY REFERENCES products (id) ON DELETE CASCADE,
+    original_product_id INT REFERENCES products (id) ON DELETE SET NULL,
+    reason              VARCHAR(32) NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS product_returns;
+ALTER TABLE receptions
+    DROP COLUMN kind;
@@ -0,0 +1,36 @@
+-- +migrate Up
+ALTER TABLE product_types
+    ADD COLUMN storage_days INT NOT NULL DEFAULT 7 CHECK (storage_days > 0);
+
+ALTER TABLE products
+    ADD COLUMN overdue_at TIMESTAMP,
+    ADD COLUMN shipped_at TIMESTAMP;
+
+-- Shipped products leave stock like issued ones.
+DROP INDEX IF EXISTS products_barcode;
+CREATE UNIQUE INDEX products_barcode ON products (barcode) WHERE barcode IS NOT NULL AND issued_at IS NULL AND shipped_at IS NULL;
+
+CREATE TABLE return_shipments
+(
+    id         SERIAL PRIMARY KEY,
+    pvz_id     INT                     NOT NULL REFERENCES pvz (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE TABLE return_shipment_items
+(
+    shipment_id INT NOT NULL REFERENCES return_shipments (id) ON DELETE CASCADE,
+    product_id  INT NOT NULL UNIQUE REFERENCES products (id),
+    PRIMARY KEY (shipment_id, product_id)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS return_shipment_items;
+DROP TABLE IF EXISTS return_shipments;
+DROP INDEX IF EXISTS products_barcode;
+CREATE UNIQUE INDEX products_barcode ON products (barcode) WHERE barcode IS NOT NULL AND issued_at IS NULL;
+ALTER TABLE products
+    DROP COLUMN shipped_at,
+    DROP COLUMN overdue_at;
+ALTER TABLE product_types
+    DROP COLUMN storage_days;
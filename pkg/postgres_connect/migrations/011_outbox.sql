@@ -0,0 +1,45 @@
+-- +migrate Up
+-- pvz_id has no foreign key so that events outlive the rows they describe.
+CREATE TABLE outbox
+(
+    id            SERIAL PRIMARY KEY,
+    type          VARCHAR(64)             NOT NULL,
+    pvz_id        INT                     NOT NULL,
+    payload       JSONB                   NOT NULL,
+    created_at    TIMESTAMP DEFAULT NOW() NOT NULL,
+    dispatched_at TIMESTAMP
+);
+
+CREATE INDEX outbox_pending ON outbox (id) WHERE dispatched_at IS NULL;
+-- The reception feed replays the events of a PVZ a client missed.
+CREATE INDEX outbox_pvz ON outbox (pvz_id, id);
+
+-- Every event is queued for each consumer, and each consumer works through its own queue,
+-- so one that keeps failing does not hold up the others.
+CREATE TABLE outbox_deliveries
+(
+    consumer        VARCHAR(64)                   NOT NULL,
+    event_id        INT                           NOT NULL REFERENCES outbox (id) ON DELETE CASCADE,
+    status          VARCHAR(16) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts        INT         DEFAULT 0         NOT NULL,
+    next_attempt_at TIMESTAMP   DEFAULT NOW()     NOT NULL,
+    last_error      TEXT        DEFAULT ''        NOT NULL,
+    delivered_at    TIMESTAMP,
+    PRIMARY KEY (consumer, event_id)
+);
+
+CREATE INDEX outbox_deliveries_queued ON outbox_deliveries (consumer, event_id) WHERE status = 'pending';
+
+-- The instance holding a lease is the only one doing that part of the relay, so every event
+-- is dispatched and delivered to a consumer by one instance at a time; it renews the lease as it goes.
+CREATE TABLE outbox_leases
+(
+    name         VARCHAR(64) PRIMARY KEY,
+    owner        VARCHAR(64) NOT NULL,
+    leased_until TIMESTAMP   NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS outbox_leases;
+DROP TABLE IF EXISTS outbox_deliveries;
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,35 @@
+-- +migrate Up
+-- Empty event_types, NULL pvz_id and empty city match every event.
+CREATE TABLE webhooks
+(
+    id          SERIAL PRIMARY KEY,
+    url         TEXT                    NOT NULL,
+    secret      TEXT                    NOT NULL,
+    event_types TEXT[]    DEFAULT '{}'  NOT NULL,
+    pvz_id      INT REFERENCES pvz (id) ON DELETE CASCADE,
+    city        VARCHAR(255) DEFAULT '' NOT NULL,
+    created_at  TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id              SERIAL PRIMARY KEY,
+    webhook_id      INT                           NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        INT                           NOT NULL,
+    event_type      VARCHAR(64)                   NOT NULL,
+    payload         TEXT                          NOT NULL,
+    status          VARCHAR(16) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts        INT         DEFAULT 0         NOT NULL,
+    next_attempt_at TIMESTAMP                     NOT NULL,
+    last_error      TEXT        DEFAULT ''        NOT NULL,
+    response_status INT         DEFAULT 0         NOT NULL,
+    created_at      TIMESTAMP   DEFAULT NOW()     NOT NULL,
+    delivered_at    TIMESTAMP,
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- +migrate Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
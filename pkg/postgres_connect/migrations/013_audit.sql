@@ -0,0 +1,23 @@
+-- +migrate Up
+-- pvz_id copies target_ids ->> 'pvzId' for filtering; it has no foreign key so that
+-- calls naming an unknown PVZ are recorded too.
+CREATE TABLE audit_log
+(
+    id         SERIAL PRIMARY KEY,
+    actor_id   VARCHAR(64) DEFAULT ''    NOT NULL,
+    role       VARCHAR(32) DEFAULT ''    NOT NULL,
+    action     VARCHAR(128)              NOT NULL,
+    target_ids JSONB       DEFAULT '{}'  NOT NULL,
+    pvz_id     INT,
+    payload    JSONB,
+    status     INT                       NOT NULL,
+    ip         VARCHAR(64) DEFAULT ''    NOT NULL,
+    created_at TIMESTAMP   DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX audit_log_created_at ON audit_log (created_at);
+CREATE INDEX audit_log_actor ON audit_log (actor_id, created_at);
+CREATE INDEX audit_log_pvz ON audit_log (pvz_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_log;
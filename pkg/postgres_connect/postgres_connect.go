@@ -6,11 +6,13 @@ import (
 	"fmt"
 	_ "github.com/lib/pq"
 	"github.com/rubenv/sql-migrate"
-	"log"
+	"log/slog"
 )
 
 type PostgresStorage struct {
 	Db *sql.DB
+	// ConnStr lets LISTEN open its own connection outside the pool.
+	ConnStr string
 }
 
 func NewPostgresStorage(cfg config.Postgres) (*PostgresStorage, error) {
@@ -26,7 +28,7 @@ func NewPostgresStorage(cfg config.Postgres) (*PostgresStorage, error) {
 		return nil, err
 	}
 
-	storage := &PostgresStorage{Db: db}
+	storage := &PostgresStorage{Db: db, ConnStr: connStr}
 
 	if err := storage.runMigrations(cfg.MigrationPath); err != nil {
 		return nil, fmt.Errorf("migrations failed: %v", err)
@@ -48,9 +50,9 @@ func (s *PostgresStorage) runMigrations(path string) error {
 		return err
 	}
 	if n == 0 {
-		log.Println("No new migrations to apply.")
+		slog.Info("no new migrations to apply")
 	} else {
-		log.Printf("Applied %d database migration(s)\n", n)
+		slog.Info("applied database migrations", "count", n)
 	}
 	return nil
 }
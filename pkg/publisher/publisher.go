@@ -0,0 +1,84 @@
+package publisher
+
+import (
+	"avito_test/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LogPublisher writes every event as a JSON line.
+type LogPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogPublisher(w io.Writer) *LogPublisher {
+	return &LogPublisher{w: w}
+}
+
+// NewFilePublisher appends events to the file at path, creating it if needed.
+func NewFilePublisher(path string) (*LogPublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogPublisher(file), nil
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event domain.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
+
+// HTTPPublisher POSTs every event as JSON to a single endpoint.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Publish treats any non-2xx response as a failed delivery.
+func (p *HTTPPublisher) Publish(ctx context.Context, event domain.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", strconv.Itoa(event.Id))
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("publish event %d: unexpected status %s", event.Id, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package publisher
+
+import (
+	"avito_test/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLogPublisher_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewLogPublisher(&buf)
+
+	event := domain.Event{Id: 1, Type: domain.EventPvzOpened, PvzId: 4, Payload: json.RawMessage(`{"id":4}`)}
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	require.NoError(t, publisher.Publish(context.Background(), event))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var got domain.Event
+	require.NoError(t, json.Unmarshal(lines[0], &got))
+	assert.Equal(t, event.Id, got.Id)
+	assert.JSONEq(t, `{"id":4}`, string(got.Payload))
+}
+
+func TestHTTPPublisher_Publish(t *testing.T) {
+	status := http.StatusAccepted
+	var got domain.Event
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPPublisher(server.URL, time.Second)
+	event := domain.Event{Id: 7, Type: domain.EventReceptionClosed, PvzId: 2, Payload: json.RawMessage(`{}`)}
+
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	assert.Equal(t, "7", header.Get("X-Event-Id"))
+	assert.Equal(t, domain.EventReceptionClosed, header.Get("X-Event-Type"))
+	assert.Equal(t, event.PvzId, got.PvzId)
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, publisher.Publish(context.Background(), event))
+}
+
+func TestWebhookSender_Send(t *testing.T) {
+	var signature, timestamp, deliveryId string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		timestamp = r.Header.Get(TimestampHeader)
+		deliveryId = r.Header.Get("X-Delivery-Id")
+		if Verify("s3cr3t", timestamp, signature, body, time.Now()) != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	sender := NewWebhookSender(time.Second)
+	delivery := domain.WebhookDelivery{Id: 4, EventId: 11, EventType: domain.EventProductAdded, Payload: json.RawMessage(`{"id":11}`)}
+
+	status, err := sender.Send(context.Background(), domain.Webhook{URL: server.URL, Secret: "s3cr3t"}, delivery)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "4", deliveryId)
+	assert.Contains(t, signature, "sha256=")
+	assert.NotEmpty(t, timestamp)
+
+	status, err = sender.Send(context.Background(), domain.Webhook{URL: server.URL, Secret: "wrong"}, delivery)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":11}`)
+	now := time.Unix(1_700_000_000, 0)
+	signature := Sign("s3cr3t", now.Unix(), body)
+
+	assert.NoError(t, Verify("s3cr3t", "1700000000", signature, body, now.Add(time.Minute)))
+	assert.ErrorIs(t, Verify("wrong", "1700000000", signature, body, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cr3t", "1700000000", signature, []byte(`{"id":12}`), now), ErrInvalidSignature)
+	// Moving the timestamp forward to get past the tolerance breaks the signature.
+	assert.ErrorIs(t, Verify("s3cr3t", "1700003600", signature, body, now.Add(time.Hour)), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cr3t", "soon", signature, body, now), ErrInvalidSignature)
+	// A captured request replayed later is stale.
+	assert.ErrorIs(t, Verify("s3cr3t", "1700000000", signature, body, now.Add(SignatureTolerance+time.Second)), ErrStaleSignature)
+	assert.ErrorIs(t, Verify("s3cr3t", "1700000000", signature, body, now.Add(-SignatureTolerance-time.Second)), ErrStaleSignature)
+}
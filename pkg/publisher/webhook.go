@@ -0,0 +1,95 @@
+package publisher
+
+import (
+	"avito_test/domain"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// TimestampHeader carries the Unix time in seconds at which the request was signed.
+	TimestampHeader = "X-Timestamp"
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256, keyed by the webhook
+	// secret, of the TimestampHeader value, a dot and the request body.
+	SignatureHeader = "X-Signature"
+)
+
+// SignatureTolerance is how far from its own clock a receiver should accept the
+// TimestampHeader. A captured request is only good for replay within that window; a
+// receiver that also drops X-Delivery-Id values it has seen in it rejects replays outright.
+const SignatureTolerance = 5 * time.Minute
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleSignature   = errors.New("webhook timestamp outside the tolerance")
+)
+
+// WebhookSender delivers webhook payloads over HTTP.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(timeout time.Duration) *WebhookSender {
+	return &WebhookSender{client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSender) Send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", strconv.Itoa(delivery.EventId))
+	req.Header.Set("X-Event-Type", delivery.EventType)
+	req.Header.Set("X-Delivery-Id", strconv.Itoa(delivery.Id))
+	// Every attempt is signed anew, so a retry is not turned away as stale.
+	timestamp := time.Now().Unix()
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the SignatureHeader value for body sent at timestamp, in Unix seconds.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a webhook request the way a receiver should: the signature must match
+// and the timestamp must be within SignatureTolerance of now.
+func Verify(secret, timestamp, signature string, body []byte, now time.Time) error {
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, sent, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return ErrStaleSignature
+	}
+	return nil
+}
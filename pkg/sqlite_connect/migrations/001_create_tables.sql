@@ -0,0 +1,46 @@
+-- +migrate Up
+CREATE TABLE users
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    email         TEXT UNIQUE NOT NULL,
+    password_hash TEXT        NOT NULL,
+    role          TEXT        NOT NULL CHECK (role IN ('employee', 'moderator'))
+);
+
+CREATE TABLE pvz
+(
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    city              TEXT NOT NULL CHECK (city IN ('Москва', 'Санкт-Петербург', 'Казань')),
+    registration_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE receptions
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    pvz_id     INTEGER REFERENCES pvz (id) ON DELETE CASCADE,
+    status     TEXT                                NOT NULL CHECK (status IN ('in_progress', 'closed')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE products
+(
+    id       INTEGER PRIMARY KEY AUTOINCREMENT,
+    type     TEXT                                NOT NULL CHECK (type IN ('электроника', 'одежда', 'обувь')),
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE reception_products
+(
+    reception_id INTEGER NOT NULL,
+    product_id   INTEGER NOT NULL,
+    PRIMARY KEY (reception_id, product_id),
+    FOREIGN KEY (reception_id) REFERENCES receptions (id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS reception_products;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS receptions;
+DROP TABLE IF EXISTS pvz;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,27 @@
+-- +migrate Up
+CREATE UNIQUE INDEX receptions_one_in_progress_per_pvz ON receptions (pvz_id) WHERE status = 'in_progress';
+
+-- +migrate StatementBegin
+CREATE TRIGGER reception_products_open_only_insert
+    BEFORE INSERT
+    ON reception_products
+    WHEN EXISTS (SELECT 1 FROM receptions WHERE id = NEW.reception_id AND status <> 'in_progress')
+BEGIN
+    SELECT RAISE(ABORT, 'reception is closed');
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE TRIGGER reception_products_open_only_delete
+    BEFORE DELETE
+    ON reception_products
+    WHEN EXISTS (SELECT 1 FROM receptions WHERE id = OLD.reception_id AND status <> 'in_progress')
+BEGIN
+    SELECT RAISE(ABORT, 'reception is closed');
+END;
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS reception_products_open_only_delete;
+DROP TRIGGER IF EXISTS reception_products_open_only_insert;
+DROP INDEX IF EXISTS receptions_one_in_progress_per_pvz;
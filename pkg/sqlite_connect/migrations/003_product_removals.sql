@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE product_removals
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    reception_id INTEGER                             NOT NULL REFERENCES receptions (id) ON DELETE CASCADE,
+    product_id   INTEGER                             NOT NULL,
+    product_type TEXT                                NOT NULL,
+    reason       TEXT                                NOT NULL,
+    removed_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX product_removals_reception_id ON product_removals (reception_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS product_removals;
@@ -0,0 +1,66 @@
+-- SQLite cannot drop the CHECK on pvz.city, so the table is rebuilt. Foreign keys
+-- must be off while the old table is dropped, otherwise its receptions cascade away,
+-- and the pragma has no effect inside a transaction.
+
+-- +migrate Up notransaction
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE cities
+(
+    id      INTEGER PRIMARY KEY AUTOINCREMENT,
+    name    TEXT UNIQUE       NOT NULL,
+    enabled BOOLEAN DEFAULT 1 NOT NULL
+);
+
+INSERT INTO cities (name)
+VALUES ('Москва'),
+       ('Санкт-Петербург'),
+       ('Казань');
+
+INSERT OR IGNORE INTO cities (name)
+SELECT DISTINCT city
+FROM pvz;
+
+CREATE TABLE pvz_new
+(
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    city              TEXT NOT NULL REFERENCES cities (name) ON UPDATE CASCADE,
+    registration_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO pvz_new (id, city, registration_date)
+SELECT id, city, registration_date
+FROM pvz;
+
+DROP TABLE pvz;
+ALTER TABLE pvz_new RENAME TO pvz;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+
+-- +migrate Down notransaction
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE pvz_old
+(
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    city              TEXT NOT NULL CHECK (city IN ('Москва', 'Санкт-Петербург', 'Казань')),
+    registration_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO pvz_old (id, city, registration_date)
+SELECT id, city, registration_date
+FROM pvz;
+
+DROP TABLE pvz;
+ALTER TABLE pvz_old RENAME TO pvz;
+DROP TABLE cities;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
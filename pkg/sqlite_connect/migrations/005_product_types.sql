@@ -0,0 +1,71 @@
+-- The CHECK on products.type is replaced with a foreign key to the catalog, which
+-- again means rebuilding the table with foreign keys off, see 004_cities.sql.
+
+-- +migrate Up notransaction
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE product_types
+(
+    code TEXT PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL
+);
+
+INSERT INTO product_types (code, name)
+VALUES ('electronics', 'электроника'),
+       ('clothes', 'одежда'),
+       ('shoes', 'обувь');
+
+CREATE TABLE products_new
+(
+    id       INTEGER PRIMARY KEY AUTOINCREMENT,
+    type     TEXT                                NOT NULL REFERENCES product_types (code),
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+INSERT INTO products_new (id, type, added_at)
+SELECT p.id, COALESCE(pt.code, p.type), p.added_at
+FROM products p
+         LEFT JOIN product_types pt ON pt.name = p.type;
+
+DROP TABLE products;
+ALTER TABLE products_new RENAME TO products;
+
+UPDATE product_removals
+SET product_type = (SELECT code FROM product_types WHERE name = product_removals.product_type)
+WHERE product_type IN (SELECT name FROM product_types);
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+
+-- +migrate Down notransaction
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE products_old
+(
+    id       INTEGER PRIMARY KEY AUTOINCREMENT,
+    type     TEXT                                NOT NULL CHECK (type IN ('электроника', 'одежда', 'обувь')),
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+INSERT INTO products_old (id, type, added_at)
+SELECT p.id, pt.name, p.added_at
+FROM products p
+         JOIN product_types pt ON pt.code = p.type;
+
+DROP TABLE products;
+ALTER TABLE products_old RENAME TO products;
+
+UPDATE product_removals
+SET product_type = (SELECT name FROM product_types WHERE code = product_removals.product_type)
+WHERE product_type IN (SELECT code FROM product_types);
+
+DROP TABLE product_types;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,10 @@
+-- +migrate Up
+ALTER TABLE products ADD COLUMN barcode TEXT;
+ALTER TABLE products ADD COLUMN order_number TEXT;
+
+CREATE UNIQUE INDEX products_barcode ON products (barcode) WHERE barcode IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS products_barcode;
+ALTER TABLE products DROP COLUMN order_number;
+ALTER TABLE products DROP COLUMN barcode;
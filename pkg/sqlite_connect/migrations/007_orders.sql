@@ -0,0 +1,38 @@
+-- +migrate Up
+ALTER TABLE products ADD COLUMN issued_at TIMESTAMP;
+
+-- Issued products leave stock, so their barcodes may be scanned again.
+DROP INDEX IF EXISTS products_barcode;
+CREATE UNIQUE INDEX products_barcode ON products (barcode) WHERE barcode IS NOT NULL AND issued_at IS NULL;
+
+CREATE TABLE orders
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    pvz_id       INTEGER                             NOT NULL REFERENCES pvz (id) ON DELETE CASCADE,
+    order_number TEXT                                NOT NULL,
+    status       TEXT                                NOT NULL CHECK (status IN ('accepted', 'ready_for_pickup', 'issued', 'refused', 'returned')),
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- An order number and its products are taken only while the order is open, so another
+-- shipment of the same order, or a product back in stock, may make a new order.
+CREATE UNIQUE INDEX orders_open_number ON orders (pvz_id, order_number) WHERE status IN ('accepted', 'ready_for_pickup');
+
+CREATE TABLE order_items
+(
+    order_id   INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products (id),
+    -- cleared once the order is issued, refused or returned
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (order_id, product_id)
+);
+
+CREATE UNIQUE INDEX order_items_open_product ON order_items (product_id) WHERE active;
+
+-- +migrate Down
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+DROP INDEX IF EXISTS products_barcode;
+CREATE UNIQUE INDEX products_barcode ON products (barcode) WHERE barcode IS NOT NULL;
+ALTER TABLE products DROP COLUMN issued_at;
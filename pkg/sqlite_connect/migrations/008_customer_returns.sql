@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE receptions ADD COLUMN kind TEXT NOT NULL DEFAULT 'delivery' CHECK (kind IN ('delivery', 'customer_return'));
+
+CREATE TABLE product_returns
+(
+    product_id          INTEGER NOT NULL PRIMARY KEY REFERENCES products (id) ON DELETE CASCADE,
+    original_product_id INTEGER REFERENCES products (id) ON DELETE SET NULL,
+    reason              TEXT    NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS product_returns;
+ALTER TABLE receptions DROP COLUMN kind;
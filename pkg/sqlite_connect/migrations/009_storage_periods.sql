@@ -0,0 +1,32 @@
+-- +migrate Up
+ALTER TABLE product_types ADD COLUMN storage_days INTEGER NOT NULL DEFAULT 7 CHECK (storage_days > 0);
+
+ALTER TABLE products ADD COLUMN overdue_at TIMESTAMP;
+ALTER TABLE products ADD COLUMN shipped_at TIMESTAMP;
+
+-- Shipped products leave stock like issued ones.
+DROP INDEX IF EXISTS products_barcode;
+CREATE UNIQUE INDEX products_barcode ON products (barcode) WHERE barcode IS NOT NULL AND issued_at IS NULL AND shipped_at IS NULL;
+
+CREATE TABLE return_shipments
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    pvz_id     INTEGER                             NOT NULL REFERENCES pvz (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE return_shipment_items
+(
+    shipment_id INTEGER NOT NULL REFERENCES return_shipments (id) ON DELETE CASCADE,
+    product_id  INTEGER NOT NULL UNIQUE REFERENCES products (id),
+    PRIMARY KEY (shipment_id, product_id)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS return_shipment_items;
+DROP TABLE IF EXISTS return_shipments;
+DROP INDEX IF EXISTS products_barcode;
+CREATE UNIQUE INDEX products_barcode ON products (barcode) WHERE barcode IS NOT NULL AND issued_at IS NULL;
+ALTER TABLE products DROP COLUMN shipped_at;
+ALTER TABLE products DROP COLUMN overdue_at;
+ALTER TABLE product_types DROP COLUMN storage_days;
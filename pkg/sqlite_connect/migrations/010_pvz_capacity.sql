@@ -0,0 +1,15 @@
+-- +migrate Up
+-- A capacity of 0 leaves the PVZ unlimited.
+ALTER TABLE pvz ADD COLUMN capacity INTEGER NOT NULL DEFAULT 0 CHECK (capacity >= 0);
+
+CREATE TABLE pvz_type_capacity
+(
+    pvz_id   INTEGER NOT NULL REFERENCES pvz (id) ON DELETE CASCADE,
+    type     TEXT    NOT NULL REFERENCES product_types (code) ON DELETE CASCADE,
+    capacity INTEGER NOT NULL CHECK (capacity > 0),
+    PRIMARY KEY (pvz_id, type)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS pvz_type_capacity;
+ALTER TABLE pvz DROP COLUMN capacity;
@@ -0,0 +1,35 @@
+-- +migrate Up
+-- event_types is a JSON array; empty event_types, NULL pvz_id and empty city match every event.
+CREATE TABLE webhooks
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    url         TEXT                                NOT NULL,
+    secret      TEXT                                NOT NULL,
+    event_types TEXT      DEFAULT '[]'              NOT NULL,
+    pvz_id      INTEGER REFERENCES pvz (id) ON DELETE CASCADE,
+    city        TEXT      DEFAULT ''                NOT NULL,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id      INTEGER                             NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        INTEGER                             NOT NULL,
+    event_type      TEXT                                NOT NULL,
+    payload         TEXT                                NOT NULL,
+    status          TEXT      DEFAULT 'pending'         NOT NULL CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts        INTEGER   DEFAULT 0                 NOT NULL,
+    next_attempt_at TIMESTAMP                           NOT NULL,
+    last_error      TEXT      DEFAULT ''                NOT NULL,
+    response_status INTEGER   DEFAULT 0                 NOT NULL,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    delivered_at    TIMESTAMP,
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- +migrate Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
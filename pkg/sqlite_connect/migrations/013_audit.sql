@@ -0,0 +1,23 @@
+-- +migrate Up
+-- target_ids and payload are JSON objects; pvz_id copies the "pvzId" target for
+-- filtering and has no foreign key so that calls naming an unknown PVZ are recorded too.
+CREATE TABLE audit_log
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id   TEXT      DEFAULT ''                NOT NULL,
+    role       TEXT      DEFAULT ''                NOT NULL,
+    action     TEXT                                NOT NULL,
+    target_ids TEXT      DEFAULT '{}'              NOT NULL,
+    pvz_id     INTEGER,
+    payload    TEXT,
+    status     INTEGER                             NOT NULL,
+    ip         TEXT      DEFAULT ''                NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX audit_log_created_at ON audit_log (created_at);
+CREATE INDEX audit_log_actor ON audit_log (actor_id, created_at);
+CREATE INDEX audit_log_pvz ON audit_log (pvz_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,58 @@
+package sqlite_connect
+
+import (
+	"avito_test/config"
+	"database/sql"
+	"fmt"
+	"github.com/rubenv/sql-migrate"
+	"log/slog"
+	_ "modernc.org/sqlite"
+)
+
+type SqliteStorage struct {
+	Db *sql.DB
+}
+
+func NewSqliteStorage(cfg config.Sqlite) (*SqliteStorage, error) {
+	connStr := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite", cfg.Path)
+	db, err := sql.Open("sqlite", connStr)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; one connection serializes transactions
+	// the way row locks do in Postgres.
+	db.SetMaxOpenConns(1)
+
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+
+	storage := &SqliteStorage{Db: db}
+
+	if err := storage.runMigrations(cfg.MigrationPath); err != nil {
+		return nil, fmt.Errorf("migrations failed: %v", err)
+	}
+	return storage, nil
+}
+
+func (s *SqliteStorage) runMigrations(path string) error {
+	migrations := &migrate.FileMigrationSource{
+		Dir: path,
+	}
+
+	ms := migrate.MigrationSet{
+		TableName: "schema_migrations",
+	}
+
+	n, err := ms.Exec(s.Db, "sqlite3", migrations, migrate.Up)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		slog.Info("no new migrations to apply")
+	} else {
+		slog.Info("applied database migrations", "count", n)
+	}
+	return nil
+}
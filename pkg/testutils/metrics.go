@@ -0,0 +1,12 @@
+package testutils
+
+import (
+	"avito_test/repository/prometheus"
+	client "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics returns collectors registered in a registry of their own, so tests can build
+// any number of them.
+func Metrics() *prometheus.Metrics {
+	return prometheus.NewMetrics(client.NewRegistry(), nil)
+}
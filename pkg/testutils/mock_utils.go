@@ -25,7 +25,8 @@ func MockReception() domain.Reception {
 func MockProduct() domain.Product {
 	return domain.Product{
 		Id:       1,
-		Type:     "электроника",
+		Type:     "electronics",
+		TypeName: "электроника",
 		DateTime: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 	}
 }
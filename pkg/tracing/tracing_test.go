@@ -0,0 +1,44 @@
+package tracing_test
+
+import (
+	"avito_test/config"
+	"avito_test/pkg/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNew_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	shutdown, err := tracing.New(context.Background(), config.TracingConfig{Exporter: "stdout", ServiceName: "pvz", SampleRatio: 1}, &buf)
+	require.NoError(t, err)
+
+	_, span := tracing.Start(context.Background(), "Pvz.OpenPvz")
+	tracing.End(span, errors.New("boom"))
+	require.NoError(t, shutdown(context.Background()))
+
+	var exported struct {
+		Name   string
+		Status struct{ Code, Description string }
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	assert.Equal(t, "Pvz.OpenPvz", exported.Name)
+	assert.Equal(t, "Error", exported.Status.Code)
+	assert.Equal(t, "boom", exported.Status.Description)
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := tracing.New(context.Background(), config.TracingConfig{Exporter: "jaeger"}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	_, err = tracing.New(context.Background(), config.TracingConfig{Exporter: "otlp"}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	shutdown, err := tracing.New(context.Background(), config.TracingConfig{Exporter: "none"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"context"
+	"time"
+)
+
+// AuditFilter selects audit entries; empty fields match every entry. PvzId matches
+// the "pvzId" target of an entry.
+type AuditFilter struct {
+	ActorId string
+	Role    string
+	Action  string
+	PvzId   int
+	From    *time.Time
+	To      *time.Time
+	Offset  int
+	Limit   int
+}
+
+type Audit interface {
+	AddEntry(ctx context.Context, entry domain.AuditEntry) error
+	// ListEntries returns a page of matching entries, newest first.
+	ListEntries(ctx context.Context, filter AuditFilter) ([]domain.AuditEntry, error)
+	CountEntries(ctx context.Context, filter AuditFilter) (int, error)
+}
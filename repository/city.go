@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+type City interface {
+	CreateCity(ctx context.Context, name string) (domain.City, error)
+	GetCity(ctx context.Context, cityId int) (domain.City, error)
+	GetCityByName(ctx context.Context, name string) (domain.City, error)
+	ListCities(ctx context.Context) ([]domain.City, error)
+	// UpdateCity saves name and enabled of the city; a rename is carried over to its PVZs.
+	UpdateCity(ctx context.Context, city domain.City) (domain.City, error)
+	DeleteCity(ctx context.Context, cityId int) error
+}
@@ -3,6 +3,16 @@ package repository
 import "errors"
 
 var (
-	NotFound              = errors.New("not found")
-	ErrEmailAlreadyExists = errors.New("email already exists")
+	NotFound                    = errors.New("not found")
+	ErrEmailAlreadyExists       = errors.New("email already exists")
+	ErrReceptionInProgress      = errors.New("reception already in progress")
+	ErrReceptionClosed          = errors.New("reception is closed")
+	ErrProductNotFound          = errors.New("product not found in reception")
+	ErrCityAlreadyExists        = errors.New("city already exists")
+	ErrCityInUse                = errors.New("city has pvz")
+	ErrProductTypeAlreadyExists = errors.New("product type already exists")
+	ErrBarcodeAlreadyExists     = errors.New("barcode already in stock")
+	ErrOrderAlreadyExists       = errors.New("order already exists")
+	ErrOrderStatusChanged       = errors.New("order status changed concurrently")
+	ErrProductNotInStock        = errors.New("product is not in stock")
 )
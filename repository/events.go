@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+// EventStream delivers events as soon as their changes commit, including those made by
+// other instances of the service sharing the database.
+type EventStream interface {
+	// Listen calls handle for every event until ctx is done. Events committed while
+	// the connection is being re-established are not replayed.
+	Listen(ctx context.Context, handle func(domain.Event)) error
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"maps"
+	"sort"
+	"strconv"
+)
+
+type AuditRepo struct {
+	audit *Storage
+}
+
+func NewAuditRepo(audit *Storage) *AuditRepo {
+	return &AuditRepo{audit: audit}
+}
+
+func (a *AuditRepo) AddEntry(ctx context.Context, entry domain.AuditEntry) error {
+	defer a.audit.lock(ctx)()
+
+	entry.Id = a.audit.nextId("audit_log")
+	entry.TargetIds = maps.Clone(entry.TargetIds)
+	entry.Payload = append([]byte(nil), entry.Payload...)
+	if len(entry.TargetIds) == 0 {
+		entry.TargetIds = nil
+	}
+	if len(entry.Payload) == 0 {
+		entry.Payload = nil
+	}
+	push(a.audit, &a.audit.data.audit, entry)
+	return nil
+}
+
+func (a *AuditRepo) ListEntries(ctx context.Context, filter repository.AuditFilter) ([]domain.AuditEntry, error) {
+	defer a.audit.lock(ctx)()
+
+	matched := a.matchEntries(filter)
+	entries := make([]domain.AuditEntry, 0)
+	for i := filter.Offset; i < len(matched) && len(entries) < filter.Limit; i++ {
+		entry := matched[i]
+		entry.TargetIds = maps.Clone(entry.TargetIds)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (a *AuditRepo) CountEntries(ctx context.Context, filter repository.AuditFilter) (int, error) {
+	defer a.audit.lock(ctx)()
+
+	return len(a.matchEntries(filter)), nil
+}
+
+// matchEntries returns the entries passing the filter ordered like the SQL backends,
+// newest first. The caller must hold the storage lock.
+func (a *AuditRepo) matchEntries(filter repository.AuditFilter) []domain.AuditEntry {
+	var matched []domain.AuditEntry
+	for _, entry := range a.audit.data.audit {
+		switch {
+		case filter.ActorId != "" && entry.ActorId != filter.ActorId,
+			filter.Role != "" && entry.Role != filter.Role,
+			filter.Action != "" && entry.Action != filter.Action,
+			filter.PvzId != 0 && entry.TargetIds[domain.AuditTargetPvz] != strconv.Itoa(filter.PvzId),
+			filter.From != nil && entry.CreatedAt.Before(*filter.From),
+			filter.To != nil && entry.CreatedAt.After(*filter.To):
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].Id > matched[j].Id
+		}
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+	return matched
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"sort"
+)
+
+type CityRepo struct {
+	cities *Storage
+}
+
+func NewCityRepo(cities *Storage) *CityRepo {
+	return &CityRepo{cities: cities}
+}
+
+func (c *CityRepo) CreateCity(ctx context.Context, name string) (domain.City, error) {
+	defer c.cities.lock(ctx)()
+
+	if _, ok := c.cities.cityByName(name); ok {
+		return domain.City{}, repository.ErrCityAlreadyExists
+	}
+
+	city := domain.City{Id: c.cities.nextId("cities"), Name: name, Enabled: true}
+	put(c.cities, c.cities.data.cities, city.Id, city)
+	return city, nil
+}
+
+func (c *CityRepo) GetCity(ctx context.Context, cityId int) (domain.City, error) {
+	defer c.cities.lock(ctx)()
+
+	city, ok := c.cities.data.cities[cityId]
+	if !ok {
+		return domain.City{}, repository.NotFound
+	}
+	return city, nil
+}
+
+func (c *CityRepo) GetCityByName(ctx context.Context, name string) (domain.City, error) {
+	defer c.cities.lock(ctx)()
+
+	city, ok := c.cities.cityByName(name)
+	if !ok {
+		return domain.City{}, repository.NotFound
+	}
+	return city, nil
+}
+
+func (c *CityRepo) ListCities(ctx context.Context) ([]domain.City, error) {
+	defer c.cities.lock(ctx)()
+
+	cities := make([]domain.City, 0, len(c.cities.data.cities))
+	for _, city := range c.cities.data.cities {
+		cities = append(cities, city)
+	}
+	sort.Slice(cities, func(i, j int) bool { return cities[i].Id < cities[j].Id })
+	return cities, nil
+}
+
+func (c *CityRepo) UpdateCity(ctx context.Context, city domain.City) (domain.City, error) {
+	defer c.cities.lock(ctx)()
+
+	old, ok := c.cities.data.cities[city.Id]
+	if !ok {
+		return domain.City{}, repository.NotFound
+	}
+	if other, ok := c.cities.cityByName(city.Name); ok && other.Id != city.Id {
+		return domain.City{}, repository.ErrCityAlreadyExists
+	}
+
+	if old.Name != city.Name {
+		for id, pvz := range c.cities.data.pvz {
+			if pvz.City == old.Name {
+				pvz.City = city.Name
+				put(c.cities, c.cities.data.pvz, id, pvz)
+			}
+		}
+	}
+	put(c.cities, c.cities.data.cities, city.Id, city)
+	return city, nil
+}
+
+func (c *CityRepo) DeleteCity(ctx context.Context, cityId int) error {
+	defer c.cities.lock(ctx)()
+
+	city, ok := c.cities.data.cities[cityId]
+	if !ok {
+		return repository.NotFound
+	}
+	for _, pvz := range c.cities.data.pvz {
+		if pvz.City == city.Name {
+			return repository.ErrCityInUse
+		}
+	}
+
+	drop(c.cities, c.cities.data.cities, cityId)
+	return nil
+}
+
+// cityByName looks a city up by its unique name; the caller must hold the lock.
+func (s *Storage) cityByName(name string) (domain.City, bool) {
+	for _, city := range s.data.cities {
+		if city.Name == name {
+			return city, true
+		}
+	}
+	return domain.City{}, false
+}
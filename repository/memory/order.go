@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"slices"
+	"sort"
+	"time"
+)
+
+type OrderRepo struct {
+	orders *Storage
+}
+
+func NewOrderRepo(orders *Storage) *OrderRepo {
+	return &OrderRepo{orders: orders}
+}
+
+func (o *OrderRepo) ListOrderableProducts(ctx context.Context, pvzId int, orderNumber string) ([]domain.Product, error) {
+	defer o.orders.lock(ctx)()
+
+	ordered := make(map[int]bool)
+	for orderId, ids := range o.orders.data.orderProducts {
+		if !domain.IsOrderOpen(o.orders.data.orders[orderId].Status) {
+			continue
+		}
+		for _, id := range ids {
+			ordered[id] = true
+		}
+	}
+
+	products := make([]domain.Product, 0)
+	for receptionId, ids := range o.orders.data.receptionProducts {
+		reception := o.orders.data.receptions[receptionId]
+		if reception.PvzId != pvzId || reception.Kind != domain.ReceptionKindDelivery || reception.Status != "closed" {
+			continue
+		}
+		for _, id := range ids {
+			product := o.orders.data.products[id]
+			if product.OrderNumber == orderNumber && inStock(product) && !ordered[id] {
+				products = append(products, product)
+			}
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].Id < products[j].Id })
+	return products, nil
+}
+
+func (o *OrderRepo) CreateOrder(ctx context.Context, order domain.Order, productIds []int) (domain.Order, error) {
+	defer o.orders.lock(ctx)()
+
+	// Like the partial unique indexes of the SQL storages, only open orders count.
+	for _, existing := range o.orders.data.orders {
+		if existing.PvzId == order.PvzId && existing.OrderNumber == order.OrderNumber && domain.IsOrderOpen(existing.Status) {
+			return domain.Order{}, repository.ErrOrderAlreadyExists
+		}
+	}
+	for orderId, ids := range o.orders.data.orderProducts {
+		if !domain.IsOrderOpen(o.orders.data.orders[orderId].Status) {
+			continue
+		}
+		for _, id := range ids {
+			for _, productId := range productIds {
+				if id == productId {
+					return domain.Order{}, repository.ErrOrderAlreadyExists
+				}
+			}
+		}
+	}
+
+	order.Id = o.orders.nextId("orders")
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+	if err := o.orders.addEvent(domain.EventOrderCreated, order.PvzId, order); err != nil {
+		return domain.Order{}, err
+	}
+	put(o.orders, o.orders.data.orders, order.Id, order)
+	put(o.orders, o.orders.data.orderProducts, order.Id, append([]int(nil), productIds...))
+	return order, nil
+}
+
+func (o *OrderRepo) GetOrder(ctx context.Context, orderId int) (domain.OrderWithProducts, error) {
+	defer o.orders.lock(ctx)()
+
+	order, ok := o.orders.data.orders[orderId]
+	if !ok {
+		return domain.OrderWithProducts{}, repository.NotFound
+	}
+
+	products := make([]domain.Product, 0)
+	for _, productId := range o.orders.data.orderProducts[orderId] {
+		products = append(products, o.orders.data.products[productId])
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].Id < products[j].Id })
+	return domain.OrderWithProducts{Order: order, Products: products}, nil
+}
+
+func (o *OrderRepo) ListOrders(ctx context.Context, pvzId int, status string) ([]domain.Order, error) {
+	defer o.orders.lock(ctx)()
+
+	orders := make([]domain.Order, 0)
+	for _, order := range o.orders.data.orders {
+		if order.PvzId == pvzId && (status == "" || order.Status == status) {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].Id > orders[j].Id
+		}
+		return orders[i].CreatedAt.After(orders[j].CreatedAt)
+	})
+	return orders, nil
+}
+
+func (o *OrderRepo) UpdateOrderStatus(ctx context.Context, orderId int, from string, status string) (domain.Order, error) {
+	defer o.orders.lock(ctx)()
+
+	order, ok := o.orders.data.orders[orderId]
+	if !ok || order.Status != from {
+		return domain.Order{}, repository.ErrOrderStatusChanged
+	}
+	order.Status = status
+	order.UpdatedAt = time.Now()
+	if err := o.orders.addEvent(domain.EventOrderStatusChanged, order.PvzId, order); err != nil {
+		return domain.Order{}, err
+	}
+	put(o.orders, o.orders.data.orders, orderId, order)
+	return order, nil
+}
+
+func (o *OrderRepo) IssueOrderProducts(ctx context.Context, orderId int) error {
+	defer o.orders.lock(ctx)()
+
+	now := time.Now()
+	for _, productId := range o.orders.data.orderProducts[orderId] {
+		product := o.orders.data.products[productId]
+		product.IssuedAt = &now
+		put(o.orders, o.orders.data.products, productId, product)
+	}
+	return nil
+}
+
+func (o *OrderRepo) ReturnOrdersOfProducts(ctx context.Context, productIds []int) error {
+	defer o.orders.lock(ctx)()
+
+	now := time.Now()
+	var returned []domain.Order
+	for orderId, ids := range o.orders.data.orderProducts {
+		order := o.orders.data.orders[orderId]
+		if order.Status != domain.OrderStatusAccepted && order.Status != domain.OrderStatusReadyForPickup {
+			continue
+		}
+		for _, id := range ids {
+			if slices.Contains(productIds, id) {
+				order.Status = domain.OrderStatusReturned
+				order.UpdatedAt = now
+				returned = append(returned, order)
+				break
+			}
+		}
+	}
+
+	sort.Slice(returned, func(i, j int) bool { return returned[i].Id < returned[j].Id })
+	for _, order := range returned {
+		if err := o.orders.addEvent(domain.EventOrderStatusChanged, order.PvzId, order); err != nil {
+			return err
+		}
+		put(o.orders, o.orders.data.orders, order.Id, order)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+type outboxRecord struct {
+	event        domain.Event
+	dispatchedAt *time.Time
+}
+
+type outboxDeliveryKey struct {
+	consumer string
+	eventId  int
+}
+
+type outboxLease struct {
+	owner       string
+	leasedUntil time.Time
+}
+
+type OutboxRepo struct {
+	outbox *Storage
+}
+
+func NewOutboxRepo(outbox *Storage) *OutboxRepo {
+	return &OutboxRepo{outbox: outbox}
+}
+
+func (o *OutboxRepo) Lease(ctx context.Context, name string, owner string, now time.Time, until time.Time) (bool, error) {
+	defer o.outbox.lock(ctx)()
+
+	if lease, ok := o.outbox.data.outboxLeases[name]; ok && lease.owner != owner && lease.leasedUntil.After(now) {
+		return false, nil
+	}
+	put(o.outbox, o.outbox.data.outboxLeases, name, outboxLease{owner: owner, leasedUntil: until})
+	return true, nil
+}
+
+func (o *OutboxRepo) ListPending(ctx context.Context, limit int) ([]domain.Event, error) {
+	defer o.outbox.lock(ctx)()
+
+	events := make([]domain.Event, 0)
+	for _, record := range o.outbox.data.outbox {
+		if len(events) == limit {
+			break
+		}
+		if record.dispatchedAt == nil {
+			events = append(events, record.event)
+		}
+	}
+	return events, nil
+}
+
+func (o *OutboxRepo) ListEvents(ctx context.Context, pvzId int, afterId int, limit int) ([]domain.Event, error) {
+	defer o.outbox.lock(ctx)()
+
+	events := make([]domain.Event, 0)
+	for _, record := range o.outbox.data.outbox {
+		if len(events) == limit {
+			break
+		}
+		if record.event.PvzId == pvzId && record.event.Id > afterId {
+			events = append(events, record.event)
+		}
+	}
+	return events, nil
+}
+
+func (o *OutboxRepo) Dispatch(ctx context.Context, eventId int, consumers []string) error {
+	defer o.outbox.lock(ctx)()
+
+	i := o.outbox.outboxEntry(eventId)
+	if i < 0 {
+		return nil
+	}
+	record := o.outbox.data.outbox[i]
+	now := time.Now()
+	for _, consumer := range consumers {
+		key := outboxDeliveryKey{consumer: consumer, eventId: eventId}
+		if _, ok := o.outbox.data.outboxDeliveries[key]; ok {
+			continue
+		}
+		put(o.outbox, o.outbox.data.outboxDeliveries, key, domain.OutboxDelivery{
+			Consumer:      consumer,
+			Event:         record.event,
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: now,
+		})
+	}
+	record.dispatchedAt = &now
+	putAt(o.outbox, &o.outbox.data.outbox, i, record)
+	return nil
+}
+
+func (o *OutboxRepo) ListQueued(ctx context.Context, consumer string, limit int) ([]domain.OutboxDelivery, error) {
+	defer o.outbox.lock(ctx)()
+
+	deliveries := make([]domain.OutboxDelivery, 0)
+	for key, delivery := range o.outbox.data.outboxDeliveries {
+		if key.consumer == consumer && delivery.Status == domain.DeliveryStatusPending {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Event.Id < deliveries[j].Event.Id })
+	return deliveries[:min(limit, len(deliveries))], nil
+}
+
+func (o *OutboxRepo) UpdateDelivery(ctx context.Context, delivery domain.OutboxDelivery) error {
+	defer o.outbox.lock(ctx)()
+
+	key := outboxDeliveryKey{consumer: delivery.Consumer, eventId: delivery.Event.Id}
+	stored, ok := o.outbox.data.outboxDeliveries[key]
+	if !ok {
+		return repository.NotFound
+	}
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastError = delivery.LastError
+	stored.DeliveredAt = delivery.DeliveredAt
+	put(o.outbox, o.outbox.data.outboxDeliveries, key, stored)
+	return nil
+}
+
+// outboxEntry returns the index of the outbox entry with the given id, or -1 if there is
+// none; the caller must hold the lock.
+func (s *Storage) outboxEntry(eventId int) int {
+	for i := range s.data.outbox {
+		if s.data.outbox[i].event.Id == eventId {
+			return i
+		}
+	}
+	return -1
+}
+
+// addEvent records an event in the outbox; the caller must hold the lock and
+// make the change it describes under the same lock.
+func (s *Storage) addEvent(eventType string, pvzId int, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	push(s, &s.data.outbox, outboxRecord{event: domain.Event{
+		Id:        s.nextId("outbox"),
+		Type:      eventType,
+		PvzId:     pvzId,
+		Payload:   raw,
+		CreatedAt: time.Now(),
+	}})
+	return nil
+}
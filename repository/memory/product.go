@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"fmt"
+	"time"
+)
+
+type ProductRepo struct {
+	products *Storage
+}
+
+func NewProductRepo(products *Storage) *ProductRepo {
+	return &ProductRepo{products: products}
+}
+
+func (r *ProductRepo) AddProduct(ctx context.Context, product domain.Product) (domain.Product, error) {
+	defer r.products.lock(ctx)()
+
+	productType, ok := r.products.data.productTypes[product.Type]
+	if !ok {
+		return domain.Product{}, fmt.Errorf("unknown product type %q", product.Type)
+	}
+	if _, ok := r.products.productByBarcode(product.Barcode); ok {
+		return domain.Product{}, repository.ErrBarcodeAlreadyExists
+	}
+
+	product.Id = r.products.nextId("products")
+	product.TypeName = productType.Name
+	product.DateTime = time.Now()
+	put(r.products, r.products.data.products, product.Id, product)
+	return product, nil
+}
+
+func (r *ProductRepo) GetProduct(ctx context.Context, productId int) (domain.Product, error) {
+	defer r.products.lock(ctx)()
+
+	product, ok := r.products.data.products[productId]
+	if !ok {
+		return domain.Product{}, repository.NotFound
+	}
+	return product, nil
+}
+
+func (r *ProductRepo) DeleteProduct(ctx context.Context, productId int) error {
+	defer r.products.lock(ctx)()
+
+	drop(r.products, r.products.data.products, productId)
+	for receptionId, ids := range r.products.data.receptionProducts {
+		put(r.products, r.products.data.receptionProducts, receptionId, removeId(ids, productId))
+	}
+	return nil
+}
+
+func (r *ProductRepo) GetProductByBarcode(ctx context.Context, barcode string) (domain.ProductLocation, error) {
+	defer r.products.lock(ctx)()
+
+	product, ok := r.products.productByBarcode(barcode)
+	if !ok {
+		return domain.ProductLocation{}, repository.NotFound
+	}
+	for receptionId, ids := range r.products.data.receptionProducts {
+		for _, id := range ids {
+			if id == product.Id {
+				reception := r.products.data.receptions[receptionId]
+				return domain.ProductLocation{Product: product, ReceptionId: reception.Id, PvzId: reception.PvzId}, nil
+			}
+		}
+	}
+	return domain.ProductLocation{}, repository.NotFound
+}
+
+// productByBarcode finds a product in stock by its barcode, products without one
+// never match; the caller must hold the lock.
+func (s *Storage) productByBarcode(barcode string) (domain.Product, bool) {
+	if barcode == "" {
+		return domain.Product{}, false
+	}
+	for _, product := range s.data.products {
+		if product.Barcode == barcode && inStock(product) {
+			return product, true
+		}
+	}
+	return domain.Product{}, false
+}
+
+func removeId(ids []int, id int) []int {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"sort"
+)
+
+type ProductTypeRepo struct {
+	productTypes *Storage
+}
+
+func NewProductTypeRepo(productTypes *Storage) *ProductTypeRepo {
+	return &ProductTypeRepo{productTypes: productTypes}
+}
+
+func (r *ProductTypeRepo) CreateProductType(ctx context.Context, productType domain.ProductType) (domain.ProductType, error) {
+	defer r.productTypes.lock(ctx)()
+
+	for _, existing := range r.productTypes.data.productTypes {
+		// A code may not repeat the name of another type, nor a name its code, so that a
+		// lookup by either stays unambiguous.
+		if existing.Code == productType.Code || existing.Name == productType.Name ||
+			existing.Code == productType.Name || existing.Name == productType.Code {
+			return domain.ProductType{}, repository.ErrProductTypeAlreadyExists
+		}
+	}
+
+	put(r.productTypes, r.productTypes.data.productTypes, productType.Code, productType)
+	return productType, nil
+}
+
+func (r *ProductTypeRepo) GetProductType(ctx context.Context, codeOrName string) (domain.ProductType, error) {
+	defer r.productTypes.lock(ctx)()
+
+	if productType, ok := r.productTypes.data.productTypes[codeOrName]; ok {
+		return productType, nil
+	}
+	for _, productType := range r.productTypes.data.productTypes {
+		if productType.Name == codeOrName {
+			return productType, nil
+		}
+	}
+	return domain.ProductType{}, repository.NotFound
+}
+
+func (r *ProductTypeRepo) ListProductTypes(ctx context.Context) ([]domain.ProductType, error) {
+	defer r.productTypes.lock(ctx)()
+
+	productTypes := make([]domain.ProductType, 0, len(r.productTypes.data.productTypes))
+	for _, productType := range r.productTypes.data.productTypes {
+		productTypes = append(productTypes, productType)
+	}
+	sort.Slice(productTypes, func(i, j int) bool { return productTypes[i].Code < productTypes[j].Code })
+	return productTypes, nil
+}
+
+func (r *ProductTypeRepo) SetStorageDays(ctx context.Context, code string, storageDays int) (domain.ProductType, error) {
+	defer r.productTypes.lock(ctx)()
+
+	productType, ok := r.productTypes.data.productTypes[code]
+	if !ok {
+		return domain.ProductType{}, repository.NotFound
+	}
+	productType.StorageDays = storageDays
+	put(r.productTypes, r.productTypes.data.productTypes, code, productType)
+	return productType, nil
+}
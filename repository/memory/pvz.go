@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+)
+
+type PvzRepo struct {
+	pvz *Storage
+}
+
+func NewPvzRepo(pvz *Storage) *PvzRepo {
+	return &PvzRepo{pvz: pvz}
+}
+
+func (p *PvzRepo) OpenPvz(ctx context.Context, city string) (domain.Pvz, error) {
+	defer p.pvz.lock(ctx)()
+
+	if _, ok := p.pvz.cityByName(city); !ok {
+		return domain.Pvz{}, fmt.Errorf("unknown city %q", city)
+	}
+
+	pvz := domain.Pvz{Id: p.pvz.nextId("pvz"), City: city, RegistrationDate: time.Now()}
+	if err := p.pvz.addEvent(domain.EventPvzOpened, pvz.Id, pvz); err != nil {
+		return domain.Pvz{}, err
+	}
+	put(p.pvz, p.pvz.data.pvz, pvz.Id, pvz)
+	return pvz, nil
+}
+
+func (p *PvzRepo) GetPvz(ctx context.Context, pvzID int) (domain.Pvz, error) {
+	defer p.pvz.lock(ctx)()
+
+	pvz, ok := p.pvz.data.pvz[pvzID]
+	if !ok {
+		return domain.Pvz{}, repository.NotFound
+	}
+	return clonePvz(pvz), nil
+}
+
+// SetCapacity stores a fresh TypeCapacity map, so copies of the storage taken for
+// transactions never share a map that is written to.
+func (p *PvzRepo) SetCapacity(ctx context.Context, pvzId int, capacity int, typeCapacity map[string]int) (domain.Pvz, error) {
+	defer p.pvz.lock(ctx)()
+
+	pvz, ok := p.pvz.data.pvz[pvzId]
+	if !ok {
+		return domain.Pvz{}, repository.NotFound
+	}
+	pvz.Capacity = capacity
+	pvz.TypeCapacity = nil
+	if len(typeCapacity) > 0 {
+		pvz.TypeCapacity = maps.Clone(typeCapacity)
+	}
+	if err := p.pvz.addEvent(domain.EventPvzCapacityChanged, pvzId, pvz); err != nil {
+		return domain.Pvz{}, err
+	}
+	put(p.pvz, p.pvz.data.pvz, pvzId, pvz)
+	return clonePvz(pvz), nil
+}
+
+func (p *PvzRepo) GetOccupancy(ctx context.Context, pvzId int) (domain.Occupancy, error) {
+	defer p.pvz.lock(ctx)()
+
+	return p.occupancy(pvzId), nil
+}
+
+// occupancy counts the products in stock at the PVZ. The caller must hold the storage lock.
+func (p *PvzRepo) occupancy(pvzId int) domain.Occupancy {
+	occupancy := domain.Occupancy{ByType: make(map[string]int)}
+	for receptionId, ids := range p.pvz.data.receptionProducts {
+		if p.pvz.data.receptions[receptionId].PvzId != pvzId {
+			continue
+		}
+		for _, id := range ids {
+			product := p.pvz.data.products[id]
+			if inStock(product) {
+				occupancy.Total++
+				occupancy.ByType[product.Type]++
+			}
+		}
+	}
+	return occupancy
+}
+
+func clonePvz(pvz domain.Pvz) domain.Pvz {
+	pvz.TypeCapacity = maps.Clone(pvz.TypeCapacity)
+	return pvz
+}
+
+// GetPvzListWithFilter mirrors the SQL backends: PVZs are paged by id and, when a date
+// filter is set, only PVZs with receptions in the range are listed together with them.
+func (p *PvzRepo) GetPvzListWithFilter(ctx context.Context, filter repository.PvzListFilter) ([]usecases.PvzWithReceptions, error) {
+	defer p.pvz.lock(ctx)()
+
+	matched := p.matchPvz(filter.StartDate, filter.EndDate)
+
+	var page []usecases.PvzWithReceptions
+	skipped := 0
+	for _, item := range matched {
+		if filter.AfterId > 0 {
+			if item.Pvz.Id <= filter.AfterId {
+				continue
+			}
+		} else if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		if len(page) == filter.Limit {
+			break
+		}
+		page = append(page, item)
+	}
+
+	return page, nil
+}
+
+func (p *PvzRepo) CountPvzWithFilter(ctx context.Context, startDate, endDate *time.Time) (int, error) {
+	defer p.pvz.lock(ctx)()
+
+	return len(p.matchPvz(startDate, endDate)), nil
+}
+
+// matchPvz returns every PVZ passing the date filter ordered by id, with receptions
+// newest first. The caller must hold the storage lock.
+func (p *PvzRepo) matchPvz(startDate, endDate *time.Time) []usecases.PvzWithReceptions {
+	pvzList := make([]domain.Pvz, 0, len(p.pvz.data.pvz))
+	for _, pvz := range p.pvz.data.pvz {
+		pvzList = append(pvzList, pvz)
+	}
+	sort.Slice(pvzList, func(i, j int) bool { return pvzList[i].Id < pvzList[j].Id })
+
+	filtered := startDate != nil || endDate != nil
+	var result []usecases.PvzWithReceptions
+	for _, pvz := range pvzList {
+		var receptions []domain.Reception
+		for _, rec := range p.pvz.data.receptions {
+			if rec.PvzId != pvz.Id {
+				continue
+			}
+			if startDate != nil && rec.StartDate.Before(*startDate) {
+				continue
+			}
+			if endDate != nil && rec.StartDate.After(*endDate) {
+				continue
+			}
+			receptions = append(receptions, rec)
+		}
+		if len(receptions) == 0 && filtered {
+			continue
+		}
+		sort.Slice(receptions, func(i, j int) bool {
+			if receptions[i].StartDate.Equal(receptions[j].StartDate) {
+				return receptions[i].Id > receptions[j].Id
+			}
+			return receptions[i].StartDate.After(receptions[j].StartDate)
+		})
+
+		item := usecases.PvzWithReceptions{Pvz: clonePvz(pvz), Receptions: []domain.ReceptionWithProducts{}, Occupancy: p.occupancy(pvz.Id)}
+		for _, rec := range receptions {
+			var products []domain.Product
+			for _, productId := range p.pvz.data.receptionProducts[rec.Id] {
+				products = append(products, p.pvz.data.products[productId])
+			}
+			item.Receptions = append(item.Receptions, domain.ReceptionWithProducts{
+				Reception: rec,
+				Products:  products,
+			})
+		}
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type ReceptionRepo struct {
+	receptions *Storage
+}
+
+func NewReceptionRepo(receptions *Storage) *ReceptionRepo {
+	return &ReceptionRepo{receptions: receptions}
+}
+
+func (r *ReceptionRepo) StartReception(ctx context.Context, pvzId int, kind string) (domain.Reception, error) {
+	defer r.receptions.lock(ctx)()
+
+	if _, ok := r.receptions.data.pvz[pvzId]; !ok {
+		return domain.Reception{}, repository.NotFound
+	}
+	for _, rec := range r.receptions.data.receptions {
+		if rec.PvzId == pvzId && rec.Status == "in_progress" {
+			return domain.Reception{}, repository.ErrReceptionInProgress
+		}
+	}
+
+	rec := domain.Reception{
+		Id:        r.receptions.nextId("receptions"),
+		PvzId:     pvzId,
+		StartDate: time.Now(),
+		Status:    "in_progress",
+		Kind:      kind,
+	}
+	if err := r.receptions.addEvent(domain.EventReceptionStarted, pvzId, rec); err != nil {
+		return domain.Reception{}, err
+	}
+	put(r.receptions, r.receptions.data.receptions, rec.Id, rec)
+	return rec, nil
+}
+
+func (r *ReceptionRepo) CloseReception(ctx context.Context, pvzId int) (domain.Reception, error) {
+	defer r.receptions.lock(ctx)()
+
+	rec, ok := r.last(pvzId)
+	if !ok || rec.Status != "in_progress" {
+		return domain.Reception{}, repository.ErrReceptionClosed
+	}
+
+	rec.Status = "closed"
+	if err := r.receptions.addEvent(domain.EventReceptionClosed, pvzId, rec); err != nil {
+		return domain.Reception{}, err
+	}
+	put(r.receptions, r.receptions.data.receptions, rec.Id, rec)
+	return rec, nil
+}
+
+func (r *ReceptionRepo) GetLastReception(ctx context.Context, pvzId int) (domain.Reception, error) {
+	defer r.receptions.lock(ctx)()
+
+	rec, ok := r.last(pvzId)
+	if !ok {
+		return domain.Reception{}, repository.NotFound
+	}
+	return rec, nil
+}
+
+func (r *ReceptionRepo) AddProduct(ctx context.Context, pvzId int, productId int) error {
+	defer r.receptions.lock(ctx)()
+
+	rec, ok := r.last(pvzId)
+	if !ok {
+		return repository.NotFound
+	}
+	if rec.Status != "in_progress" {
+		return repository.ErrReceptionClosed
+	}
+	product, ok := r.receptions.data.products[productId]
+	if !ok {
+		return repository.NotFound
+	}
+
+	event := domain.ProductEvent{ReceptionId: rec.Id, ProductId: productId, Type: product.Type}
+	if err := r.receptions.addEvent(domain.EventProductAdded, pvzId, event); err != nil {
+		return err
+	}
+	put(r.receptions, r.receptions.data.receptionProducts, rec.Id, append(r.receptions.data.receptionProducts[rec.Id], productId))
+	return nil
+}
+
+func (r *ReceptionRepo) DeleteProduct(ctx context.Context, pvzId int, productId int, reason string) (string, error) {
+	defer r.receptions.lock(ctx)()
+
+	rec, ok := r.last(pvzId)
+	if !ok {
+		return "", repository.NotFound
+	}
+	if rec.Status != "in_progress" {
+		return "", repository.ErrReceptionClosed
+	}
+
+	ids := r.receptions.data.receptionProducts[rec.Id]
+	if productId == 0 {
+		for _, id := range ids {
+			productId = max(productId, id)
+		}
+	}
+	if productId == 0 || !slices.Contains(ids, productId) {
+		return "", repository.ErrProductNotFound
+	}
+
+	productType := r.receptions.data.products[productId].Type
+	event := domain.ProductEvent{ReceptionId: rec.Id, ProductId: productId, Type: productType, Reason: reason}
+	if err := r.receptions.addEvent(domain.EventProductRemoved, pvzId, event); err != nil {
+		return "", err
+	}
+	put(r.receptions, r.receptions.data.receptionProducts, rec.Id, removeId(ids, productId))
+	push(r.receptions, &r.receptions.data.removals, domain.ProductRemoval{
+		Id:          r.receptions.nextId("product_removals"),
+		ReceptionId: rec.Id,
+		ProductId:   productId,
+		ProductType: productType,
+		Reason:      reason,
+		RemovedAt:   time.Now(),
+	})
+	return strconv.Itoa(productId), nil
+}
+
+func (r *ReceptionRepo) ListRemovals(ctx context.Context, receptionId int) ([]domain.ProductRemoval, error) {
+	defer r.receptions.lock(ctx)()
+
+	removals := make([]domain.ProductRemoval, 0)
+	for _, removal := range r.receptions.data.removals {
+		if removal.ReceptionId == receptionId {
+			removals = append(removals, removal)
+		}
+	}
+	return removals, nil
+}
+
+// last returns the most recently started reception of the PVZ; the caller must hold the lock.
+func (r *ReceptionRepo) last(pvzId int) (domain.Reception, bool) {
+	var last domain.Reception
+	found := false
+	for _, rec := range r.receptions.data.receptions {
+		if rec.PvzId != pvzId {
+			continue
+		}
+		if !found || rec.StartDate.After(last.StartDate) ||
+			(rec.StartDate.Equal(last.StartDate) && rec.Id > last.Id) {
+			last = rec
+			found = true
+		}
+	}
+	return last, found
+}
+
+func (r *ReceptionRepo) ListReceptions(ctx context.Context, pvzId int, filter usecases.ReceptionFilter) ([]domain.Reception, error) {
+	defer r.receptions.lock(ctx)()
+
+	receptions := make([]domain.Reception, 0)
+	for _, rec := range r.receptions.data.receptions {
+		if rec.PvzId != pvzId {
+			continue
+		}
+		if filter.Status != "" && rec.Status != filter.Status {
+			continue
+		}
+		if filter.StartDate != nil && rec.StartDate.Before(*filter.StartDate) {
+			continue
+		}
+		if filter.EndDate != nil && rec.StartDate.After(*filter.EndDate) {
+			continue
+		}
+		receptions = append(receptions, rec)
+	}
+	sort.Slice(receptions, func(i, j int) bool {
+		if receptions[i].StartDate.Equal(receptions[j].StartDate) {
+			return receptions[i].Id > receptions[j].Id
+		}
+		return receptions[i].StartDate.After(receptions[j].StartDate)
+	})
+	return receptions, nil
+}
+
+func (r *ReceptionRepo) CountOpenReceptions(ctx context.Context) (map[string]int, error) {
+	defer r.receptions.lock(ctx)()
+
+	open := make(map[string]int)
+	for _, rec := range r.receptions.data.receptions {
+		if rec.Status == "in_progress" {
+			open[r.receptions.data.pvz[rec.PvzId].City]++
+		}
+	}
+	return open, nil
+}
+
+func (r *ReceptionRepo) GetReception(ctx context.Context, receptionId int) (domain.ReceptionWithProducts, error) {
+	defer r.receptions.lock(ctx)()
+
+	rec, ok := r.receptions.data.receptions[receptionId]
+	if !ok {
+		return domain.ReceptionWithProducts{}, repository.NotFound
+	}
+
+	products := make([]domain.Product, 0)
+	for _, productId := range r.receptions.data.receptionProducts[receptionId] {
+		products = append(products, r.receptions.data.products[productId])
+	}
+	return domain.ReceptionWithProducts{Reception: rec, Products: products}, nil
+}
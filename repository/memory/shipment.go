@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"sort"
+	"time"
+)
+
+type ShipmentRepo struct {
+	shipments *Storage
+}
+
+func NewShipmentRepo(shipments *Storage) *ShipmentRepo {
+	return &ShipmentRepo{shipments: shipments}
+}
+
+func (s *ShipmentRepo) MarkOverdue(ctx context.Context, now time.Time) (int, error) {
+	defer s.shipments.lock(ctx)()
+
+	marked := 0
+	for receptionId, ids := range s.shipments.data.receptionProducts {
+		reception := s.shipments.data.receptions[receptionId]
+		if reception.Status != "closed" {
+			continue
+		}
+		for _, id := range ids {
+			product := s.shipments.data.products[id]
+			if product.OverdueAt != nil || !inStock(product) {
+				continue
+			}
+			storageDays := s.shipments.data.productTypes[product.Type].StorageDays
+			if product.DateTime.AddDate(0, 0, storageDays).Before(now) {
+				overdueAt := now
+				product.OverdueAt = &overdueAt
+				put(s.shipments, s.shipments.data.products, id, product)
+				marked++
+			}
+		}
+	}
+	return marked, nil
+}
+
+func (s *ShipmentRepo) ListOverdueProducts(ctx context.Context, pvzId int) ([]domain.Product, error) {
+	defer s.shipments.lock(ctx)()
+
+	products := make([]domain.Product, 0)
+	for receptionId, ids := range s.shipments.data.receptionProducts {
+		if s.shipments.data.receptions[receptionId].PvzId != pvzId {
+			continue
+		}
+		for _, id := range ids {
+			product := s.shipments.data.products[id]
+			if product.OverdueAt != nil && inStock(product) {
+				products = append(products, product)
+			}
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].DateTime.Equal(products[j].DateTime) {
+			return products[i].Id < products[j].Id
+		}
+		return products[i].DateTime.Before(products[j].DateTime)
+	})
+	return products, nil
+}
+
+func (s *ShipmentRepo) CreateShipment(ctx context.Context, pvzId int, productIds []int) (domain.ReturnShipment, error) {
+	defer s.shipments.lock(ctx)()
+
+	for _, id := range productIds {
+		product, ok := s.shipments.data.products[id]
+		if !ok || !inStock(product) {
+			return domain.ReturnShipment{}, repository.ErrProductNotInStock
+		}
+	}
+
+	shipment := domain.ReturnShipment{Id: s.shipments.nextId("return_shipments"), PvzId: pvzId, CreatedAt: time.Now()}
+	event := domain.ShipmentEvent{ShipmentId: shipment.Id, ProductIds: productIds}
+	if err := s.shipments.addEvent(domain.EventShipmentCreated, pvzId, event); err != nil {
+		return domain.ReturnShipment{}, err
+	}
+	for _, id := range productIds {
+		product := s.shipments.data.products[id]
+		shippedAt := shipment.CreatedAt
+		product.ShippedAt = &shippedAt
+		put(s.shipments, s.shipments.data.products, id, product)
+	}
+	put(s.shipments, s.shipments.data.shipments, shipment.Id, shipment)
+	put(s.shipments, s.shipments.data.shipmentProducts, shipment.Id, append([]int(nil), productIds...))
+	return shipment, nil
+}
+
+func (s *ShipmentRepo) GetShipment(ctx context.Context, shipmentId int) (domain.ReturnShipmentWithProducts, error) {
+	defer s.shipments.lock(ctx)()
+
+	shipment, ok := s.shipments.data.shipments[shipmentId]
+	if !ok {
+		return domain.ReturnShipmentWithProducts{}, repository.NotFound
+	}
+
+	products := make([]domain.Product, 0)
+	for _, id := range s.shipments.data.shipmentProducts[shipmentId] {
+		products = append(products, s.shipments.data.products[id])
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].Id < products[j].Id })
+	return domain.ReturnShipmentWithProducts{Shipment: shipment, Products: products}, nil
+}
+
+// inStock reports whether the product has neither been issued nor shipped back.
+func inStock(product domain.Product) bool {
+	return product.IssuedAt == nil && product.ShippedAt == nil
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"context"
+	"sync"
+)
+
+type state struct {
+	users             map[int]domain.User
+	pvz               map[int]domain.Pvz
+	receptions        map[int]domain.Reception
+	products          map[int]domain.Product
+	receptionProducts map[int][]int
+	removals          []domain.ProductRemoval
+	cities            map[int]domain.City
+	productTypes      map[string]domain.ProductType
+	orders            map[int]domain.Order
+	orderProducts     map[int][]int
+	shipments         map[int]domain.ReturnShipment
+	shipmentProducts  map[int][]int
+	outbox            []outboxRecord
+	outboxDeliveries  map[outboxDeliveryKey]domain.OutboxDelivery
+	outboxLeases      map[string]outboxLease
+	webhooks          map[int]domain.Webhook
+	deliveries        map[int]domain.WebhookDelivery
+	audit             []domain.AuditEntry
+}
+
+func newState() *state {
+	return &state{
+		users:             make(map[int]domain.User),
+		pvz:               make(map[int]domain.Pvz),
+		receptions:        make(map[int]domain.Reception),
+		products:          make(map[int]domain.Product),
+		receptionProducts: make(map[int][]int),
+		cities:            make(map[int]domain.City),
+		productTypes:      make(map[string]domain.ProductType),
+		orders:            make(map[int]domain.Order),
+		orderProducts:     make(map[int][]int),
+		shipments:         make(map[int]domain.ReturnShipment),
+		shipmentProducts:  make(map[int][]int),
+		outboxDeliveries:  make(map[outboxDeliveryKey]domain.OutboxDelivery),
+		outboxLeases:      make(map[string]outboxLease),
+		webhooks:          make(map[int]domain.Webhook),
+		deliveries:        make(map[int]domain.WebhookDelivery),
+	}
+}
+
+// Storage keeps all tables behind a single mutex. Like Postgres sequences,
+// id counters are not rolled back together with a failed transaction.
+type Storage struct {
+	mu   sync.Mutex
+	data *state
+	seq  map[string]int
+	// undo reverts the changes of the running transaction, newest last;
+	// it is nil outside of transactions.
+	undo []func()
+}
+
+// NewStorage starts with the same cities and product types the SQL migrations seed.
+func NewStorage() *Storage {
+	s := &Storage{data: newState(), seq: make(map[string]int)}
+	for _, name := range []string{"Москва", "Санкт-Петербург", "Казань"} {
+		city := domain.City{Id: s.nextId("cities"), Name: name, Enabled: true}
+		s.data.cities[city.Id] = city
+	}
+	for _, productType := range []domain.ProductType{
+		{Code: "electronics", Name: "электроника", StorageDays: domain.DefaultStorageDays},
+		{Code: "clothes", Name: "одежда", StorageDays: domain.DefaultStorageDays},
+		{Code: "shoes", Name: "обувь", StorageDays: domain.DefaultStorageDays},
+	} {
+		s.data.productTypes[productType.Code] = productType
+	}
+	return s
+}
+
+type txKey struct{}
+
+// lock acquires the storage mutex unless ctx already belongs to a transaction
+// started by TxManager.Do on the same storage.
+func (s *Storage) lock(ctx context.Context) func() {
+	if owner, ok := ctx.Value(txKey{}).(*Storage); ok && owner == s {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Storage) nextId(table string) int {
+	s.seq[table]++
+	return s.seq[table]
+}
+
+// put sets m[key] and, inside a transaction, logs how to undo it.
+func put[K comparable, V any](s *Storage, m map[K]V, key K, value V) {
+	if s.undo != nil {
+		old, ok := m[key]
+		s.undo = append(s.undo, func() {
+			if ok {
+				m[key] = old
+			} else {
+				delete(m, key)
+			}
+		})
+	}
+	m[key] = value
+}
+
+// drop deletes m[key] and, inside a transaction, logs how to undo it.
+func drop[K comparable, V any](s *Storage, m map[K]V, key K) {
+	if old, ok := m[key]; ok && s.undo != nil {
+		s.undo = append(s.undo, func() { m[key] = old })
+	}
+	delete(m, key)
+}
+
+// push appends to the slice and, inside a transaction, logs how to undo it.
+func push[T any](s *Storage, slice *[]T, values ...T) {
+	if s.undo != nil {
+		n := len(*slice)
+		s.undo = append(s.undo, func() { *slice = (*slice)[:n] })
+	}
+	*slice = append(*slice, values...)
+}
+
+// putAt sets the i-th element of the slice and, inside a transaction, logs how to undo it.
+func putAt[T any](s *Storage, slice *[]T, i int, value T) {
+	if s.undo != nil {
+		old := (*slice)[i]
+		s.undo = append(s.undo, func() { (*slice)[i] = old })
+	}
+	(*slice)[i] = value
+}
@@ -0,0 +1,33 @@
+package memory
+
+import "context"
+
+type TxManager struct {
+	storage *Storage
+}
+
+func NewTxManager(storage *Storage) *TxManager {
+	return &TxManager{storage: storage}
+}
+
+// Do holds the storage lock for the whole unit of work. The changes fn makes
+// are logged as they happen and undone, newest first, if fn fails.
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if owner, ok := ctx.Value(txKey{}).(*Storage); ok && owner == m.storage {
+		return fn(ctx)
+	}
+
+	m.storage.mu.Lock()
+	defer m.storage.mu.Unlock()
+
+	m.storage.undo = []func(){}
+	defer func() { m.storage.undo = nil }()
+
+	if err := fn(context.WithValue(ctx, txKey{}, m.storage)); err != nil {
+		for i := len(m.storage.undo) - 1; i >= 0; i-- {
+			m.storage.undo[i]()
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+)
+
+type UserRepo struct {
+	users *Storage
+}
+
+func NewUserRepo(users *Storage) *UserRepo {
+	return &UserRepo{users: users}
+}
+
+func (u *UserRepo) Register(ctx context.Context, email string, password string, role string) (domain.User, error) {
+	defer u.users.lock(ctx)()
+
+	for _, user := range u.users.data.users {
+		if user.Email == email {
+			return domain.User{}, repository.ErrEmailAlreadyExists
+		}
+	}
+
+	user := domain.User{Id: u.users.nextId("users"), Email: email, Password: password, Role: role}
+	put(u.users, u.users.data.users, user.Id, user)
+	return user, nil
+}
+
+func (u *UserRepo) Login(ctx context.Context, email string) (domain.User, error) {
+	defer u.users.lock(ctx)()
+
+	for _, user := range u.users.data.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return domain.User{}, repository.NotFound
+}
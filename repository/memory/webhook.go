@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"slices"
+	"sort"
+	"time"
+)
+
+type WebhookRepo struct {
+	webhooks *Storage
+}
+
+func NewWebhookRepo(webhooks *Storage) *WebhookRepo {
+	return &WebhookRepo{webhooks: webhooks}
+}
+
+func (w *WebhookRepo) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	defer w.webhooks.lock(ctx)()
+
+	if _, ok := w.webhooks.data.pvz[webhook.PvzId]; webhook.PvzId != 0 && !ok {
+		return domain.Webhook{}, repository.NotFound
+	}
+
+	webhook.Id = w.webhooks.nextId("webhooks")
+	webhook.EventTypes = append([]string{}, webhook.EventTypes...)
+	webhook.CreatedAt = time.Now()
+	put(w.webhooks, w.webhooks.data.webhooks, webhook.Id, webhook)
+	return webhook, nil
+}
+
+func (w *WebhookRepo) GetWebhook(ctx context.Context, webhookId int) (domain.Webhook, error) {
+	defer w.webhooks.lock(ctx)()
+
+	webhook, ok := w.webhooks.data.webhooks[webhookId]
+	if !ok {
+		return domain.Webhook{}, repository.NotFound
+	}
+	return webhook, nil
+}
+
+func (w *WebhookRepo) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	defer w.webhooks.lock(ctx)()
+
+	webhooks := make([]domain.Webhook, 0, len(w.webhooks.data.webhooks))
+	for _, webhook := range w.webhooks.data.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].Id < webhooks[j].Id })
+	return webhooks, nil
+}
+
+func (w *WebhookRepo) DeleteWebhook(ctx context.Context, webhookId int) error {
+	defer w.webhooks.lock(ctx)()
+
+	if _, ok := w.webhooks.data.webhooks[webhookId]; !ok {
+		return repository.NotFound
+	}
+	drop(w.webhooks, w.webhooks.data.webhooks, webhookId)
+	for id, delivery := range w.webhooks.data.deliveries {
+		if delivery.WebhookId == webhookId {
+			drop(w.webhooks, w.webhooks.data.deliveries, id)
+		}
+	}
+	return nil
+}
+
+func (w *WebhookRepo) AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	defer w.webhooks.lock(ctx)()
+
+	if _, ok := w.webhooks.data.webhooks[delivery.WebhookId]; !ok {
+		return repository.NotFound
+	}
+	for _, queued := range w.webhooks.data.deliveries {
+		if queued.WebhookId == delivery.WebhookId && queued.EventId == delivery.EventId {
+			return nil
+		}
+	}
+
+	delivery.Id = w.webhooks.nextId("webhook_deliveries")
+	put(w.webhooks, w.webhooks.data.deliveries, delivery.Id, delivery)
+	return nil
+}
+
+func (w *WebhookRepo) GetDelivery(ctx context.Context, deliveryId int) (domain.WebhookDelivery, error) {
+	defer w.webhooks.lock(ctx)()
+
+	delivery, ok := w.webhooks.data.deliveries[deliveryId]
+	if !ok {
+		return domain.WebhookDelivery{}, repository.NotFound
+	}
+	return delivery, nil
+}
+
+func (w *WebhookRepo) ListDeliveries(ctx context.Context, webhookId int, limit int) ([]domain.WebhookDelivery, error) {
+	defer w.webhooks.lock(ctx)()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for _, delivery := range w.webhooks.data.deliveries {
+		if delivery.WebhookId == webhookId {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Id > deliveries[j].Id })
+	return deliveries[:min(limit, len(deliveries))], nil
+}
+
+func (w *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	defer w.webhooks.lock(ctx)()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for _, delivery := range w.webhooks.data.deliveries {
+		if delivery.Status == domain.DeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b domain.WebhookDelivery) int {
+		if c := a.NextAttemptAt.Compare(b.NextAttemptAt); c != 0 {
+			return c
+		}
+		return a.Id - b.Id
+	})
+	deliveries = deliveries[:min(limit, len(deliveries))]
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Id < deliveries[j].Id })
+	for i := range deliveries {
+		deliveries[i].NextAttemptAt = until
+		put(w.webhooks, w.webhooks.data.deliveries, deliveries[i].Id, deliveries[i])
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	defer w.webhooks.lock(ctx)()
+
+	stored, ok := w.webhooks.data.deliveries[delivery.Id]
+	if !ok {
+		return repository.NotFound
+	}
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastError = delivery.LastError
+	stored.ResponseStatus = delivery.ResponseStatus
+	stored.DeliveredAt = delivery.DeliveredAt
+	put(w.webhooks, w.webhooks.data.deliveries, delivery.Id, stored)
+	return nil
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type Audit struct {
+	mock.Mock
+}
+
+func (m *Audit) AddEntry(ctx context.Context, entry domain.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *Audit) ListEntries(ctx context.Context, filter repository.AuditFilter) ([]domain.AuditEntry, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+func (m *Audit) CountEntries(ctx context.Context, filter repository.AuditFilter) (int, error) {
+	args := m.Called(filter)
+	return args.Int(0), args.Error(1)
+}
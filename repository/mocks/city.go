@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type City struct {
+	mock.Mock
+}
+
+func (m *City) CreateCity(ctx context.Context, name string) (domain.City, error) {
+	args := m.Called(name)
+	return args.Get(0).(domain.City), args.Error(1)
+}
+
+func (m *City) GetCity(ctx context.Context, cityId int) (domain.City, error) {
+	args := m.Called(cityId)
+	return args.Get(0).(domain.City), args.Error(1)
+}
+
+func (m *City) GetCityByName(ctx context.Context, name string) (domain.City, error) {
+	args := m.Called(name)
+	return args.Get(0).(domain.City), args.Error(1)
+}
+
+func (m *City) ListCities(ctx context.Context) ([]domain.City, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.City), args.Error(1)
+}
+
+func (m *City) UpdateCity(ctx context.Context, city domain.City) (domain.City, error) {
+	args := m.Called(city)
+	return args.Get(0).(domain.City), args.Error(1)
+}
+
+func (m *City) DeleteCity(ctx context.Context, cityId int) error {
+	args := m.Called(cityId)
+	return args.Error(0)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type Order struct {
+	mock.Mock
+}
+
+func (m *Order) ListOrderableProducts(ctx context.Context, pvzId int, orderNumber string) ([]domain.Product, error) {
+	args := m.Called(pvzId, orderNumber)
+	return args.Get(0).([]domain.Product), args.Error(1)
+}
+
+func (m *Order) CreateOrder(ctx context.Context, order domain.Order, productIds []int) (domain.Order, error) {
+	args := m.Called(order, productIds)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
+
+func (m *Order) GetOrder(ctx context.Context, orderId int) (domain.OrderWithProducts, error) {
+	args := m.Called(orderId)
+	return args.Get(0).(domain.OrderWithProducts), args.Error(1)
+}
+
+func (m *Order) ListOrders(ctx context.Context, pvzId int, status string) ([]domain.Order, error) {
+	args := m.Called(pvzId, status)
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *Order) UpdateOrderStatus(ctx context.Context, orderId int, from string, status string) (domain.Order, error) {
+	args := m.Called(orderId, from, status)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
+
+func (m *Order) IssueOrderProducts(ctx context.Context, orderId int) error {
+	args := m.Called(orderId)
+	return args.Error(0)
+}
+
+func (m *Order) ReturnOrdersOfProducts(ctx context.Context, productIds []int) error {
+	args := m.Called(productIds)
+	return args.Error(0)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type Outbox struct {
+	mock.Mock
+}
+
+func (m *Outbox) Lease(ctx context.Context, name string, owner string, now time.Time, until time.Time) (bool, error) {
+	args := m.Called(name, owner, now, until)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *Outbox) ListPending(ctx context.Context, limit int) ([]domain.Event, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]domain.Event), args.Error(1)
+}
+
+func (m *Outbox) ListEvents(ctx context.Context, pvzId int, afterId int, limit int) ([]domain.Event, error) {
+	args := m.Called(pvzId, afterId, limit)
+	return args.Get(0).([]domain.Event), args.Error(1)
+}
+
+func (m *Outbox) Dispatch(ctx context.Context, eventId int, consumers []string) error {
+	args := m.Called(eventId, consumers)
+	return args.Error(0)
+}
+
+func (m *Outbox) ListQueued(ctx context.Context, consumer string, limit int) ([]domain.OutboxDelivery, error) {
+	args := m.Called(consumer, limit)
+	return args.Get(0).([]domain.OutboxDelivery), args.Error(1)
+}
+
+func (m *Outbox) UpdateDelivery(ctx context.Context, delivery domain.OutboxDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"avito_test/domain"
+	"avito_test/usecases"
+	"context"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -9,27 +11,47 @@ type Reception struct {
 	mock.Mock
 }
 
-func (m *Reception) StartReception(pvzId int) (domain.Reception, error) {
-	args := m.Called(pvzId)
+func (m *Reception) StartReception(ctx context.Context, pvzId int, kind string) (domain.Reception, error) {
+	args := m.Called(pvzId, kind)
 	return args.Get(0).(domain.Reception), args.Error(1)
 }
 
-func (m *Reception) CloseReception(pvzId int) (domain.Reception, error) {
+func (m *Reception) CloseReception(ctx context.Context, pvzId int) (domain.Reception, error) {
 	args := m.Called(pvzId)
 	return args.Get(0).(domain.Reception), args.Error(1)
 }
 
-func (m *Reception) GetLastReception(pvzId int) (domain.Reception, error) {
+func (m *Reception) GetLastReception(ctx context.Context, pvzId int) (domain.Reception, error) {
 	args := m.Called(pvzId)
 	return args.Get(0).(domain.Reception), args.Error(1)
 }
 
-func (m *Reception) AddProduct(receptionId int, productId int) error {
+func (m *Reception) AddProduct(ctx context.Context, receptionId int, productId int) error {
 	args := m.Called(receptionId, productId)
 	return args.Error(0)
 }
 
-func (m *Reception) DeleteProduct(pvzId int) (string, error) {
-	args := m.Called(pvzId)
+func (m *Reception) DeleteProduct(ctx context.Context, pvzId int, productId int, reason string) (string, error) {
+	args := m.Called(pvzId, productId, reason)
 	return args.String(0), args.Error(1)
 }
+
+func (m *Reception) ListReceptions(ctx context.Context, pvzId int, filter usecases.ReceptionFilter) ([]domain.Reception, error) {
+	args := m.Called(pvzId, filter)
+	return args.Get(0).([]domain.Reception), args.Error(1)
+}
+
+func (m *Reception) GetReception(ctx context.Context, receptionId int) (domain.ReceptionWithProducts, error) {
+	args := m.Called(receptionId)
+	return args.Get(0).(domain.ReceptionWithProducts), args.Error(1)
+}
+
+func (m *Reception) CountOpenReceptions(ctx context.Context) (map[string]int, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *Reception) ListRemovals(ctx context.Context, receptionId int) ([]domain.ProductRemoval, error) {
+	args := m.Called(receptionId)
+	return args.Get(0).([]domain.ProductRemoval), args.Error(1)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"avito_test/domain"
+	"context"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -9,12 +10,22 @@ type Product struct {
 	mock.Mock
 }
 
-func (m *Product) AddProduct(productType string) (domain.Product, error) {
-	args := m.Called(productType)
+func (m *Product) AddProduct(ctx context.Context, product domain.Product) (domain.Product, error) {
+	args := m.Called(product)
 	return args.Get(0).(domain.Product), args.Error(1)
 }
 
-func (m *Product) DeleteProduct(productId int) error {
+func (m *Product) GetProduct(ctx context.Context, productId int) (domain.Product, error) {
+	args := m.Called(productId)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Product) DeleteProduct(ctx context.Context, productId int) error {
 	args := m.Called(productId)
 	return args.Error(0)
 }
+
+func (m *Product) GetProductByBarcode(ctx context.Context, barcode string) (domain.ProductLocation, error) {
+	args := m.Called(barcode)
+	return args.Get(0).(domain.ProductLocation), args.Error(1)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type ProductType struct {
+	mock.Mock
+}
+
+func (m *ProductType) CreateProductType(ctx context.Context, productType domain.ProductType) (domain.ProductType, error) {
+	args := m.Called(productType)
+	return args.Get(0).(domain.ProductType), args.Error(1)
+}
+
+func (m *ProductType) GetProductType(ctx context.Context, codeOrName string) (domain.ProductType, error) {
+	args := m.Called(codeOrName)
+	return args.Get(0).(domain.ProductType), args.Error(1)
+}
+
+func (m *ProductType) ListProductTypes(ctx context.Context) ([]domain.ProductType, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.ProductType), args.Error(1)
+}
+
+func (m *ProductType) SetStorageDays(ctx context.Context, code string, storageDays int) (domain.ProductType, error) {
+	args := m.Called(code, storageDays)
+	return args.Get(0).(domain.ProductType), args.Error(1)
+}
@@ -2,7 +2,9 @@ package mocks
 
 import (
 	"avito_test/domain"
+	"avito_test/repository"
 	"avito_test/usecases"
+	"context"
 	"github.com/stretchr/testify/mock"
 	"time"
 )
@@ -11,17 +13,32 @@ type Pvz struct {
 	mock.Mock
 }
 
-func (m *Pvz) OpenPvz(city string) (domain.Pvz, error) {
+func (m *Pvz) OpenPvz(ctx context.Context, city string) (domain.Pvz, error) {
 	args := m.Called(city)
 	return args.Get(0).(domain.Pvz), args.Error(1)
 }
 
-func (m *Pvz) GetPvz(pvzId int) (domain.Pvz, error) {
+func (m *Pvz) GetPvz(ctx context.Context, pvzId int) (domain.Pvz, error) {
 	args := m.Called(pvzId)
 	return args.Get(0).(domain.Pvz), args.Error(1)
 }
 
-func (m *Pvz) GetPvzListWithFilter(startDate, endDate *time.Time, offset, limit int) ([]usecases.PvzWithReceptions, error) {
-	return []usecases.PvzWithReceptions{}, nil
+func (m *Pvz) SetCapacity(ctx context.Context, pvzId int, capacity int, typeCapacity map[string]int) (domain.Pvz, error) {
+	args := m.Called(pvzId, capacity, typeCapacity)
+	return args.Get(0).(domain.Pvz), args.Error(1)
+}
+
+func (m *Pvz) GetOccupancy(ctx context.Context, pvzId int) (domain.Occupancy, error) {
+	args := m.Called(pvzId)
+	return args.Get(0).(domain.Occupancy), args.Error(1)
+}
+
+func (m *Pvz) GetPvzListWithFilter(ctx context.Context, filter repository.PvzListFilter) ([]usecases.PvzWithReceptions, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]usecases.PvzWithReceptions), args.Error(1)
+}
 
+func (m *Pvz) CountPvzWithFilter(ctx context.Context, startDate, endDate *time.Time) (int, error) {
+	args := m.Called(startDate, endDate)
+	return args.Int(0), args.Error(1)
 }
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type Shipment struct {
+	mock.Mock
+}
+
+func (m *Shipment) MarkOverdue(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *Shipment) ListOverdueProducts(ctx context.Context, pvzId int) ([]domain.Product, error) {
+	args := m.Called(pvzId)
+	return args.Get(0).([]domain.Product), args.Error(1)
+}
+
+func (m *Shipment) CreateShipment(ctx context.Context, pvzId int, productIds []int) (domain.ReturnShipment, error) {
+	args := m.Called(pvzId, productIds)
+	return args.Get(0).(domain.ReturnShipment), args.Error(1)
+}
+
+func (m *Shipment) GetShipment(ctx context.Context, shipmentId int) (domain.ReturnShipmentWithProducts, error) {
+	args := m.Called(shipmentId)
+	return args.Get(0).(domain.ReturnShipmentWithProducts), args.Error(1)
+}
@@ -0,0 +1,10 @@
+package mocks
+
+import "context"
+
+// TxManager runs the unit of work directly; repository mocks have no transactional state.
+type TxManager struct{}
+
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
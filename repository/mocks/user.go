@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"avito_test/domain"
+	"context"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -9,12 +10,12 @@ type User struct {
 	mock.Mock
 }
 
-func (m *User) Register(email string, password string, role string) (domain.User, error) {
+func (m *User) Register(ctx context.Context, email string, password string, role string) (domain.User, error) {
 	args := m.Called(email, password, role)
 	return args.Get(0).(domain.User), args.Error(1)
 }
 
-func (m *User) Login(email string) (domain.User, error) {
+func (m *User) Login(ctx context.Context, email string) (domain.User, error) {
 	args := m.Called(email)
 	return args.Get(0).(domain.User), args.Error(1)
 }
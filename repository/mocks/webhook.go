@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type Webhook struct {
+	mock.Mock
+}
+
+func (m *Webhook) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *Webhook) GetWebhook(ctx context.Context, webhookId int) (domain.Webhook, error) {
+	args := m.Called(webhookId)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *Webhook) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *Webhook) DeleteWebhook(ctx context.Context, webhookId int) error {
+	args := m.Called(webhookId)
+	return args.Error(0)
+}
+
+func (m *Webhook) AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *Webhook) GetDelivery(ctx context.Context, deliveryId int) (domain.WebhookDelivery, error) {
+	args := m.Called(deliveryId)
+	return args.Get(0).(domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *Webhook) ListDeliveries(ctx context.Context, webhookId int, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(webhookId, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *Webhook) ClaimDueDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(now, until, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *Webhook) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
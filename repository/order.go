@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+type Order interface {
+	// ListOrderableProducts returns the stock of the PVZ that carries the order number,
+	// arrived with a closed reception and does not belong to an open order.
+	ListOrderableProducts(ctx context.Context, pvzId int, orderNumber string) ([]domain.Product, error)
+	// CreateOrder fails with ErrOrderAlreadyExists while an open order of the PVZ holds the
+	// order number or one of the products.
+	CreateOrder(ctx context.Context, order domain.Order, productIds []int) (domain.Order, error)
+	GetOrder(ctx context.Context, orderId int) (domain.OrderWithProducts, error)
+	// ListOrders returns the orders of the PVZ newest first; an empty status matches all.
+	ListOrders(ctx context.Context, pvzId int, status string) ([]domain.Order, error)
+	// UpdateOrderStatus moves the order to status only while it is still in from; a final
+	// status frees the order number and the products for a new order.
+	UpdateOrderStatus(ctx context.Context, orderId int, from string, status string) (domain.Order, error)
+	// IssueOrderProducts marks the products of the order as handed out, which takes them out of stock.
+	IssueOrderProducts(ctx context.Context, orderId int) error
+	// ReturnOrdersOfProducts moves the accepted and ready_for_pickup orders holding any
+	// of the products to returned.
+	ReturnOrdersOfProducts(ctx context.Context, productIds []int) error
+}
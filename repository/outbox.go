@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"context"
+	"time"
+)
+
+// Outbox reads back the events the other repositories record with their changes
+// and keeps a queue of them for every consumer.
+type Outbox interface {
+	// Lease takes the named lease for owner, or extends it when owner holds it already,
+	// until the given time. It reports false while another owner holds it past now.
+	Lease(ctx context.Context, name string, owner string, now time.Time, until time.Time) (bool, error)
+	// ListPending returns up to limit events that are not dispatched yet, oldest first.
+	ListPending(ctx context.Context, limit int) ([]domain.Event, error)
+	// ListEvents returns up to limit events of the PVZ recorded after afterId, oldest first,
+	// whether dispatched or not.
+	ListEvents(ctx context.Context, pvzId int, afterId int, limit int) ([]domain.Event, error)
+	// Dispatch queues the event for every consumer and takes it off the pending list.
+	// Consumers that have it queued already keep their delivery.
+	Dispatch(ctx context.Context, eventId int, consumers []string) error
+	// ListQueued returns up to limit pending deliveries of the consumer, oldest event first.
+	ListQueued(ctx context.Context, consumer string, limit int) ([]domain.OutboxDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery domain.OutboxDelivery) error
+}
@@ -0,0 +1,115 @@
+package postgreSQL
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+type AuditRepo struct {
+	audit *postgres_connect.PostgresStorage
+}
+
+func NewAuditRepo(audit *postgres_connect.PostgresStorage) *AuditRepo {
+	return &AuditRepo{audit: audit}
+}
+
+func (a *AuditRepo) AddEntry(ctx context.Context, entry domain.AuditEntry) error {
+	targetIds := []byte("{}")
+	if len(entry.TargetIds) > 0 {
+		var err error
+		if targetIds, err = json.Marshal(entry.TargetIds); err != nil {
+			return err
+		}
+	}
+	var payload *string
+	if len(entry.Payload) > 0 {
+		s := string(entry.Payload)
+		payload = &s
+	}
+	// A target that is not a number cannot name a PVZ and is only kept in target_ids.
+	pvzId, _ := strconv.Atoi(entry.TargetIds[domain.AuditTargetPvz])
+
+	_, err := executor(ctx, a.audit).ExecContext(ctx, `
+		INSERT INTO audit_log (actor_id, role, action, target_ids, pvz_id, payload, status, ip, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, $7, $8, $9)`,
+		entry.ActorId, entry.Role, entry.Action, string(targetIds), pvzId, payload, entry.Status, entry.IP,
+		entry.CreatedAt)
+	return err
+}
+
+func (a *AuditRepo) ListEntries(ctx context.Context, filter repository.AuditFilter) ([]domain.AuditEntry, error) {
+	cond, args := auditCondition(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := `
+		SELECT id, actor_id, role, action, target_ids, payload, status, ip, created_at
+		FROM audit_log WHERE TRUE` + cond + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+
+	rows, err := executor(ctx, a.audit).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0)
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var targetIds, payload []byte
+		if err := rows.Scan(&entry.Id, &entry.ActorId, &entry.Role, &entry.Action, &targetIds, &payload,
+			&entry.Status, &entry.IP, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(targetIds, &entry.TargetIds); err != nil {
+			return nil, err
+		}
+		if len(entry.TargetIds) == 0 {
+			entry.TargetIds = nil
+		}
+		if payload != nil {
+			entry.Payload = payload
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (a *AuditRepo) CountEntries(ctx context.Context, filter repository.AuditFilter) (int, error) {
+	cond, args := auditCondition(filter)
+	var count int
+	err := executor(ctx, a.audit).QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log WHERE TRUE`+cond, args...).
+		Scan(&count)
+	return count, err
+}
+
+func auditCondition(filter repository.AuditFilter) (string, []interface{}) {
+	var cond string
+	var args []interface{}
+	add := func(column string, value interface{}) {
+		args = append(args, value)
+		cond += " AND " + column + " $" + strconv.Itoa(len(args))
+	}
+	if filter.ActorId != "" {
+		add("actor_id =", filter.ActorId)
+	}
+	if filter.Role != "" {
+		add("role =", filter.Role)
+	}
+	if filter.Action != "" {
+		add("action =", filter.Action)
+	}
+	if filter.PvzId != 0 {
+		add("pvz_id =", filter.PvzId)
+	}
+	if filter.From != nil {
+		add("created_at >=", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at <=", *filter.To)
+	}
+	return cond, args
+}
@@ -0,0 +1,20 @@
+package postgreSQL
+
+import (
+	"errors"
+	"github.com/lib/pq"
+)
+
+const (
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+	foreignKeyViolation = "23503"
+)
+
+func pgErrorCode(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
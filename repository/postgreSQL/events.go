@@ -0,0 +1,55 @@
+package postgreSQL
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/logger"
+	"avito_test/pkg/postgres_connect"
+	"context"
+	"encoding/json"
+	"github.com/lib/pq"
+	"time"
+)
+
+type EventListener struct {
+	events *postgres_connect.PostgresStorage
+}
+
+func NewEventListener(events *postgres_connect.PostgresStorage) *EventListener {
+	return &EventListener{events: events}
+}
+
+func (l *EventListener) Listen(ctx context.Context, handle func(domain.Event)) error {
+	listener := pq.NewListener(l.events.ConnStr, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.FromContext(ctx).Warn("event listener", "error", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(eventsChannel); err != nil {
+		return err
+	}
+
+	ping := time.NewTicker(time.Minute)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			// A nil notification means the connection was re-established.
+			if notification == nil {
+				continue
+			}
+			var event domain.Event
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				logger.FromContext(ctx).Warn("event listener: malformed notification", "error", err)
+				continue
+			}
+			handle(event)
+		case <-ping.C:
+			go func() { _ = listener.Ping() }()
+		}
+	}
+}
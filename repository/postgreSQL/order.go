@@ -0,0 +1,210 @@
+package postgreSQL
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+type OrderRepo struct {
+	orders *postgres_connect.PostgresStorage
+}
+
+func NewOrderRepo(orders *postgres_connect.PostgresStorage) *OrderRepo {
+	return &OrderRepo{orders: orders}
+}
+
+func (o *OrderRepo) ListOrderableProducts(ctx context.Context, pvzId int, orderNumber string) ([]domain.Product, error) {
+	return o.queryProducts(ctx, `
+		SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), p.order_number, p.issued_at
+		FROM products p
+		JOIN product_types pt ON pt.code = p.type
+		JOIN reception_products rp ON rp.product_id = p.id
+		JOIN receptions r ON r.id = rp.reception_id
+		WHERE r.pvz_id = $1 AND r.kind = 'delivery' AND r.status = 'closed' AND p.order_number = $2 AND p.issued_at IS NULL AND p.shipped_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM order_items oi WHERE oi.product_id = p.id AND oi.active)
+		ORDER BY p.id`, pvzId, orderNumber)
+}
+
+func (o *OrderRepo) CreateOrder(ctx context.Context, order domain.Order, productIds []int) (_ domain.Order, err error) {
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+	err = inTx(ctx, o.orders, func(ctx context.Context) error {
+		err := executor(ctx, o.orders).QueryRowContext(ctx, `
+			INSERT INTO orders (pvz_id, order_number, status, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4) RETURNING id`,
+			order.PvzId, order.OrderNumber, order.Status, order.CreatedAt,
+		).Scan(&order.Id)
+		if pgErrorCode(err) == uniqueViolation {
+			return repository.ErrOrderAlreadyExists
+		} else if err != nil {
+			return err
+		}
+
+		for _, productId := range productIds {
+			_, err := executor(ctx, o.orders).ExecContext(ctx,
+				`INSERT INTO order_items (order_id, product_id) VALUES ($1, $2)`, order.Id, productId)
+			if pgErrorCode(err) == uniqueViolation {
+				return repository.ErrOrderAlreadyExists
+			} else if err != nil {
+				return err
+			}
+		}
+		return addEvent(ctx, o.orders, domain.EventOrderCreated, order.PvzId, order)
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return order, nil
+}
+
+func (o *OrderRepo) GetOrder(ctx context.Context, orderId int) (domain.OrderWithProducts, error) {
+	var result domain.OrderWithProducts
+	err := executor(ctx, o.orders).QueryRowContext(ctx, `
+		SELECT id, pvz_id, order_number, status, created_at, updated_at FROM orders
+		WHERE id = $1`, orderId).
+		Scan(&result.Order.Id, &result.Order.PvzId, &result.Order.OrderNumber, &result.Order.Status,
+			&result.Order.CreatedAt, &result.Order.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.OrderWithProducts{}, repository.NotFound
+	} else if err != nil {
+		return domain.OrderWithProducts{}, err
+	}
+
+	result.Products, err = o.queryProducts(ctx, `
+		SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), COALESCE(p.order_number, ''), p.issued_at
+		FROM products p
+		JOIN product_types pt ON pt.code = p.type
+		JOIN order_items oi ON oi.product_id = p.id
+		WHERE oi.order_id = $1
+		ORDER BY p.id`, orderId)
+	if err != nil {
+		return domain.OrderWithProducts{}, err
+	}
+	return result, nil
+}
+
+func (o *OrderRepo) ListOrders(ctx context.Context, pvzId int, status string) ([]domain.Order, error) {
+	query := `SELECT id, pvz_id, order_number, status, created_at, updated_at FROM orders WHERE pvz_id = $1`
+	args := []interface{}{pvzId}
+
+	if status != "" {
+		args = append(args, status)
+		query += " AND status = $" + strconv.Itoa(len(args))
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := executor(ctx, o.orders).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanOrders(rows)
+}
+
+func (o *OrderRepo) UpdateOrderStatus(ctx context.Context, orderId int, from string, status string) (order domain.Order, err error) {
+	err = inTx(ctx, o.orders, func(ctx context.Context) error {
+		err := executor(ctx, o.orders).QueryRowContext(ctx, `
+			UPDATE orders SET status = $1, updated_at = $2
+			WHERE id = $3 AND status = $4
+			RETURNING id, pvz_id, order_number, status, created_at, updated_at`,
+			status, time.Now(), orderId, from,
+		).Scan(&order.Id, &order.PvzId, &order.OrderNumber, &order.Status, &order.CreatedAt, &order.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrOrderStatusChanged
+		} else if err != nil {
+			return err
+		}
+
+		if !domain.IsOrderOpen(status) {
+			_, err = executor(ctx, o.orders).ExecContext(ctx, `UPDATE order_items SET active = FALSE WHERE order_id = $1`, orderId)
+			if err != nil {
+				return err
+			}
+		}
+		return addEvent(ctx, o.orders, domain.EventOrderStatusChanged, order.PvzId, order)
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return order, nil
+}
+
+func (o *OrderRepo) IssueOrderProducts(ctx context.Context, orderId int) error {
+	_, err := executor(ctx, o.orders).ExecContext(ctx, `
+		UPDATE products SET issued_at = $1
+		WHERE id IN (SELECT product_id FROM order_items WHERE order_id = $2)`,
+		time.Now(), orderId)
+	return err
+}
+
+// ReturnOrdersOfProducts records an order.status_changed event for every order it returns.
+func (o *OrderRepo) ReturnOrdersOfProducts(ctx context.Context, productIds []int) error {
+	return inTx(ctx, o.orders, func(ctx context.Context) error {
+		rows, err := executor(ctx, o.orders).QueryContext(ctx, `
+			WITH returned AS (
+				UPDATE orders SET status = $1, updated_at = $2
+				WHERE status IN ($3, $4)
+				  AND id IN (SELECT order_id FROM order_items WHERE product_id = ANY($5))
+				RETURNING id, pvz_id, order_number, status, created_at, updated_at
+			), deactivated AS (
+				UPDATE order_items SET active = FALSE WHERE order_id IN (SELECT id FROM returned)
+			)
+			SELECT id, pvz_id, order_number, status, created_at, updated_at FROM returned ORDER BY id`,
+			domain.OrderStatusReturned, time.Now(), domain.OrderStatusAccepted, domain.OrderStatusReadyForPickup, pq.Array(productIds))
+		if err != nil {
+			return err
+		}
+		returned, err := scanOrders(rows)
+		if err != nil {
+			return err
+		}
+
+		for _, order := range returned {
+			if err := addEvent(ctx, o.orders, domain.EventOrderStatusChanged, order.PvzId, order); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// scanOrders reads and closes the rows.
+func scanOrders(rows *sql.Rows) ([]domain.Order, error) {
+	defer rows.Close()
+
+	orders := make([]domain.Order, 0)
+	for rows.Next() {
+		var order domain.Order
+		if err := rows.Scan(&order.Id, &order.PvzId, &order.OrderNumber, &order.Status, &order.CreatedAt, &order.UpdatedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+func (o *OrderRepo) queryProducts(ctx context.Context, query string, args ...interface{}) ([]domain.Product, error) {
+	rows, err := executor(ctx, o.orders).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]domain.Product, 0)
+	for rows.Next() {
+		var product domain.Product
+		err := rows.Scan(&product.Id, &product.Type, &product.TypeName, &product.DateTime,
+			&product.Barcode, &product.OrderNumber, &product.IssuedAt)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
@@ -0,0 +1,165 @@
+package postgreSQL
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+type OutboxRepo struct {
+	outbox *postgres_connect.PostgresStorage
+}
+
+func NewOutboxRepo(outbox *postgres_connect.PostgresStorage) *OutboxRepo {
+	return &OutboxRepo{outbox: outbox}
+}
+
+func (o *OutboxRepo) Lease(ctx context.Context, name string, owner string, now time.Time, until time.Time) (bool, error) {
+	err := executor(ctx, o.outbox).QueryRowContext(ctx, `
+		INSERT INTO outbox_leases (name, owner, leased_until) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET owner = EXCLUDED.owner, leased_until = EXCLUDED.leased_until
+		WHERE outbox_leases.owner = EXCLUDED.owner OR outbox_leases.leased_until <= $4
+		RETURNING name`, name, owner, until, now).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (o *OutboxRepo) ListPending(ctx context.Context, limit int) ([]domain.Event, error) {
+	rows, err := executor(ctx, o.outbox).QueryContext(ctx, `
+		SELECT id, type, pvz_id, payload, created_at
+		FROM outbox
+		WHERE dispatched_at IS NULL
+		ORDER BY id
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (o *OutboxRepo) ListEvents(ctx context.Context, pvzId int, afterId int, limit int) ([]domain.Event, error) {
+	rows, err := executor(ctx, o.outbox).QueryContext(ctx, `
+		SELECT id, type, pvz_id, payload, created_at
+		FROM outbox
+		WHERE pvz_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`, pvzId, afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+// scanEvents reads and closes the rows.
+func scanEvents(rows *sql.Rows) ([]domain.Event, error) {
+	defer rows.Close()
+
+	events := make([]domain.Event, 0)
+	for rows.Next() {
+		var event domain.Event
+		var payload []byte
+		if err := rows.Scan(&event.Id, &event.Type, &event.PvzId, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (o *OutboxRepo) Dispatch(ctx context.Context, eventId int, consumers []string) error {
+	_, err := executor(ctx, o.outbox).ExecContext(ctx, `
+		WITH dispatched AS (
+			UPDATE outbox SET dispatched_at = $1 WHERE id = $2 RETURNING id
+		)
+		INSERT INTO outbox_deliveries (consumer, event_id, next_attempt_at)
+		SELECT consumer, id, $1 FROM dispatched, unnest($3::text[]) AS consumer
+		ON CONFLICT (consumer, event_id) DO NOTHING`, time.Now(), eventId, pq.Array(consumers))
+	return err
+}
+
+func (o *OutboxRepo) ListQueued(ctx context.Context, consumer string, limit int) ([]domain.OutboxDelivery, error) {
+	rows, err := executor(ctx, o.outbox).QueryContext(ctx, `
+		SELECT d.consumer, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at,
+		       o.id, o.type, o.pvz_id, o.payload, o.created_at
+		FROM outbox_deliveries d
+		JOIN outbox o ON o.id = d.event_id
+		WHERE d.consumer = $1 AND d.status = $2
+		ORDER BY d.event_id
+		LIMIT $3`, consumer, domain.DeliveryStatusPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.OutboxDelivery, 0)
+	for rows.Next() {
+		var delivery domain.OutboxDelivery
+		var payload []byte
+		err := rows.Scan(&delivery.Consumer, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt,
+			&delivery.LastError, &delivery.DeliveredAt, &delivery.Event.Id, &delivery.Event.Type,
+			&delivery.Event.PvzId, &payload, &delivery.Event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Event.Payload = payload
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (o *OutboxRepo) UpdateDelivery(ctx context.Context, delivery domain.OutboxDelivery) error {
+	res, err := executor(ctx, o.outbox).ExecContext(ctx, `
+		UPDATE outbox_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5
+		WHERE consumer = $6 AND event_id = $7`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.DeliveredAt,
+		delivery.Consumer, delivery.Event.Id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return repository.NotFound
+	}
+	return nil
+}
+
+// eventsChannel is the NOTIFY channel every recorded event is announced on.
+const eventsChannel = "pvz_events"
+
+// addEvent records an event in the outbox and announces it on eventsChannel; callers run it
+// in the transaction of the change it describes, so listeners only hear about committed changes.
+func addEvent(ctx context.Context, storage *postgres_connect.PostgresStorage, eventType string, pvzId int, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := domain.Event{Type: eventType, PvzId: pvzId, Payload: raw, CreatedAt: time.Now()}
+	err = executor(ctx, storage).QueryRowContext(ctx,
+		`INSERT INTO outbox (type, pvz_id, payload, created_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+		eventType, pvzId, string(raw), event.CreatedAt,
+	).Scan(&event.Id)
+	if err != nil {
+		return err
+	}
+
+	notification, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = executor(ctx, storage).ExecContext(ctx, `SELECT pg_notify($1, $2)`, eventsChannel, string(notification))
+	return err
+}
@@ -0,0 +1,86 @@
+package postgreSQL
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type ProductTypeRepo struct {
+	productTypes *postgres_connect.PostgresStorage
+}
+
+func NewProductTypeRepo(productTypes *postgres_connect.PostgresStorage) *ProductTypeRepo {
+	return &ProductTypeRepo{productTypes: productTypes}
+}
+
+func (r *ProductTypeRepo) CreateProductType(ctx context.Context, productType domain.ProductType) (domain.ProductType, error) {
+	// A code may not repeat the name of another type, nor a name its code, so that a
+	// lookup by either stays unambiguous.
+	res, err := executor(ctx, r.productTypes).ExecContext(ctx, `
+		INSERT INTO product_types (code, name, storage_days)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (SELECT 1 FROM product_types WHERE code IN ($1, $2) OR name IN ($1, $2))`,
+		productType.Code, productType.Name, productType.StorageDays,
+	)
+	if pgErrorCode(err) == uniqueViolation {
+		return domain.ProductType{}, repository.ErrProductTypeAlreadyExists
+	} else if err != nil {
+		return domain.ProductType{}, err
+	}
+	if inserted, err := res.RowsAffected(); err != nil {
+		return domain.ProductType{}, err
+	} else if inserted == 0 {
+		return domain.ProductType{}, repository.ErrProductTypeAlreadyExists
+	}
+	return productType, nil
+}
+
+func (r *ProductTypeRepo) GetProductType(ctx context.Context, codeOrName string) (domain.ProductType, error) {
+	var productType domain.ProductType
+	err := executor(ctx, r.productTypes).QueryRowContext(ctx,
+		`SELECT code, name, storage_days FROM product_types WHERE code = $1 OR name = $1
+		 ORDER BY code = $1 DESC LIMIT 1`, codeOrName,
+	).Scan(&productType.Code, &productType.Name, &productType.StorageDays)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ProductType{}, repository.NotFound
+	} else if err != nil {
+		return domain.ProductType{}, err
+	}
+	return productType, nil
+}
+
+func (r *ProductTypeRepo) ListProductTypes(ctx context.Context) ([]domain.ProductType, error) {
+	rows, err := executor(ctx, r.productTypes).QueryContext(ctx, `SELECT code, name, storage_days FROM product_types ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	productTypes := make([]domain.ProductType, 0)
+	for rows.Next() {
+		var productType domain.ProductType
+		if err := rows.Scan(&productType.Code, &productType.Name, &productType.StorageDays); err != nil {
+			return nil, err
+		}
+		productTypes = append(productTypes, productType)
+	}
+	return productTypes, rows.Err()
+}
+
+func (r *ProductTypeRepo) SetStorageDays(ctx context.Context, code string, storageDays int) (domain.ProductType, error) {
+	var productType domain.ProductType
+	err := executor(ctx, r.productTypes).QueryRowContext(ctx,
+		`UPDATE product_types SET storage_days = $1 WHERE code = $2 RETURNING code, name, storage_days`,
+		storageDays, code,
+	).Scan(&productType.Code, &productType.Name, &productType.StorageDays)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ProductType{}, repository.NotFound
+	} else if err != nil {
+		return domain.ProductType{}, err
+	}
+	return productType, nil
+}
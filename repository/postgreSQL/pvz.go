@@ -5,6 +5,7 @@ import (
 	"avito_test/pkg/postgres_connect"
 	"avito_test/repository"
 	"avito_test/usecases"
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/lib/pq"
@@ -21,120 +22,229 @@ func NewPvzRepo(pvz *postgres_connect.PostgresStorage) *PvzRepo {
 	return &PvzRepo{pvz: pvz}
 }
 
-func (p *PvzRepo) OpenPvz(city string) (domain.Pvz, error) {
-	now := time.Now()
-	var id int
-	err := p.pvz.Db.QueryRow(
-		`INSERT INTO pvz (city, registration_date) VALUES ($1, $2) RETURNING id`,
-		city, now,
-	).Scan(&id)
+func (p *PvzRepo) OpenPvz(ctx context.Context, city string) (pvz domain.Pvz, err error) {
+	pvz = domain.Pvz{City: city, RegistrationDate: time.Now()}
+	err = inTx(ctx, p.pvz, func(ctx context.Context) error {
+		err := executor(ctx, p.pvz).QueryRowContext(ctx,
+			`INSERT INTO pvz (city, registration_date) VALUES ($1, $2) RETURNING id`,
+			city, pvz.RegistrationDate,
+		).Scan(&pvz.Id)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, p.pvz, domain.EventPvzOpened, pvz.Id, pvz)
+	})
 	if err != nil {
 		return domain.Pvz{}, err
 	}
-
-	return domain.Pvz{Id: id, City: city, RegistrationDate: now}, nil
+	return pvz, nil
 }
 
-func (p *PvzRepo) GetPvz(pvzID int) (domain.Pvz, error) {
-	row := p.pvz.Db.QueryRow(`SELECT id, city, registration_date FROM pvz WHERE id = $1`, pvzID)
+func (p *PvzRepo) GetPvz(ctx context.Context, pvzID int) (domain.Pvz, error) {
+	row := executor(ctx, p.pvz).QueryRowContext(ctx, `SELECT id, city, registration_date, capacity FROM pvz WHERE id = $1`, pvzID)
 
 	var pvz domain.Pvz
-	err := row.Scan(&pvz.Id, &pvz.City, &pvz.RegistrationDate)
+	err := row.Scan(&pvz.Id, &pvz.City, &pvz.RegistrationDate, &pvz.Capacity)
 	if errors.Is(err, sql.ErrNoRows) {
 		return domain.Pvz{}, repository.NotFound
 	} else if err != nil {
 		return domain.Pvz{}, err
 	}
+
+	typeCapacities, err := p.getTypeCapacitiesMap(ctx, []int{pvz.Id})
+	if err != nil {
+		return domain.Pvz{}, err
+	}
+	pvz.TypeCapacity = typeCapacities[pvz.Id]
 	return pvz, nil
 }
 
-func (p *PvzRepo) GetPvzListWithFilter(startDate, endDate *time.Time, offset, limit int) ([]usecases.PvzWithReceptions, error) {
-	query := `
-        SELECT p.id, p.city, p.registration_date, 
-               r.id, r.created_at, r.status
-        FROM pvz p
-        LEFT JOIN receptions r ON p.id = r.pvz_id
-    `
+func (p *PvzRepo) SetCapacity(ctx context.Context, pvzId int, capacity int, typeCapacity map[string]int) (pvz domain.Pvz, err error) {
+	pvz = domain.Pvz{Capacity: capacity}
+	if len(typeCapacity) > 0 {
+		pvz.TypeCapacity = typeCapacity
+	}
+	err = inTx(ctx, p.pvz, func(ctx context.Context) error {
+		err := executor(ctx, p.pvz).QueryRowContext(ctx,
+			`UPDATE pvz SET capacity = $1 WHERE id = $2 RETURNING id, city, registration_date`,
+			capacity, pvzId,
+		).Scan(&pvz.Id, &pvz.City, &pvz.RegistrationDate)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.NotFound
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := executor(ctx, p.pvz).ExecContext(ctx, `DELETE FROM pvz_type_capacity WHERE pvz_id = $1`, pvzId); err != nil {
+			return err
+		}
+		for productType, limit := range typeCapacity {
+			_, err := executor(ctx, p.pvz).ExecContext(ctx,
+				`INSERT INTO pvz_type_capacity (pvz_id, type, capacity) VALUES ($1, $2, $3)`,
+				pvzId, productType, limit)
+			if err != nil {
+				return err
+			}
+		}
+		return addEvent(ctx, p.pvz, domain.EventPvzCapacityChanged, pvzId, pvz)
+	})
+	if err != nil {
+		return domain.Pvz{}, err
+	}
+	return pvz, nil
+}
 
+func (p *PvzRepo) GetOccupancy(ctx context.Context, pvzId int) (domain.Occupancy, error) {
+	occupancies, err := p.getOccupancyMap(ctx, []int{pvzId})
+	if err != nil {
+		return domain.Occupancy{}, err
+	}
+	return occupancies[pvzId], nil
+}
+
+// GetPvzListWithFilter pages over PVZs first and joins receptions afterwards, so a page
+// always holds whole PVZs. With a date filter only PVZs that had receptions in the range
+// are listed, together with those receptions.
+func (p *PvzRepo) GetPvzListWithFilter(ctx context.Context, filter repository.PvzListFilter) ([]usecases.PvzWithReceptions, error) {
 	var args []interface{}
-	var where []string
+	receptionCond := receptionDateCondition(filter.StartDate, filter.EndDate, &args)
 
-	if startDate != nil {
-		where = append(where, "r.created_at >= $"+strconv.Itoa(len(args)+1))
-		args = append(args, *startDate)
+	var where []string
+	if receptionCond != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM receptions r WHERE r.pvz_id = p.id"+receptionCond+")")
 	}
-	if endDate != nil {
-		where = append(where, "r.created_at <= $"+strconv.Itoa(len(args)+1))
-		args = append(args, *endDate)
+	offset := filter.Offset
+	if filter.AfterId > 0 {
+		offset = 0
+		args = append(args, filter.AfterId)
+		where = append(where, "p.id > $"+strconv.Itoa(len(args)))
 	}
 
+	page := `SELECT p.id, p.city, p.registration_date, p.capacity FROM pvz p`
 	if len(where) > 0 {
-		query += " WHERE " + strings.Join(where, " AND ")
+		page += " WHERE " + strings.Join(where, " AND ")
 	}
-
-	query += " ORDER BY p.id, r.created_at DESC LIMIT $" + strconv.Itoa(len(args)+1)
-	args = append(args, limit)
-	query += " OFFSET $" + strconv.Itoa(len(args)+1)
+	args = append(args, filter.Limit)
+	page += " ORDER BY p.id LIMIT $" + strconv.Itoa(len(args))
 	args = append(args, offset)
+	page += " OFFSET $" + strconv.Itoa(len(args))
 
-	rows, err := p.pvz.Db.Query(query, args...)
+	query := `
+        WITH page AS (` + page + `)
+        SELECT p.id, p.city, p.registration_date, p.capacity,
+               r.id, r.created_at, r.status, r.kind
+        FROM page p
+        LEFT JOIN receptions r ON p.id = r.pvz_id` + receptionCond + `
+        ORDER BY p.id, r.created_at DESC, r.id DESC
+    `
+
+	rows, err := executor(ctx, p.pvz).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	result := make(map[int]*usecases.PvzWithReceptions)
+	var result []usecases.PvzWithReceptions
 	receptionIDs := make([]int, 0)
 
 	for rows.Next() {
 		var pvz domain.Pvz
-		var reception domain.Reception
+		var receptionId sql.NullInt64
+		var createdAt sql.NullTime
+		var status sql.NullString
+		var kind sql.NullString
 
 		err := rows.Scan(
-			&pvz.Id, &pvz.City, &pvz.RegistrationDate,
-			&reception.Id, &reception.StartDate, &reception.Status,
+			&pvz.Id, &pvz.City, &pvz.RegistrationDate, &pvz.Capacity,
+			&receptionId, &createdAt, &status, &kind,
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		if _, ok := result[pvz.Id]; !ok {
-			result[pvz.Id] = &usecases.PvzWithReceptions{
+		if len(result) == 0 || result[len(result)-1].Pvz.Id != pvz.Id {
+			result = append(result, usecases.PvzWithReceptions{
 				Pvz:        pvz,
 				Receptions: []domain.ReceptionWithProducts{},
-			}
+			})
 		}
 
-		if reception.Id != 0 {
-			result[pvz.Id].Receptions = append(result[pvz.Id].Receptions, domain.ReceptionWithProducts{
-				Reception: reception,
-				Products:  nil, // временно nil
+		if receptionId.Valid {
+			current := &result[len(result)-1]
+			current.Receptions = append(current.Receptions, domain.ReceptionWithProducts{
+				Reception: domain.Reception{
+					Id:        int(receptionId.Int64),
+					PvzId:     pvz.Id,
+					StartDate: createdAt.Time,
+					Status:    status.String,
+					Kind:      kind.String,
+				},
 			})
-			receptionIDs = append(receptionIDs, reception.Id)
+			receptionIDs = append(receptionIDs, int(receptionId.Int64))
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	productsMap, err := p.getProductsForReceptionsMap(receptionIDs)
+	productsMap, err := p.getProductsForReceptionsMap(ctx, receptionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pvzIDs := make([]int, len(result))
+	for i := range result {
+		pvzIDs[i] = result[i].Pvz.Id
+	}
+	typeCapacities, err := p.getTypeCapacitiesMap(ctx, pvzIDs)
+	if err != nil {
+		return nil, err
+	}
+	occupancies, err := p.getOccupancyMap(ctx, pvzIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, pvzData := range result {
-		for i := range pvzData.Receptions {
-			rid := pvzData.Receptions[i].Reception.Id
-			pvzData.Receptions[i].Products = productsMap[rid]
+	for i := range result {
+		result[i].Pvz.TypeCapacity = typeCapacities[result[i].Pvz.Id]
+		result[i].Occupancy = occupancies[result[i].Pvz.Id]
+		for j := range result[i].Receptions {
+			rid := result[i].Receptions[j].Reception.Id
+			result[i].Receptions[j].Products = productsMap[rid]
 		}
 	}
 
-	var finalResult []usecases.PvzWithReceptions
-	for _, v := range result {
-		finalResult = append(finalResult, *v)
+	return result, nil
+}
+
+func (p *PvzRepo) CountPvzWithFilter(ctx context.Context, startDate, endDate *time.Time) (int, error) {
+	var args []interface{}
+	query := `SELECT COUNT(*) FROM pvz p`
+	if cond := receptionDateCondition(startDate, endDate, &args); cond != "" {
+		query += " WHERE EXISTS (SELECT 1 FROM receptions r WHERE r.pvz_id = p.id" + cond + ")"
 	}
 
-	return finalResult, nil
+	var total int
+	err := executor(ctx, p.pvz).QueryRowContext(ctx, query, args...).Scan(&total)
+	return total, err
 }
 
-func (p *PvzRepo) getProductsForReceptionsMap(receptionIDs []int) (map[int][]domain.Product, error) {
+// receptionDateCondition returns the " AND ..." clause restricting receptions aliased r
+// to the date range and appends its parameters to args.
+func receptionDateCondition(startDate, endDate *time.Time, args *[]interface{}) string {
+	var cond string
+	if startDate != nil {
+		*args = append(*args, *startDate)
+		cond += " AND r.created_at >= $" + strconv.Itoa(len(*args))
+	}
+	if endDate != nil {
+		*args = append(*args, *endDate)
+		cond += " AND r.created_at <= $" + strconv.Itoa(len(*args))
+	}
+	return cond
+}
+
+func (p *PvzRepo) getProductsForReceptionsMap(ctx context.Context, receptionIDs []int) (map[int][]domain.Product, error) {
 	if len(receptionIDs) == 0 {
 		return make(map[int][]domain.Product), nil
 	}
@@ -145,13 +255,17 @@ func (p *PvzRepo) getProductsForReceptionsMap(receptionIDs []int) (map[int][]dom
 	}
 
 	query := `
-        SELECT p.id, p.type, p.added_at, rp.reception_id
+        SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), COALESCE(p.order_number, ''), p.issued_at,
+               COALESCE(pr.reason, ''), COALESCE(pr.original_product_id, 0), rp.reception_id
         FROM products p
+        JOIN product_types pt ON pt.code = p.type
         JOIN reception_products rp ON p.id = rp.product_id
+        LEFT JOIN product_returns pr ON pr.product_id = p.id
         WHERE rp.reception_id = ANY($1)
+        ORDER BY p.id
     `
 
-	rows, err := p.pvz.Db.Query(query, pq.Array(receptionIDs))
+	rows, err := executor(ctx, p.pvz).QueryContext(ctx, query, pq.Array(receptionIDs))
 	if err != nil {
 		return nil, err
 	}
@@ -162,7 +276,8 @@ func (p *PvzRepo) getProductsForReceptionsMap(receptionIDs []int) (map[int][]dom
 	for rows.Next() {
 		var product domain.Product
 		var receptionID int
-		if err := rows.Scan(&product.Id, &product.Type, &product.DateTime, &receptionID); err != nil {
+		if err := rows.Scan(&product.Id, &product.Type, &product.TypeName, &product.DateTime, &product.Barcode, &product.OrderNumber, &product.IssuedAt,
+			&product.ReturnReason, &product.OriginalProductId, &receptionID); err != nil {
 			return nil, err
 		}
 		result[receptionID] = append(result[receptionID], product)
@@ -170,3 +285,68 @@ func (p *PvzRepo) getProductsForReceptionsMap(receptionIDs []int) (map[int][]dom
 
 	return result, nil
 }
+
+func (p *PvzRepo) getTypeCapacitiesMap(ctx context.Context, pvzIDs []int) (map[int]map[string]int, error) {
+	result := make(map[int]map[string]int)
+	if len(pvzIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := executor(ctx, p.pvz).QueryContext(ctx,
+		`SELECT pvz_id, type, capacity FROM pvz_type_capacity WHERE pvz_id = ANY($1)`, pq.Array(pvzIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pvzID, capacity int
+		var productType string
+		if err := rows.Scan(&pvzID, &productType, &capacity); err != nil {
+			return nil, err
+		}
+		if result[pvzID] == nil {
+			result[pvzID] = make(map[string]int)
+		}
+		result[pvzID][productType] = capacity
+	}
+	return result, rows.Err()
+}
+
+// getOccupancyMap counts the products in stock per PVZ and type, whatever the
+// status of the reception that brought them in.
+func (p *PvzRepo) getOccupancyMap(ctx context.Context, pvzIDs []int) (map[int]domain.Occupancy, error) {
+	result := make(map[int]domain.Occupancy, len(pvzIDs))
+	for _, id := range pvzIDs {
+		result[id] = domain.Occupancy{ByType: make(map[string]int)}
+	}
+	if len(pvzIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := executor(ctx, p.pvz).QueryContext(ctx, `
+        SELECT r.pvz_id, p.type, COUNT(*)
+        FROM products p
+        JOIN reception_products rp ON rp.product_id = p.id
+        JOIN receptions r ON r.id = rp.reception_id
+        WHERE r.pvz_id = ANY($1) AND p.issued_at IS NULL AND p.shipped_at IS NULL
+        GROUP BY r.pvz_id, p.type
+    `, pq.Array(pvzIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pvzID, count int
+		var productType string
+		if err := rows.Scan(&pvzID, &productType, &count); err != nil {
+			return nil, err
+		}
+		occupancy := result[pvzID]
+		occupancy.Total += count
+		occupancy.ByType[productType] = count
+		result[pvzID] = occupancy
+	}
+	return result, rows.Err()
+}
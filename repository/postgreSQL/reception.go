@@ -3,6 +3,12 @@ package postgreSQL
 import (
 	"avito_test/domain"
 	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
 	"time"
 )
 
@@ -14,57 +20,72 @@ func NewReceptionRepo(receptions *postgres_connect.PostgresStorage) *ReceptionRe
 	return &ReceptionRepo{receptions: receptions}
 }
 
-func (r *ReceptionRepo) StartReception(pvzId int) (domain.Reception, error) {
-	now := time.Now()
-	status := "in_progress"
-	var id int
-	err := r.receptions.Db.QueryRow(
-		`INSERT INTO receptions (pvz_id, created_at, status) VALUES ($1, $2, $3) RETURNING id`,
-		pvzId, now, status,
-	).Scan(&id)
+func (r *ReceptionRepo) StartReception(ctx context.Context, pvzId int, kind string) (reception domain.Reception, err error) {
+	reception = domain.Reception{PvzId: pvzId, StartDate: time.Now(), Status: "in_progress", Kind: kind}
+	err = inTx(ctx, r.receptions, func(ctx context.Context) error {
+		err := executor(ctx, r.receptions).QueryRowContext(ctx,
+			`INSERT INTO receptions (pvz_id, created_at, status, kind) VALUES ($1, $2, $3, $4) RETURNING id`,
+			pvzId, reception.StartDate, reception.Status, kind,
+		).Scan(&reception.Id)
+		if pgErrorCode(err) == uniqueViolation {
+			return repository.ErrReceptionInProgress
+		} else if err != nil {
+			return err
+		}
+		return addEvent(ctx, r.receptions, domain.EventReceptionStarted, pvzId, reception)
+	})
 	if err != nil {
 		return domain.Reception{}, err
 	}
-
-	return domain.Reception{Id: id, PvzId: pvzId, StartDate: now, Status: status}, nil
+	return reception, nil
 }
 
-func (r *ReceptionRepo) CloseReception(pvzId int) (domain.Reception, error) {
-	var reception domain.Reception
+func (r *ReceptionRepo) CloseReception(ctx context.Context, pvzId int) (reception domain.Reception, err error) {
+	err = inTx(ctx, r.receptions, func(ctx context.Context) error {
+		err := executor(ctx, r.receptions).QueryRowContext(ctx, `
+			SELECT id FROM receptions
+			WHERE pvz_id = $1 AND status = 'in_progress'
+			ORDER BY created_at DESC LIMIT 1
+			FOR UPDATE`, pvzId).Scan(&reception.Id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrReceptionClosed
+		} else if err != nil {
+			return err
+		}
 
-	err := r.receptions.Db.QueryRow(`
-		SELECT id FROM receptions
-		WHERE pvz_id = $1
-		ORDER BY created_at DESC LIMIT 1`, pvzId).Scan(&reception.Id)
+		err = executor(ctx, r.receptions).QueryRowContext(ctx, `
+			UPDATE receptions SET status = 'closed'
+			WHERE id = $1
+			RETURNING created_at, kind`, reception.Id).Scan(&reception.StartDate, &reception.Kind)
+		if err != nil {
+			return err
+		}
 
+		reception.Status = "closed"
+		reception.PvzId = pvzId
+		return addEvent(ctx, r.receptions, domain.EventReceptionClosed, pvzId, reception)
+	})
 	if err != nil {
 		return domain.Reception{}, err
 	}
-
-	_, err = r.receptions.Db.Exec(`
-		UPDATE receptions SET status = 'closed'
-		WHERE id = $1`, reception.Id)
-
-	if err != nil {
-		return domain.Reception{}, err
-	}
-
-	reception.Status = "closed"
-	reception.PvzId = pvzId
-
 	return reception, nil
 }
 
-func (r *ReceptionRepo) GetLastReception(pvzId int) (domain.Reception, error) {
+// GetLastReception locks the returned row until the surrounding transaction ends,
+// so the reception cannot be closed while products are being added to it.
+func (r *ReceptionRepo) GetLastReception(ctx context.Context, pvzId int) (domain.Reception, error) {
 	var rec domain.Reception
-	err := r.receptions.Db.QueryRow(`
-		SELECT id, created_at,status
+	err := executor(ctx, r.receptions).QueryRowContext(ctx, `
+		SELECT id, created_at, status, kind
 		FROM receptions
 		WHERE pvz_id = $1
-		ORDER BY created_at DESC LIMIT 1`, pvzId).
-		Scan(&rec.Id, &rec.StartDate, &rec.Status)
+		ORDER BY created_at DESC LIMIT 1
+		FOR UPDATE`, pvzId).
+		Scan(&rec.Id, &rec.StartDate, &rec.Status, &rec.Kind)
 
-	if err != nil {
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Reception{}, repository.NotFound
+	} else if err != nil {
 		return domain.Reception{}, err
 	}
 
@@ -72,42 +93,200 @@ func (r *ReceptionRepo) GetLastReception(pvzId int) (domain.Reception, error) {
 	return rec, nil
 }
 
-func (r *ReceptionRepo) AddProduct(pvzId int, productId int) error {
-	rec, err := r.GetLastReception(pvzId)
-	if err != nil {
-		return err
-	}
+func (r *ReceptionRepo) AddProduct(ctx context.Context, pvzId int, productId int) error {
+	return inTx(ctx, r.receptions, func(ctx context.Context) error {
+		rec, err := r.GetLastReception(ctx, pvzId)
+		if err != nil {
+			return err
+		}
+		if rec.Status != "in_progress" {
+			return repository.ErrReceptionClosed
+		}
+
+		_, err = executor(ctx, r.receptions).ExecContext(ctx,
+			`INSERT INTO reception_products (reception_id, product_id) VALUES ($1, $2)`,
+			rec.Id, productId,
+		)
+		if pgErrorCode(err) == checkViolation {
+			return repository.ErrReceptionClosed
+		} else if err != nil {
+			return err
+		}
 
-	_, err = r.receptions.Db.Exec(
-		`INSERT INTO reception_products (reception_id, product_id) VALUES ($1, $2)`,
-		rec.Id, productId,
-	)
-	return err
+		event := domain.ProductEvent{ReceptionId: rec.Id, ProductId: productId}
+		err = executor(ctx, r.receptions).QueryRowContext(ctx,
+			`SELECT type FROM products WHERE id = $1`, productId).Scan(&event.Type)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, r.receptions, domain.EventProductAdded, pvzId, event)
+	})
 }
 
-func (r *ReceptionRepo) DeleteProduct(pvzId int) (string, error) {
-	rec, err := r.GetLastReception(pvzId)
+func (r *ReceptionRepo) DeleteProduct(ctx context.Context, pvzId int, productId int, reason string) (deletedId string, err error) {
+	err = inTx(ctx, r.receptions, func(ctx context.Context) error {
+		rec, err := r.GetLastReception(ctx, pvzId)
+		if err != nil {
+			return err
+		}
+		if rec.Status != "in_progress" {
+			return repository.ErrReceptionClosed
+		}
+
+		if productId == 0 {
+			err = executor(ctx, r.receptions).QueryRowContext(ctx, `
+				SELECT product_id FROM reception_products
+				WHERE reception_id = $1
+				ORDER BY product_id DESC LIMIT 1`, rec.Id).Scan(&productId)
+			if errors.Is(err, sql.ErrNoRows) {
+				return repository.ErrProductNotFound
+			} else if err != nil {
+				return err
+			}
+		}
+
+		res, err := executor(ctx, r.receptions).ExecContext(ctx, `
+			DELETE FROM reception_products WHERE reception_id = $1 AND product_id = $2`,
+			rec.Id, productId,
+		)
+		if pgErrorCode(err) == checkViolation {
+			return repository.ErrReceptionClosed
+		} else if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return repository.ErrProductNotFound
+		}
+
+		event := domain.ProductEvent{ReceptionId: rec.Id, ProductId: productId, Reason: reason}
+		err = executor(ctx, r.receptions).QueryRowContext(ctx, `
+			INSERT INTO product_removals (reception_id, product_id, product_type, reason, removed_at)
+			SELECT $1, id, type, $3, $4 FROM products WHERE id = $2
+			RETURNING product_type`,
+			rec.Id, productId, reason, time.Now(),
+		).Scan(&event.Type)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, r.receptions, domain.EventProductRemoved, pvzId, event)
+	})
 	if err != nil {
 		return "", err
 	}
+	return strconv.Itoa(productId), nil
+}
 
-	var productId string
-	err = r.receptions.Db.QueryRow(`
-		SELECT product_id FROM reception_products
+func (r *ReceptionRepo) ListRemovals(ctx context.Context, receptionId int) ([]domain.ProductRemoval, error) {
+	rows, err := executor(ctx, r.receptions).QueryContext(ctx, `
+		SELECT id, reception_id, product_id, product_type, reason, removed_at
+		FROM product_removals
 		WHERE reception_id = $1
-		ORDER BY product_id DESC LIMIT 1`, rec.Id).Scan(&productId)
+		ORDER BY removed_at, id`, receptionId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	removals := make([]domain.ProductRemoval, 0)
+	for rows.Next() {
+		var removal domain.ProductRemoval
+		err := rows.Scan(&removal.Id, &removal.ReceptionId, &removal.ProductId, &removal.ProductType, &removal.Reason, &removal.RemovedAt)
+		if err != nil {
+			return nil, err
+		}
+		removals = append(removals, removal)
+	}
+	return removals, rows.Err()
+}
+
+func (r *ReceptionRepo) ListReceptions(ctx context.Context, pvzId int, filter usecases.ReceptionFilter) ([]domain.Reception, error) {
+	query := `SELECT id, created_at, status, kind FROM receptions r WHERE pvz_id = $1`
+	args := []interface{}{pvzId}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += " AND status = $" + strconv.Itoa(len(args))
+	}
+	query += receptionDateCondition(filter.StartDate, filter.EndDate, &args)
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := executor(ctx, r.receptions).QueryContext(ctx, query, args...)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer rows.Close()
 
-	_, err = r.receptions.Db.Exec(`
-		DELETE FROM reception_products WHERE reception_id = $1 AND product_id = $2`,
-		rec.Id, productId,
-	)
+	receptions := make([]domain.Reception, 0)
+	for rows.Next() {
+		rec := domain.Reception{PvzId: pvzId}
+		if err := rows.Scan(&rec.Id, &rec.StartDate, &rec.Status, &rec.Kind); err != nil {
+			return nil, err
+		}
+		receptions = append(receptions, rec)
+	}
+	return receptions, rows.Err()
+}
+
+func (r *ReceptionRepo) CountOpenReceptions(ctx context.Context) (map[string]int, error) {
+	rows, err := executor(ctx, r.receptions).QueryContext(ctx, `
+		SELECT p.city, COUNT(*) FROM receptions r
+		JOIN pvz p ON p.id = r.pvz_id
+		WHERE r.status = 'in_progress'
+		GROUP BY p.city`)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return productId, nil
+	open := make(map[string]int)
+	for rows.Next() {
+		var city string
+		var count int
+		if err := rows.Scan(&city, &count); err != nil {
+			return nil, err
+		}
+		open[city] = count
+	}
+	return open, rows.Err()
+}
+
+func (r *ReceptionRepo) GetReception(ctx context.Context, receptionId int) (domain.ReceptionWithProducts, error) {
+	var result domain.ReceptionWithProducts
+	err := executor(ctx, r.receptions).QueryRowContext(ctx, `
+		SELECT id, pvz_id, created_at, status, kind FROM receptions
+		WHERE id = $1`, receptionId).
+		Scan(&result.Reception.Id, &result.Reception.PvzId, &result.Reception.StartDate, &result.Reception.Status, &result.Reception.Kind)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ReceptionWithProducts{}, repository.NotFound
+	} else if err != nil {
+		return domain.ReceptionWithProducts{}, err
+	}
+
+	rows, err := executor(ctx, r.receptions).QueryContext(ctx, `
+		SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), COALESCE(p.order_number, ''), p.issued_at,
+		       COALESCE(pr.reason, ''), COALESCE(pr.original_product_id, 0)
+		FROM products p
+		JOIN product_types pt ON pt.code = p.type
+		JOIN reception_products rp ON p.id = rp.product_id
+		LEFT JOIN product_returns pr ON pr.product_id = p.id
+		WHERE rp.reception_id = $1
+		ORDER BY p.added_at, p.id`, receptionId)
+	if err != nil {
+		return domain.ReceptionWithProducts{}, err
+	}
+	defer rows.Close()
+
+	result.Products = make([]domain.Product, 0)
+	for rows.Next() {
+		var product domain.Product
+		if err := rows.Scan(&product.Id, &product.Type, &product.TypeName, &product.DateTime, &product.Barcode, &product.OrderNumber, &product.IssuedAt,
+			&product.ReturnReason, &product.OriginalProductId); err != nil {
+			return domain.ReceptionWithProducts{}, err
+		}
+		result.Products = append(result.Products, product)
+	}
+	return result, rows.Err()
 }
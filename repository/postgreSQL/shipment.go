@@ -0,0 +1,131 @@
+package postgreSQL
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type ShipmentRepo struct {
+	shipments *postgres_connect.PostgresStorage
+}
+
+func NewShipmentRepo(shipments *postgres_connect.PostgresStorage) *ShipmentRepo {
+	return &ShipmentRepo{shipments: shipments}
+}
+
+func (s *ShipmentRepo) MarkOverdue(ctx context.Context, now time.Time) (int, error) {
+	res, err := executor(ctx, s.shipments).ExecContext(ctx, `
+		UPDATE products p SET overdue_at = $1
+		FROM product_types pt
+		WHERE pt.code = p.type
+		  AND p.added_at + pt.storage_days * INTERVAL '1 day' < $1
+		  AND p.overdue_at IS NULL AND p.issued_at IS NULL AND p.shipped_at IS NULL
+		  AND EXISTS (SELECT 1 FROM reception_products rp
+		              JOIN receptions r ON r.id = rp.reception_id
+		              WHERE rp.product_id = p.id AND r.status = 'closed')`, now)
+	if err != nil {
+		return 0, err
+	}
+	marked, err := res.RowsAffected()
+	return int(marked), err
+}
+
+func (s *ShipmentRepo) ListOverdueProducts(ctx context.Context, pvzId int) ([]domain.Product, error) {
+	return s.queryProducts(ctx, `
+		SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), COALESCE(p.order_number, ''), p.overdue_at, p.shipped_at
+		FROM products p
+		JOIN product_types pt ON pt.code = p.type
+		JOIN reception_products rp ON rp.product_id = p.id
+		JOIN receptions r ON r.id = rp.reception_id
+		WHERE r.pvz_id = $1 AND p.overdue_at IS NOT NULL AND p.issued_at IS NULL AND p.shipped_at IS NULL
+		ORDER BY p.added_at, p.id`, pvzId)
+}
+
+func (s *ShipmentRepo) CreateShipment(ctx context.Context, pvzId int, productIds []int) (_ domain.ReturnShipment, err error) {
+	shipment := domain.ReturnShipment{PvzId: pvzId, CreatedAt: time.Now()}
+	err = inTx(ctx, s.shipments, func(ctx context.Context) error {
+		err := executor(ctx, s.shipments).QueryRowContext(ctx,
+			`INSERT INTO return_shipments (pvz_id, created_at) VALUES ($1, $2) RETURNING id`,
+			pvzId, shipment.CreatedAt,
+		).Scan(&shipment.Id)
+		if err != nil {
+			return err
+		}
+
+		for _, productId := range productIds {
+			res, err := executor(ctx, s.shipments).ExecContext(ctx, `
+				UPDATE products SET shipped_at = $1
+				WHERE id = $2 AND issued_at IS NULL AND shipped_at IS NULL`,
+				shipment.CreatedAt, productId)
+			if err != nil {
+				return err
+			}
+			if affected, err := res.RowsAffected(); err != nil {
+				return err
+			} else if affected == 0 {
+				return repository.ErrProductNotInStock
+			}
+
+			_, err = executor(ctx, s.shipments).ExecContext(ctx,
+				`INSERT INTO return_shipment_items (shipment_id, product_id) VALUES ($1, $2)`, shipment.Id, productId)
+			if err != nil {
+				return err
+			}
+		}
+		event := domain.ShipmentEvent{ShipmentId: shipment.Id, ProductIds: productIds}
+		return addEvent(ctx, s.shipments, domain.EventShipmentCreated, pvzId, event)
+	})
+	if err != nil {
+		return domain.ReturnShipment{}, err
+	}
+	return shipment, nil
+}
+
+func (s *ShipmentRepo) GetShipment(ctx context.Context, shipmentId int) (domain.ReturnShipmentWithProducts, error) {
+	var result domain.ReturnShipmentWithProducts
+	err := executor(ctx, s.shipments).QueryRowContext(ctx,
+		`SELECT id, pvz_id, created_at FROM return_shipments WHERE id = $1`, shipmentId,
+	).Scan(&result.Shipment.Id, &result.Shipment.PvzId, &result.Shipment.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ReturnShipmentWithProducts{}, repository.NotFound
+	} else if err != nil {
+		return domain.ReturnShipmentWithProducts{}, err
+	}
+
+	result.Products, err = s.queryProducts(ctx, `
+		SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), COALESCE(p.order_number, ''), p.overdue_at, p.shipped_at
+		FROM products p
+		JOIN product_types pt ON pt.code = p.type
+		JOIN return_shipment_items si ON si.product_id = p.id
+		WHERE si.shipment_id = $1
+		ORDER BY p.id`, shipmentId)
+	if err != nil {
+		return domain.ReturnShipmentWithProducts{}, err
+	}
+	return result, nil
+}
+
+func (s *ShipmentRepo) queryProducts(ctx context.Context, query string, args ...interface{}) ([]domain.Product, error) {
+	rows, err := executor(ctx, s.shipments).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]domain.Product, 0)
+	for rows.Next() {
+		var product domain.Product
+		err := rows.Scan(&product.Id, &product.Type, &product.TypeName, &product.DateTime,
+			&product.Barcode, &product.OrderNumber, &product.OverdueAt, &product.ShippedAt)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
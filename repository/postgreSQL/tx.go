@@ -0,0 +1,109 @@
+package postgreSQL
+
+import (
+	"avito_test/pkg/logger"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/pkg/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+type txKey struct{}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// executor returns the transaction stored in ctx by TxManager.Do, or the plain connection pool.
+func executor(ctx context.Context, storage *postgres_connect.PostgresStorage) querier {
+	var q querier = storage.Db
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		q = tx
+	}
+	// Statements outside of a traced call, like the polls of the outbox relay, are not traced.
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return q
+	}
+	return tracedQuerier{q}
+}
+
+// tracedQuerier opens a client span for every statement. Spans of queries end once the
+// rows are returned, reading them is not included.
+type tracedQuerier struct {
+	querier
+}
+
+func (q tracedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startStatement(ctx, query)
+	result, err := q.querier.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (q tracedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startStatement(ctx, query)
+	rows, err := q.querier.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (q tracedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startStatement(ctx, query)
+	row := q.querier.QueryRowContext(ctx, query, args...)
+	tracing.End(span, row.Err())
+	return row
+}
+
+// startStatement names the span after the SQL command, e.g. "SELECT"; the statement
+// itself only has placeholders, so it is recorded as is.
+func startStatement(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := "SQL"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	return tracing.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(query),
+	))
+}
+
+type TxManager struct {
+	storage *postgres_connect.PostgresStorage
+}
+
+func NewTxManager(storage *postgres_connect.PostgresStorage) *TxManager {
+	return &TxManager{storage: storage}
+}
+
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.storage.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		// The driver has already rolled back a transaction whose context is done.
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			logger.FromContext(ctx).Warn("transaction rollback failed", "error", rollbackErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// inTx runs fn in the transaction carried by ctx or in a new one, so that a change
+// and its outbox event are committed together.
+func inTx(ctx context.Context, storage *postgres_connect.PostgresStorage, fn func(ctx context.Context) error) error {
+	return NewTxManager(storage).Do(ctx, fn)
+}
@@ -4,9 +4,9 @@ import (
 	"avito_test/domain"
 	"avito_test/pkg/postgres_connect"
 	"avito_test/repository"
+	"context"
 	"database/sql"
 	"errors"
-	"github.com/lib/pq"
 )
 
 type UserRepo struct {
@@ -17,14 +17,14 @@ func NewUserRepo(users *postgres_connect.PostgresStorage) *UserRepo {
 	return &UserRepo{users: users}
 }
 
-func (u *UserRepo) Register(email string, password string, role string) (domain.User, error) {
+func (u *UserRepo) Register(ctx context.Context, email string, password string, role string) (domain.User, error) {
 	var id int
-	err := u.users.Db.QueryRow(
+	err := executor(ctx, u.users).QueryRowContext(ctx,
 		`INSERT INTO users (email, password_hash, role) VALUES ($1, $2, $3) RETURNING id`,
 		email, password, role,
 	).Scan(&id)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		if pgErrorCode(err) == uniqueViolation {
 			return domain.User{}, repository.ErrEmailAlreadyExists
 		}
 		return domain.User{}, err
@@ -32,8 +32,8 @@ func (u *UserRepo) Register(email string, password string, role string) (domain.
 	return domain.User{Id: id, Email: email, Password: password, Role: role}, nil
 }
 
-func (u *UserRepo) Login(email string) (domain.User, error) {
-	row := u.users.Db.QueryRow(
+func (u *UserRepo) Login(ctx context.Context, email string) (domain.User, error) {
+	row := executor(ctx, u.users).QueryRowContext(ctx,
 		`SELECT id, email, password_hash, role FROM users WHERE email = $1`,
 		email,
 	)
@@ -0,0 +1,186 @@
+package postgreSQL
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+type WebhookRepo struct {
+	webhooks *postgres_connect.PostgresStorage
+}
+
+func NewWebhookRepo(webhooks *postgres_connect.PostgresStorage) *WebhookRepo {
+	return &WebhookRepo{webhooks: webhooks}
+}
+
+const webhookColumns = `id, url, secret, event_types, COALESCE(pvz_id, 0), city, created_at`
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_error, response_status, created_at, delivered_at`
+
+func (w *WebhookRepo) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	webhook.CreatedAt = time.Now()
+	err := executor(ctx, w.webhooks).QueryRowContext(ctx, `
+		INSERT INTO webhooks (url, secret, event_types, pvz_id, city, created_at)
+		VALUES ($1, $2, COALESCE($3::TEXT[], '{}'), NULLIF($4, 0), $5, $6)
+		RETURNING id`,
+		webhook.URL, webhook.Secret, pq.Array(webhook.EventTypes), webhook.PvzId, webhook.City, webhook.CreatedAt,
+	).Scan(&webhook.Id)
+	if pgErrorCode(err) == foreignKeyViolation {
+		return domain.Webhook{}, repository.NotFound
+	} else if err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (w *WebhookRepo) GetWebhook(ctx context.Context, webhookId int) (domain.Webhook, error) {
+	webhook, err := scanWebhook(executor(ctx, w.webhooks).QueryRowContext(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, webhookId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Webhook{}, repository.NotFound
+	} else if err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (w *WebhookRepo) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := executor(ctx, w.webhooks).QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]domain.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (w *WebhookRepo) DeleteWebhook(ctx context.Context, webhookId int) error {
+	res, err := executor(ctx, w.webhooks).ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, webhookId)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return repository.NotFound
+	}
+	return nil
+}
+
+func (w *WebhookRepo) AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	_, err := executor(ctx, w.webhooks).ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING`,
+		delivery.WebhookId, delivery.EventId, delivery.EventType, string(delivery.Payload), delivery.Status,
+		delivery.NextAttemptAt, delivery.CreatedAt)
+	if pgErrorCode(err) == foreignKeyViolation {
+		return repository.NotFound
+	}
+	return err
+}
+
+func (w *WebhookRepo) GetDelivery(ctx context.Context, deliveryId int) (domain.WebhookDelivery, error) {
+	delivery, err := scanDelivery(executor(ctx, w.webhooks).QueryRowContext(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, deliveryId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.WebhookDelivery{}, repository.NotFound
+	} else if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func (w *WebhookRepo) ListDeliveries(ctx context.Context, webhookId int, limit int) ([]domain.WebhookDelivery, error) {
+	return w.listDeliveries(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC LIMIT $2`, webhookId, limit)
+}
+
+func (w *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	return w.listDeliveries(ctx, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries SET next_attempt_at = $1
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = $2 AND next_attempt_at <= $3
+				ORDER BY next_attempt_at, id LIMIT $4
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING `+deliveryColumns+`
+		)
+		SELECT `+deliveryColumns+` FROM claimed ORDER BY id`, until, domain.DeliveryStatusPending, now, limit)
+}
+
+func (w *WebhookRepo) listDeliveries(ctx context.Context, query string, args ...interface{}) ([]domain.WebhookDelivery, error) {
+	rows, err := executor(ctx, w.webhooks).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (w *WebhookRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	res, err := executor(ctx, w.webhooks).ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, response_status = $5, delivered_at = $6
+		WHERE id = $7`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.ResponseStatus,
+		delivery.DeliveredAt, delivery.Id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return repository.NotFound
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row scanner) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := row.Scan(&webhook.Id, &webhook.URL, &webhook.Secret, pq.Array(&webhook.EventTypes), &webhook.PvzId,
+		&webhook.City, &webhook.CreatedAt)
+	return webhook, err
+}
+
+func scanDelivery(row scanner) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var payload []byte
+	err := row.Scan(&delivery.Id, &delivery.WebhookId, &delivery.EventId, &delivery.EventType, &payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastError, &delivery.ResponseStatus,
+		&delivery.CreatedAt, &delivery.DeliveredAt)
+	delivery.Payload = payload
+	return delivery, err
+}
@@ -1,8 +1,15 @@
 package repository
 
-import "avito_test/domain"
+import (
+	"avito_test/domain"
+	"context"
+)
 
 type Product interface {
-	AddProduct(sort string) (product domain.Product, err error)
-	DeleteProduct(productId int) (err error)
+	// AddProduct stores the product; its Type must be a catalog code. Return
+	// details are stored when ReturnReason is set.
+	AddProduct(ctx context.Context, product domain.Product) (domain.Product, error)
+	GetProduct(ctx context.Context, productId int) (domain.Product, error)
+	DeleteProduct(ctx context.Context, productId int) (err error)
+	GetProductByBarcode(ctx context.Context, barcode string) (domain.ProductLocation, error)
 }
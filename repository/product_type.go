@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+type ProductType interface {
+	// CreateProductType fails with ErrProductTypeAlreadyExists if the code or the name is
+	// taken, as a code or as a name, by another type.
+	CreateProductType(ctx context.Context, productType domain.ProductType) (domain.ProductType, error)
+	// GetProductType looks the type up by its code or by its display name; a code match
+	// wins over a name.
+	GetProductType(ctx context.Context, codeOrName string) (domain.ProductType, error)
+	ListProductTypes(ctx context.Context) ([]domain.ProductType, error)
+	SetStorageDays(ctx context.Context, code string, storageDays int) (domain.ProductType, error)
+}
@@ -2,62 +2,129 @@ package prometheus
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
 	"time"
 )
 
-var (
-	// Технические метрики
-	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
-		Name: "http_requests_total",
-		Help: "Total number of HTTP requests",
-	}, []string{"method", "path", "status"})
+// DefaultBuckets bound the response time histogram when no buckets are configured.
+var DefaultBuckets = []float64{0.1, 0.3, 0.5, 1, 2, 5}
+
+// ReceptionDurationBuckets bound the reception duration histogram: from a minute to about
+// eight and a half hours.
+var ReceptionDurationBuckets = prometheus.ExponentialBuckets(60, 2, 10)
+
+// UnmatchedRoute labels requests that match no route, so unknown paths cannot add series.
+const UnmatchedRoute = "unmatched"
 
-	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
-		Name:    "http_response_time_seconds",
-		Help:    "Duration of HTTP requests",
-		Buckets: []float64{0.1, 0.3, 0.5, 1, 2, 5},
-	}, []string{"method", "path"})
+// Metrics holds the collectors of the service, registered in the registry passed to
+// NewMetrics rather than the global one, so tests can use a registry of their own.
+type Metrics struct {
+	// Технические метрики
+	httpRequestsTotal *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
 
 	// Бизнесовые метрики
-	pvzCreated = promauto.NewCounter(prometheus.CounterOpts{
-		Name: "pvz_created_total",
-		Help: "Total number of PVZ created",
-	})
-
-	receptionsCreated = promauto.NewCounter(prometheus.CounterOpts{
-		Name: "receptions_created_total",
-		Help: "Total number of receptions created",
-	})
-
-	productsAdded = promauto.NewCounter(prometheus.CounterOpts{
-		Name: "products_added_total",
-		Help: "Total number of products added",
-	})
-)
+	pvzCreated        prometheus.Counter
+	receptionsStarted *prometheus.CounterVec
+	receptionsClosed  *prometheus.CounterVec
+	receptionDuration *prometheus.HistogramVec
+	productsAdded     *prometheus.CounterVec
+	productsDeleted   *prometheus.CounterVec
+}
+
+// NewMetrics registers the collectors in registerer; buckets, in seconds, bound the
+// response time histogram and default to DefaultBuckets.
+func NewMetrics(registerer prometheus.Registerer, buckets []float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	factory := promauto.With(registerer)
+	return &Metrics{
+		httpRequestsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests",
+		}, []string{"method", "path", "status"}),
+
+		httpDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_time_seconds",
+			Help:    "Duration of HTTP requests",
+			Buckets: buckets,
+		}, []string{"method", "path"}),
+
+		pvzCreated: factory.NewCounter(prometheus.CounterOpts{
+			Name: "pvz_created_total",
+			Help: "Total number of PVZ created",
+		}),
+
+		receptionsStarted: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "receptions_started_total",
+			Help: "Total number of receptions started",
+		}, []string{"city"}),
+
+		receptionsClosed: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "receptions_closed_total",
+			Help: "Total number of receptions closed",
+		}, []string{"city"}),
+
+		receptionDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "reception_duration_seconds",
+			Help:    "Time from the start of a reception to its close",
+			Buckets: ReceptionDurationBuckets,
+		}, []string{"city"}),
+
+		productsAdded: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "products_added_total",
+			Help: "Total number of products added",
+		}, []string{"type", "city"}),
+
+		productsDeleted: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "products_deleted_total",
+			Help: "Total number of products deleted from receptions",
+		}, []string{"type", "city"}),
+	}
+}
+
+// NewRegistry returns a registry with the Go runtime and process collectors, which the
+// global registry used to provide.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return registry
+}
+
+// Handler serves the metrics gathered from gatherer. A collector that fails, like one
+// that cannot reach the storage, is left out instead of failing the whole scrape.
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+// RecordHTTPRequest labels the request by its route pattern, e.g. "/pvz/{pvzId}/receptions".
+func (m *Metrics) RecordHTTPRequest(method, route string, statusCode int, duration time.Duration) {
+	m.httpRequestsTotal.WithLabelValues(method, route, http.StatusText(statusCode)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
 
-func InitPrometheus() {
-	http.Handle("/metrics", promhttp.Handler())
-	go func() {
-		http.ListenAndServe(":9000", nil)
-	}()
+func (m *Metrics) RecordPVZCreated() {
+	m.pvzCreated.Inc()
 }
 
-func RecordHTTPRequest(method, path string, statusCode int, duration time.Duration) {
-	httpRequestsTotal.WithLabelValues(method, path, http.StatusText(statusCode)).Inc()
-	httpDuration.WithLabelValues(method, path).Observe(duration.Seconds())
+func (m *Metrics) RecordReceptionStarted(city string) {
+	m.receptionsStarted.WithLabelValues(city).Inc()
 }
 
-func RecordPVZCreated() {
-	pvzCreated.Inc()
+// RecordReceptionClosed takes how long the reception was in progress.
+func (m *Metrics) RecordReceptionClosed(city string, duration time.Duration) {
+	m.receptionsClosed.WithLabelValues(city).Inc()
+	m.receptionDuration.WithLabelValues(city).Observe(duration.Seconds())
 }
 
-func RecordReceptionCreated() {
-	receptionsCreated.Inc()
+func (m *Metrics) RecordProductAdded(productType, city string) {
+	m.productsAdded.WithLabelValues(productType, city).Inc()
 }
 
-func RecordProductAdded() {
-	productsAdded.Inc()
+func (m *Metrics) RecordProductDeleted(productType, city string) {
+	m.productsDeleted.WithLabelValues(productType, city).Inc()
 }
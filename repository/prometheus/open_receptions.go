@@ -0,0 +1,45 @@
+package prometheus
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// countTimeout bounds the query run on each scrape, below the default scrape timeout.
+const countTimeout = 5 * time.Second
+
+// OpenReceptions is the receptions_open gauge. It counts the receptions in progress in
+// the storage on each scrape, so every instance reports the same number however many
+// of them started or closed the receptions; aggregate it with max, not sum.
+type OpenReceptions struct {
+	desc  *prometheus.Desc
+	count func(ctx context.Context) (map[string]int, error)
+}
+
+// NewOpenReceptions takes the count of receptions in progress by city, e.g. the
+// CountOpenReceptions of the reception repository.
+func NewOpenReceptions(count func(ctx context.Context) (map[string]int, error)) *OpenReceptions {
+	return &OpenReceptions{
+		desc:  prometheus.NewDesc("receptions_open", "Number of receptions in progress", []string{"city"}, nil),
+		count: count,
+	}
+}
+
+func (o *OpenReceptions) Describe(ch chan<- *prometheus.Desc) {
+	ch <- o.desc
+}
+
+func (o *OpenReceptions) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	byCity, err := o.count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(o.desc, err)
+		return
+	}
+	for city, open := range byCity {
+		ch <- prometheus.MustNewConstMetric(o.desc, prometheus.GaugeValue, float64(open), city)
+	}
+}
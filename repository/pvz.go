@@ -3,11 +3,28 @@ package repository
 import (
 	"avito_test/domain"
 	"avito_test/usecases"
+	"context"
 	"time"
 )
 
+// PvzListFilter pages PVZs ordered by id. With AfterId set only PVZs with a
+// greater id are returned and Offset is ignored.
+type PvzListFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	AfterId   int
+	Offset    int
+	Limit     int
+}
+
 type Pvz interface {
-	OpenPvz(city string) (domain.Pvz, error)
-	GetPvz(pvzId int) (domain.Pvz, error)
-	GetPvzListWithFilter(startDate, endDate *time.Time, offset, limit int) ([]usecases.PvzWithReceptions, error)
+	OpenPvz(ctx context.Context, city string) (domain.Pvz, error)
+	// GetPvz returns the PVZ together with its capacity settings.
+	GetPvz(ctx context.Context, pvzId int) (domain.Pvz, error)
+	// SetCapacity replaces the total and per type capacity of the PVZ.
+	SetCapacity(ctx context.Context, pvzId int, capacity int, typeCapacity map[string]int) (domain.Pvz, error)
+	// GetOccupancy counts the products in stock at the PVZ, including open receptions.
+	GetOccupancy(ctx context.Context, pvzId int) (domain.Occupancy, error)
+	GetPvzListWithFilter(ctx context.Context, filter PvzListFilter) ([]usecases.PvzWithReceptions, error)
+	CountPvzWithFilter(ctx context.Context, startDate, endDate *time.Time) (int, error)
 }
@@ -1,11 +1,24 @@
 package repository
 
-import "avito_test/domain"
+import (
+	"avito_test/domain"
+	"avito_test/usecases"
+	"context"
+)
 
 type Reception interface {
-	StartReception(pvzId int) (domain.Reception, error)
-	CloseReception(receptionId int) (domain.Reception, error)
-	GetLastReception(pvzId int) (domain.Reception, error)
-	AddProduct(pvzId int, productId int) error
-	DeleteProduct(pvzId int) (string, error)
+	StartReception(ctx context.Context, pvzId int, kind string) (domain.Reception, error)
+	CloseReception(ctx context.Context, receptionId int) (domain.Reception, error)
+	GetLastReception(ctx context.Context, pvzId int) (domain.Reception, error)
+	AddProduct(ctx context.Context, pvzId int, productId int) error
+	// DeleteProduct unlinks productId, or the last scanned product when productId is 0,
+	// from the in-progress reception of the PVZ and records the removal with its reason.
+	DeleteProduct(ctx context.Context, pvzId int, productId int, reason string) (string, error)
+	ListRemovals(ctx context.Context, receptionId int) ([]domain.ProductRemoval, error)
+	// ListReceptions returns the receptions of the PVZ newest first.
+	ListReceptions(ctx context.Context, pvzId int, filter usecases.ReceptionFilter) ([]domain.Reception, error)
+	// GetReception returns the reception with its products in the order they were scanned.
+	GetReception(ctx context.Context, receptionId int) (domain.ReceptionWithProducts, error)
+	// CountOpenReceptions returns the number of receptions in progress by city of their PVZ.
+	CountOpenReceptions(ctx context.Context) (map[string]int, error)
 }
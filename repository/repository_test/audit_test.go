@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBackends_Audit(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		start := time.Now().Truncate(time.Second)
+		entries := []domain.AuditEntry{
+			{ActorId: "7", Role: "moderator", Action: domain.AuditPvzOpen, TargetIds: map[string]string{"pvzId": "1"},
+				Payload: json.RawMessage(`{"city":"Москва"}`), Status: 201, IP: "10.0.0.1", CreatedAt: start},
+			{ActorId: "8", Role: "employee", Action: domain.AuditReceptionStart,
+				TargetIds: map[string]string{"pvzId": "1", "receptionId": "3"}, Status: 201, CreatedAt: start.Add(time.Minute)},
+			{ActorId: "8", Role: "employee", Action: domain.AuditProductAdd, TargetIds: map[string]string{"pvzId": "2"},
+				Status: 400, CreatedAt: start.Add(2 * time.Minute)},
+			{Action: domain.AuditUserRegister, TargetIds: map[string]string{"pvzId": "abc"}, Status: 201,
+				CreatedAt: start.Add(3 * time.Minute)},
+		}
+		for _, entry := range entries {
+			require.NoError(t, b.Audit.AddEntry(ctx, entry))
+		}
+
+		all, err := b.Audit.ListEntries(ctx, repository.AuditFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, all, 4)
+		assert.Equal(t, domain.AuditUserRegister, all[0].Action)
+		assert.Empty(t, all[0].Payload)
+		first := all[3]
+		assert.Equal(t, "7", first.ActorId)
+		assert.Equal(t, "moderator", first.Role)
+		assert.Equal(t, map[string]string{"pvzId": "1"}, first.TargetIds)
+		assert.JSONEq(t, `{"city":"Москва"}`, string(first.Payload))
+		assert.Equal(t, 201, first.Status)
+		assert.Equal(t, "10.0.0.1", first.IP)
+		assert.True(t, start.Equal(first.CreatedAt))
+
+		from, to := start.Add(time.Minute), start.Add(2*time.Minute)
+		for name, tc := range map[string]struct {
+			filter  repository.AuditFilter
+			actions []string
+		}{
+			"actor":  {repository.AuditFilter{ActorId: "8"}, []string{domain.AuditProductAdd, domain.AuditReceptionStart}},
+			"role":   {repository.AuditFilter{Role: "moderator"}, []string{domain.AuditPvzOpen}},
+			"action": {repository.AuditFilter{Action: domain.AuditReceptionStart}, []string{domain.AuditReceptionStart}},
+			"pvz":    {repository.AuditFilter{PvzId: 1}, []string{domain.AuditReceptionStart, domain.AuditPvzOpen}},
+			"period": {repository.AuditFilter{From: &from, To: &to}, []string{domain.AuditProductAdd, domain.AuditReceptionStart}},
+		} {
+			tc.filter.Limit = 10
+			got, err := b.Audit.ListEntries(ctx, tc.filter)
+			require.NoError(t, err, name)
+			var actions []string
+			for _, entry := range got {
+				actions = append(actions, entry.Action)
+			}
+			assert.Equal(t, tc.actions, actions, name)
+			count, err := b.Audit.CountEntries(ctx, tc.filter)
+			require.NoError(t, err, name)
+			assert.Equal(t, len(tc.actions), count, name)
+		}
+
+		page, err := b.Audit.ListEntries(ctx, repository.AuditFilter{Offset: 1, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, domain.AuditProductAdd, page[0].Action)
+		assert.Equal(t, domain.AuditReceptionStart, page[1].Action)
+	})
+}
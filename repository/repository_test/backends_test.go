@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"avito_test/config"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"avito_test/repository/memory"
+	"avito_test/repository/postgreSQL"
+	"avito_test/repository/sqlite"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type backend struct {
+	TxManager   repository.TxManager
+	User        repository.User
+	Pvz         repository.Pvz
+	Reception   repository.Reception
+	Product     repository.Product
+	City        repository.City
+	ProductType repository.ProductType
+	Order       repository.Order
+	Shipment    repository.Shipment
+	Outbox      repository.Outbox
+	Webhook     repository.Webhook
+	Audit       repository.Audit
+}
+
+// forEachBackend runs fn against a fresh instance of every storage backend.
+// Postgres is only included when TEST_DB_HOST points at a test database.
+func forEachBackend(t *testing.T, fn func(t *testing.T, b backend)) {
+	t.Run("memory", func(t *testing.T) {
+		storage := memory.NewStorage()
+		fn(t, backend{
+			TxManager:   memory.NewTxManager(storage),
+			User:        memory.NewUserRepo(storage),
+			Pvz:         memory.NewPvzRepo(storage),
+			Reception:   memory.NewReceptionRepo(storage),
+			Product:     memory.NewProductRepo(storage),
+			City:        memory.NewCityRepo(storage),
+			ProductType: memory.NewProductTypeRepo(storage),
+			Order:       memory.NewOrderRepo(storage),
+			Shipment:    memory.NewShipmentRepo(storage),
+			Outbox:      memory.NewOutboxRepo(storage),
+			Webhook:     memory.NewWebhookRepo(storage),
+			Audit:       memory.NewAuditRepo(storage),
+		})
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		storage, err := sqlite_connect.NewSqliteStorage(config.Sqlite{
+			Path:          filepath.Join(t.TempDir(), "pvz.db"),
+			MigrationPath: "../../pkg/sqlite_connect/migrations",
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { storage.Db.Close() })
+
+		fn(t, backend{
+			TxManager:   sqlite.NewTxManager(storage),
+			User:        sqlite.NewUserRepo(storage),
+			Pvz:         sqlite.NewPvzRepo(storage),
+			Reception:   sqlite.NewReceptionRepo(storage),
+			Product:     sqlite.NewProductRepo(storage),
+			City:        sqlite.NewCityRepo(storage),
+			ProductType: sqlite.NewProductTypeRepo(storage),
+			Order:       sqlite.NewOrderRepo(storage),
+			Shipment:    sqlite.NewShipmentRepo(storage),
+			Outbox:      sqlite.NewOutboxRepo(storage),
+			Webhook:     sqlite.NewWebhookRepo(storage),
+			Audit:       sqlite.NewAuditRepo(storage),
+		})
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		if os.Getenv("TEST_DB_HOST") == "" {
+			t.Skip("TEST_DB_HOST is not set")
+		}
+		storage, err := postgres_connect.NewPostgresStorage(config.Postgres{
+			Host:          os.Getenv("TEST_DB_HOST"),
+			Port:          5432,
+			User:          os.Getenv("TEST_DB_USER"),
+			Password:      os.Getenv("TEST_DB_PASSWORD"),
+			DBName:        os.Getenv("TEST_DB_NAME"),
+			SSLMode:       "disable",
+			MigrationPath: "../../pkg/postgres_connect/migrations",
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { storage.Db.Close() })
+
+		_, err = storage.Db.Exec(`TRUNCATE TABLE users, pvz, receptions, products, reception_products, orders, order_items, product_returns, return_shipments, return_shipment_items, pvz_type_capacity, outbox, outbox_leases, webhooks, webhook_deliveries, audit_log RESTART IDENTITY CASCADE`)
+		require.NoError(t, err)
+		_, err = storage.Db.Exec(`DELETE FROM cities WHERE name NOT IN ('Москва', 'Санкт-Петербург', 'Казань')`)
+		require.NoError(t, err)
+		_, err = storage.Db.Exec(`DELETE FROM product_types WHERE code NOT IN ('electronics', 'clothes', 'shoes')`)
+		require.NoError(t, err)
+		_, err = storage.Db.Exec(`UPDATE product_types SET storage_days = 7`)
+		require.NoError(t, err)
+
+		fn(t, backend{
+			TxManager:   postgreSQL.NewTxManager(storage),
+			User:        postgreSQL.NewUserRepo(storage),
+			Pvz:         postgreSQL.NewPvzRepo(storage),
+			Reception:   postgreSQL.NewReceptionRepo(storage),
+			Product:     postgreSQL.NewProductRepo(storage),
+			City:        postgreSQL.NewCityRepo(storage),
+			ProductType: postgreSQL.NewProductTypeRepo(storage),
+			Order:       postgreSQL.NewOrderRepo(storage),
+			Shipment:    postgreSQL.NewShipmentRepo(storage),
+			Outbox:      postgreSQL.NewOutboxRepo(storage),
+			Webhook:     postgreSQL.NewWebhookRepo(storage),
+			Audit:       postgreSQL.NewAuditRepo(storage),
+		})
+	})
+}
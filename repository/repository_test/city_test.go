@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"avito_test/repository"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBackends_Cities(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		cities, err := b.City.ListCities(ctx)
+		require.NoError(t, err)
+		assert.Len(t, cities, 3)
+
+		_, err = b.Pvz.OpenPvz(ctx, "Новосибирск")
+		assert.Error(t, err)
+
+		city, err := b.City.CreateCity(ctx, "Новосибирск")
+		require.NoError(t, err)
+		assert.True(t, city.Enabled)
+		_, err = b.City.CreateCity(ctx, "Новосибирск")
+		assert.ErrorIs(t, err, repository.ErrCityAlreadyExists)
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Новосибирск")
+		require.NoError(t, err)
+
+		city.Name = "Новосибирск-Главный"
+		city.Enabled = false
+		_, err = b.City.UpdateCity(ctx, city)
+		require.NoError(t, err)
+
+		byName, err := b.City.GetCityByName(ctx, "Новосибирск-Главный")
+		require.NoError(t, err)
+		assert.False(t, byName.Enabled)
+		renamed, err := b.Pvz.GetPvz(ctx, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "Новосибирск-Главный", renamed.City)
+
+		assert.ErrorIs(t, b.City.DeleteCity(ctx, city.Id), repository.ErrCityInUse)
+
+		unused, err := b.City.CreateCity(ctx, "Омск")
+		require.NoError(t, err)
+		require.NoError(t, b.City.DeleteCity(ctx, unused.Id))
+		_, err = b.City.GetCity(ctx, unused.Id)
+		assert.ErrorIs(t, err, repository.NotFound)
+		assert.ErrorIs(t, b.City.DeleteCity(ctx, unused.Id), repository.NotFound)
+	})
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBackends_Orders(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+		orderService := service.NewOrderService(b.Order, b.Pvz, b.TxManager)
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+
+		for _, product := range []domain.Product{
+			{Type: "shoes", Barcode: "4601234567890", OrderNumber: "ORD-1"},
+			{Type: "clothes", OrderNumber: "ORD-1"},
+			{Type: "electronics", OrderNumber: "ORD-2"},
+		} {
+			added, err := b.Product.AddProduct(ctx, product)
+			require.NoError(t, err)
+			require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, added.Id))
+		}
+
+		// Products of a reception that is still open are not in stock yet.
+		_, err = orderService.CreateOrder(ctx, pvz.Id, "ORD-1")
+		assert.ErrorIs(t, err, usecases.ErrOrderEmpty)
+
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+
+		created, err := orderService.CreateOrder(ctx, pvz.Id, "ORD-1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.OrderStatusAccepted, created.Order.Status)
+		assert.Len(t, created.Products, 2)
+
+		_, err = orderService.CreateOrder(ctx, pvz.Id, "ORD-1")
+		assert.Error(t, err)
+		_, err = orderService.CreateOrder(ctx, pvz.Id, "ORD-404")
+		assert.ErrorIs(t, err, usecases.ErrOrderEmpty)
+
+		_, err = orderService.ChangeOrderStatus(ctx, created.Order.Id, domain.OrderStatusIssued)
+		assert.ErrorIs(t, err, usecases.ErrInvalidTransition)
+
+		ready, err := orderService.ChangeOrderStatus(ctx, created.Order.Id, domain.OrderStatusReadyForPickup)
+		require.NoError(t, err)
+		assert.Equal(t, domain.OrderStatusReadyForPickup, ready.Status)
+
+		issued, err := orderService.ChangeOrderStatus(ctx, created.Order.Id, domain.OrderStatusIssued)
+		require.NoError(t, err)
+		assert.Equal(t, domain.OrderStatusIssued, issued.Status)
+
+		got, err := orderService.GetOrder(ctx, created.Order.Id)
+		require.NoError(t, err)
+		require.Len(t, got.Products, 2)
+		for _, product := range got.Products {
+			assert.NotNil(t, product.IssuedAt)
+		}
+
+		// Issued products are out of stock: the barcode is free again and not found.
+		_, err = b.Product.GetProductByBarcode(ctx, "4601234567890")
+		assert.ErrorIs(t, err, repository.NotFound)
+		_, err = b.Product.AddProduct(ctx, domain.Product{Type: "shoes", Barcode: "4601234567890"})
+		assert.NoError(t, err)
+
+		second, err := orderService.CreateOrder(ctx, pvz.Id, "ORD-2")
+		require.NoError(t, err)
+		_, err = orderService.ChangeOrderStatus(ctx, second.Order.Id, domain.OrderStatusReturned)
+		require.NoError(t, err)
+		_, err = orderService.ChangeOrderStatus(ctx, second.Order.Id, domain.OrderStatusReadyForPickup)
+		assert.ErrorIs(t, err, usecases.ErrInvalidTransition)
+
+		// A returned order frees its number and its products, which are still in stock.
+		again, err := orderService.CreateOrder(ctx, pvz.Id, "ORD-2")
+		require.NoError(t, err)
+		assert.Equal(t, second.Products, again.Products)
+
+		// Another shipment of an issued order makes a new order.
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		added, err := b.Product.AddProduct(ctx, domain.Product{Type: "clothes", OrderNumber: "ORD-1"})
+		require.NoError(t, err)
+		require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, added.Id))
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		reshipped, err := orderService.CreateOrder(ctx, pvz.Id, "ORD-1")
+		require.NoError(t, err)
+		require.Len(t, reshipped.Products, 1)
+		assert.Equal(t, added.Id, reshipped.Products[0].Id)
+
+		// Returning the products to the sender closes the orders holding them.
+		require.NoError(t, b.Order.ReturnOrdersOfProducts(ctx, []int{added.Id}))
+		got, err = orderService.GetOrder(ctx, reshipped.Order.Id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.OrderStatusReturned, got.Order.Status)
+		recreated, err := orderService.CreateOrder(ctx, pvz.Id, "ORD-1")
+		require.NoError(t, err)
+		assert.Len(t, recreated.Products, 1)
+
+		orders, err := orderService.GetOrders(ctx, pvz.Id, "")
+		require.NoError(t, err)
+		require.Len(t, orders, 5)
+		assert.Equal(t, recreated.Order.Id, orders[0].Id)
+
+		returned, err := orderService.GetOrders(ctx, pvz.Id, domain.OrderStatusReturned)
+		require.NoError(t, err)
+		require.Len(t, returned, 2)
+		assert.Equal(t, "ORD-1", returned[0].OrderNumber)
+		assert.Equal(t, "ORD-2", returned[1].OrderNumber)
+
+		_, err = orderService.GetOrder(ctx, 999)
+		assert.ErrorIs(t, err, repository.NotFound)
+		_, err = orderService.ChangeOrderStatus(ctx, 999, domain.OrderStatusIssued)
+		assert.ErrorIs(t, err, repository.NotFound)
+	})
+}
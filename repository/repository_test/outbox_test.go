@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBackends_Outbox(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		reception, err := b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		product, err := b.Product.AddProduct(ctx, domain.Product{Type: "shoes"})
+		require.NoError(t, err)
+		require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, product.Id))
+		_, err = b.Reception.DeleteProduct(ctx, pvz.Id, product.Id, domain.RemovalReasonDamaged)
+		require.NoError(t, err)
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+
+		// Failed changes leave no events behind.
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.ErrorIs(t, err, repository.ErrReceptionClosed)
+
+		events, err := b.Outbox.ListPending(ctx, 10)
+		require.NoError(t, err)
+		var types []string
+		for _, event := range events {
+			assert.Equal(t, pvz.Id, event.PvzId)
+			types = append(types, event.Type)
+		}
+		assert.Equal(t, []string{
+			domain.EventPvzOpened,
+			domain.EventReceptionStarted,
+			domain.EventProductAdded,
+			domain.EventProductRemoved,
+			domain.EventReceptionClosed,
+		}, types)
+
+		var removed domain.ProductEvent
+		require.NoError(t, json.Unmarshal(events[3].Payload, &removed))
+		assert.Equal(t, domain.ProductEvent{
+			ReceptionId: reception.Id,
+			ProductId:   product.Id,
+			Type:        "shoes",
+			Reason:      domain.RemovalReasonDamaged,
+		}, removed)
+
+		// Dispatching queues the event for every consumer and takes it off the pending list;
+		// a consumer that has it queued already keeps its delivery.
+		for _, event := range events[:3] {
+			require.NoError(t, b.Outbox.Dispatch(ctx, event.Id, []string{"a", "b"}))
+		}
+		require.NoError(t, b.Outbox.Dispatch(ctx, events[0].Id, []string{"a", "c"}))
+
+		pending, err := b.Outbox.ListPending(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+		assert.Equal(t, events[3].Id, pending[0].Id)
+
+		queued, err := b.Outbox.ListQueued(ctx, "a", 10)
+		require.NoError(t, err)
+		require.Len(t, queued, 3)
+		for i, delivery := range queued {
+			assert.Equal(t, "a", delivery.Consumer)
+			assert.Equal(t, domain.DeliveryStatusPending, delivery.Status)
+			assert.Equal(t, events[i].Id, delivery.Event.Id)
+			assert.Equal(t, events[i].Type, delivery.Event.Type)
+		}
+		assert.JSONEq(t, string(events[0].Payload), string(queued[0].Event.Payload))
+
+		// A failed attempt stays queued, a delivered or failed delivery is gone,
+		// and the queues of the consumers are independent.
+		retry := queued[0]
+		retry.Attempts = 1
+		retry.LastError = "connection refused"
+		retry.NextAttemptAt = time.Now().Add(time.Minute)
+		require.NoError(t, b.Outbox.UpdateDelivery(ctx, retry))
+		delivered := queued[1]
+		deliveredAt := time.Now()
+		delivered.Status = domain.DeliveryStatusDelivered
+		delivered.Attempts = 1
+		delivered.DeliveredAt = &deliveredAt
+		require.NoError(t, b.Outbox.UpdateDelivery(ctx, delivered))
+
+		queuedB, err := b.Outbox.ListQueued(ctx, "b", 10)
+		require.NoError(t, err)
+		require.Len(t, queuedB, 3)
+		queuedB[0].Status = domain.DeliveryStatusFailed
+		require.NoError(t, b.Outbox.UpdateDelivery(ctx, queuedB[0]))
+
+		queued, err = b.Outbox.ListQueued(ctx, "a", 10)
+		require.NoError(t, err)
+		require.Len(t, queued, 2)
+		assert.Equal(t, events[0].Id, queued[0].Event.Id)
+		assert.Equal(t, 1, queued[0].Attempts)
+		assert.Equal(t, "connection refused", queued[0].LastError)
+		assert.WithinDuration(t, retry.NextAttemptAt, queued[0].NextAttemptAt, time.Second)
+		assert.Equal(t, events[2].Id, queued[1].Event.Id)
+
+		queuedB, err = b.Outbox.ListQueued(ctx, "b", 1)
+		require.NoError(t, err)
+		require.Len(t, queuedB, 1)
+		assert.Equal(t, events[1].Id, queuedB[0].Event.Id)
+
+		queuedC, err := b.Outbox.ListQueued(ctx, "c", 10)
+		require.NoError(t, err)
+		require.Len(t, queuedC, 1)
+		assert.Equal(t, events[0].Id, queuedC[0].Event.Id)
+
+		delivered.Consumer = "unknown"
+		assert.ErrorIs(t, b.Outbox.UpdateDelivery(ctx, delivered), repository.NotFound)
+
+		// Events are listed for replay whether dispatched or not, and only those of the PVZ.
+		other, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		replay, err := b.Outbox.ListEvents(ctx, pvz.Id, events[1].Id, 2)
+		require.NoError(t, err)
+		require.Len(t, replay, 2)
+		assert.Equal(t, events[2].Id, replay[0].Id)
+		assert.Equal(t, events[3].Id, replay[1].Id)
+		assert.JSONEq(t, string(events[3].Payload), string(replay[1].Payload))
+		replay, err = b.Outbox.ListEvents(ctx, other.Id, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, replay, 1)
+		assert.Equal(t, domain.EventPvzOpened, replay[0].Type)
+	})
+}
+
+func TestBackends_OutboxOrderAndShipmentEvents(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		_, err = b.Pvz.SetCapacity(ctx, pvz.Id, 10, map[string]int{"shoes": 5})
+		require.NoError(t, err)
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		product, err := b.Product.AddProduct(ctx, domain.Product{Type: "shoes", OrderNumber: "ORD-1"})
+		require.NoError(t, err)
+		require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, product.Id))
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		before, err := b.Outbox.ListPending(ctx, 100)
+		require.NoError(t, err)
+
+		order, err := b.Order.CreateOrder(ctx, domain.Order{PvzId: pvz.Id, OrderNumber: "ORD-1", Status: domain.OrderStatusAccepted}, []int{product.Id})
+		require.NoError(t, err)
+		_, err = b.Order.UpdateOrderStatus(ctx, order.Id, domain.OrderStatusAccepted, domain.OrderStatusReadyForPickup)
+		require.NoError(t, err)
+		shipment, err := b.Shipment.CreateShipment(ctx, pvz.Id, []int{product.Id})
+		require.NoError(t, err)
+		require.NoError(t, b.Order.ReturnOrdersOfProducts(ctx, []int{product.Id}))
+
+		events, err := b.Outbox.ListPending(ctx, 100)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EventPvzCapacityChanged, before[1].Type)
+		events = events[len(before):]
+		var types []string
+		for _, event := range events {
+			assert.Equal(t, pvz.Id, event.PvzId)
+			types = append(types, event.Type)
+		}
+		require.Equal(t, []string{
+			domain.EventOrderCreated,
+			domain.EventOrderStatusChanged,
+			domain.EventShipmentCreated,
+			domain.EventOrderStatusChanged,
+		}, types)
+
+		var shipped domain.ShipmentEvent
+		require.NoError(t, json.Unmarshal(events[2].Payload, &shipped))
+		assert.Equal(t, domain.ShipmentEvent{ShipmentId: shipment.Id, ProductIds: []int{product.Id}}, shipped)
+		var returned domain.Order
+		require.NoError(t, json.Unmarshal(events[3].Payload, &returned))
+		assert.Equal(t, order.Id, returned.Id)
+		assert.Equal(t, domain.OrderStatusReturned, returned.Status)
+	})
+}
+
+func TestBackends_OutboxLease(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+		now := time.Now()
+
+		held, err := b.Outbox.Lease(ctx, "relay", "a", now, now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, held)
+
+		// Another instance waits for the lease to run out, its holder extends it.
+		held, err = b.Outbox.Lease(ctx, "relay", "b", now.Add(time.Second), now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.False(t, held)
+		held, err = b.Outbox.Lease(ctx, "relay", "a", now.Add(time.Second), now.Add(2*time.Minute))
+		require.NoError(t, err)
+		assert.True(t, held)
+		held, err = b.Outbox.Lease(ctx, "relay", "b", now.Add(time.Minute), now.Add(3*time.Minute))
+		require.NoError(t, err)
+		assert.False(t, held)
+
+		held, err = b.Outbox.Lease(ctx, "relay", "b", now.Add(2*time.Minute), now.Add(3*time.Minute))
+		require.NoError(t, err)
+		assert.True(t, held)
+		held, err = b.Outbox.Lease(ctx, "relay", "a", now.Add(2*time.Minute), now.Add(3*time.Minute))
+		require.NoError(t, err)
+		assert.False(t, held)
+
+		// Leases of other names are independent.
+		held, err = b.Outbox.Lease(ctx, "other", "a", now, now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, held)
+	})
+}
@@ -1,15 +1,19 @@
-package repository_test
+package repository
 
 import (
 	"avito_test/domain"
 	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
 	"avito_test/repository/postgreSQL"
+	"context"
 	"database/sql"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProductRepo_AddProduct(t *testing.T) {
@@ -34,7 +38,7 @@ func TestProductRepo_AddProduct(t *testing.T) {
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery(`INSERT INTO products`).
-					WithArgs("apple", sqlmock.AnyArg()).
+					WithArgs("apple", sqlmock.AnyArg(), "", "").
 					WillReturnRows(rows)
 			},
 			want: domain.Product{
@@ -49,7 +53,7 @@ func TestProductRepo_AddProduct(t *testing.T) {
 			productType: "apple",
 			mock: func() {
 				mock.ExpectQuery(`INSERT INTO products`).
-					WithArgs("apple", sqlmock.AnyArg()).
+					WithArgs("apple", sqlmock.AnyArg(), "", "").
 					WillReturnError(sql.ErrConnDone)
 			},
 			want:    domain.Product{},
@@ -61,7 +65,7 @@ func TestProductRepo_AddProduct(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.AddProduct(tt.productType)
+			got, err := repo.AddProduct(context.Background(), domain.Product{Type: tt.productType})
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -118,7 +122,7 @@ func TestProductRepo_DeleteProduct(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.DeleteProduct(tt.productId)
+			err := repo.DeleteProduct(context.Background(), tt.productId)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -144,11 +148,29 @@ func TestProductRepo_DeleteProduct_NoRowsAffected(t *testing.T) {
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(0, 0))
 
-	err = repo.DeleteProduct(1)
+	err = repo.DeleteProduct(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestProductRepo_AddProduct_DuplicateBarcode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := postgreSQL.NewProductRepo(&postgres_connect.PostgresStorage{Db: db})
+
+	mock.ExpectQuery(`INSERT INTO products`).
+		WithArgs("shoes", sqlmock.AnyArg(), "4601234567890", "").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	_, err = repo.AddProduct(context.Background(), domain.Product{Type: "shoes", Barcode: "4601234567890"})
+	assert.ErrorIs(t, err, repository.ErrBarcodeAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestProductRepo_AddProduct_ScanError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -159,10 +181,81 @@ func TestProductRepo_AddProduct_ScanError(t *testing.T) {
 	repo := postgreSQL.NewProductRepo(&postgres_connect.PostgresStorage{Db: db})
 
 	mock.ExpectQuery(`INSERT INTO products`).
-		WithArgs("apple", sqlmock.AnyArg()).
+		WithArgs("apple", sqlmock.AnyArg(), "", "").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("invalid")) // Неправильный тип для id
 
-	_, err = repo.AddProduct("apple")
+	_, err = repo.AddProduct(context.Background(), domain.Product{Type: "apple"})
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestBackends_AddProduct(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		product, err := b.Product.AddProduct(ctx, domain.Product{Type: "shoes"})
+		require.NoError(t, err)
+		assert.NotZero(t, product.Id)
+		assert.Equal(t, "shoes", product.Type)
+		assert.False(t, product.DateTime.IsZero())
+
+		second, err := b.Product.AddProduct(ctx, domain.Product{Type: "shoes"})
+		require.NoError(t, err)
+		assert.Greater(t, second.Id, product.Id)
+
+		// Deleting is idempotent.
+		require.NoError(t, b.Product.DeleteProduct(ctx, product.Id))
+		require.NoError(t, b.Product.DeleteProduct(ctx, product.Id))
+	})
+}
+
+func TestBackends_ProductBarcodes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		reception, err := b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+
+		product, err := b.Product.AddProduct(ctx, domain.Product{Type: "shoes", Barcode: "4601234567890", OrderNumber: "ORD-1"})
+		require.NoError(t, err)
+		require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, product.Id))
+
+		_, err = b.Product.AddProduct(ctx, domain.Product{Type: "clothes", Barcode: "4601234567890"})
+		assert.ErrorIs(t, err, repository.ErrBarcodeAlreadyExists)
+
+		// Products without a barcode do not collide with each other.
+		for i := 0; i < 2; i++ {
+			unlabeled, err := b.Product.AddProduct(ctx, domain.Product{Type: "clothes"})
+			require.NoError(t, err)
+			require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, unlabeled.Id))
+		}
+
+		location, err := b.Product.GetProductByBarcode(ctx, "4601234567890")
+		require.NoError(t, err)
+		assert.Equal(t, pvz.Id, location.PvzId)
+		assert.Equal(t, reception.Id, location.ReceptionId)
+		assert.Equal(t, product.Id, location.Product.Id)
+		assert.Equal(t, "обувь", location.Product.TypeName)
+		assert.Equal(t, "ORD-1", location.Product.OrderNumber)
+
+		_, err = b.Product.GetProductByBarcode(ctx, "0000000000000")
+		assert.ErrorIs(t, err, repository.NotFound)
+		_, err = b.Product.GetProductByBarcode(ctx, "")
+		assert.ErrorIs(t, err, repository.NotFound)
+
+		got, err := b.Reception.GetReception(ctx, reception.Id)
+		require.NoError(t, err)
+		require.Len(t, got.Products, 3)
+		assert.Equal(t, "4601234567890", got.Products[0].Barcode)
+		assert.Empty(t, got.Products[1].Barcode)
+
+		// Once the product leaves stock its barcode may be scanned again.
+		_, err = b.Reception.DeleteProduct(ctx, pvz.Id, product.Id, domain.RemovalReasonMisScan)
+		require.NoError(t, err)
+		require.NoError(t, b.Product.DeleteProduct(ctx, product.Id))
+		_, err = b.Product.AddProduct(ctx, domain.Product{Type: "shoes", Barcode: "4601234567890"})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBackends_ProductTypes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		books, err := b.ProductType.CreateProductType(ctx, domain.ProductType{Code: "books", Name: "книги", StorageDays: 30})
+		require.NoError(t, err)
+		_, err = b.ProductType.CreateProductType(ctx, domain.ProductType{Code: "books", Name: "литература", StorageDays: 30})
+		assert.ErrorIs(t, err, repository.ErrProductTypeAlreadyExists)
+		_, err = b.ProductType.CreateProductType(ctx, domain.ProductType{Code: "shoes2", Name: "обувь", StorageDays: 30})
+		assert.ErrorIs(t, err, repository.ErrProductTypeAlreadyExists)
+		// A code equal to a name, or a name equal to a code, would make lookups ambiguous.
+		_, err = b.ProductType.CreateProductType(ctx, domain.ProductType{Code: "книги", Name: "учебники", StorageDays: 30})
+		assert.ErrorIs(t, err, repository.ErrProductTypeAlreadyExists)
+		_, err = b.ProductType.CreateProductType(ctx, domain.ProductType{Code: "textbooks", Name: "books", StorageDays: 30})
+		assert.ErrorIs(t, err, repository.ErrProductTypeAlreadyExists)
+		toys, err := b.ProductType.CreateProductType(ctx, domain.ProductType{Code: "toys", Name: "toys", StorageDays: 30})
+		require.NoError(t, err)
+		byBoth, err := b.ProductType.GetProductType(ctx, "toys")
+		require.NoError(t, err)
+		assert.Equal(t, toys, byBoth)
+
+		byCode, err := b.ProductType.GetProductType(ctx, "books")
+		require.NoError(t, err)
+		byName, err := b.ProductType.GetProductType(ctx, "книги")
+		require.NoError(t, err)
+		assert.Equal(t, books, byCode)
+		assert.Equal(t, books, byName)
+		_, err = b.ProductType.GetProductType(ctx, "apple")
+		assert.ErrorIs(t, err, repository.NotFound)
+
+		list, err := b.ProductType.ListProductTypes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.ProductType{
+			{Code: "books", Name: "книги", StorageDays: 30},
+			{Code: "clothes", Name: "одежда", StorageDays: domain.DefaultStorageDays},
+			{Code: "electronics", Name: "электроника", StorageDays: domain.DefaultStorageDays},
+			{Code: "shoes", Name: "обувь", StorageDays: domain.DefaultStorageDays},
+			{Code: "toys", Name: "toys", StorageDays: 30},
+		}, list)
+
+		shoes, err := b.ProductType.SetStorageDays(ctx, "shoes", 14)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ProductType{Code: "shoes", Name: "обувь", StorageDays: 14}, shoes)
+		_, err = b.ProductType.SetStorageDays(ctx, "apple", 14)
+		assert.ErrorIs(t, err, repository.NotFound)
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		reception, err := b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		product, err := b.Product.AddProduct(ctx, domain.Product{Type: "books"})
+		require.NoError(t, err)
+		require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, product.Id))
+		_, err = b.Product.AddProduct(ctx, domain.Product{Type: "apple"})
+		assert.Error(t, err)
+
+		got, err := b.Reception.GetReception(ctx, reception.Id)
+		require.NoError(t, err)
+		require.Len(t, got.Products, 1)
+		assert.Equal(t, "books", got.Products[0].Type)
+		assert.Equal(t, "книги", got.Products[0].TypeName)
+	})
+}
@@ -3,12 +3,17 @@ package repository
 import (
 	"avito_test/domain"
 	"avito_test/pkg/postgres_connect"
+	"avito_test/pkg/testutils"
 	"avito_test/repository"
 	"avito_test/repository/postgreSQL"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
 )
@@ -34,9 +39,17 @@ func TestPvzRepo_OpenPvz(t *testing.T) {
 			city: "Moscow",
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectBegin()
 				mock.ExpectQuery(`INSERT INTO pvz`).
 					WithArgs("Moscow", sqlmock.AnyArg()).
 					WillReturnRows(rows)
+				mock.ExpectQuery(`INSERT INTO outbox`).
+					WithArgs(domain.EventPvzOpened, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(`SELECT pg_notify`).
+					WithArgs("pvz_events", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
 			want: domain.Pvz{
 				Id:               1,
@@ -49,9 +62,11 @@ func TestPvzRepo_OpenPvz(t *testing.T) {
 			name: "database error",
 			city: "Moscow",
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectQuery(`INSERT INTO pvz`).
 					WithArgs("Moscow", sqlmock.AnyArg()).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			want:    domain.Pvz{},
 			wantErr: true,
@@ -62,7 +77,7 @@ func TestPvzRepo_OpenPvz(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.OpenPvz(tt.city)
+			got, err := repo.OpenPvz(context.Background(), tt.city)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -98,16 +113,20 @@ func TestPvzRepo_GetPvz(t *testing.T) {
 			name:  "success",
 			pvzID: 1,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "city", "registration_date"}).
-					AddRow(1, "Moscow", time.Now())
-				mock.ExpectQuery(`SELECT id, city, registration_date`).
+				rows := sqlmock.NewRows([]string{"id", "city", "registration_date", "capacity"}).
+					AddRow(1, "Moscow", time.Now(), 100)
+				mock.ExpectQuery(`SELECT id, city, registration_date, capacity`).
 					WithArgs(1).
 					WillReturnRows(rows)
+				mock.ExpectQuery(`SELECT pvz_id, type, capacity FROM pvz_type_capacity`).
+					WillReturnRows(sqlmock.NewRows([]string{"pvz_id", "type", "capacity"}).AddRow(1, "shoes", 10))
 			},
 			want: domain.Pvz{
 				Id:               1,
 				City:             "Moscow",
 				RegistrationDate: time.Now(),
+				Capacity:         100,
+				TypeCapacity:     map[string]int{"shoes": 10},
 			},
 			wantErr: false,
 		},
@@ -139,7 +158,7 @@ func TestPvzRepo_GetPvz(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetPvz(tt.pvzID)
+			got, err := repo.GetPvz(context.Background(), tt.pvzID)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -151,9 +170,185 @@ func TestPvzRepo_GetPvz(t *testing.T) {
 				assert.Equal(t, tt.want.Id, got.Id)
 				assert.Equal(t, tt.want.City, got.City)
 				assert.NotZero(t, got.RegistrationDate)
+				assert.Equal(t, tt.want.Capacity, got.Capacity)
+				assert.Equal(t, tt.want.TypeCapacity, got.TypeCapacity)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestBackends_OpenPvz(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		assert.NotZero(t, pvz.Id)
+		assert.Equal(t, "Москва", pvz.City)
+		assert.False(t, pvz.RegistrationDate.IsZero())
+
+		got, err := b.Pvz.GetPvz(ctx, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, pvz.Id, got.Id)
+		assert.Equal(t, "Москва", got.City)
+		assert.WithinDuration(t, pvz.RegistrationDate, got.RegistrationDate, time.Second)
+		assert.Zero(t, got.Capacity)
+		assert.Empty(t, got.TypeCapacity)
+
+		_, err = b.Pvz.GetPvz(ctx, pvz.Id+100)
+		assert.ErrorIs(t, err, repository.NotFound)
+
+		events, err := b.Outbox.ListPending(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, domain.EventPvzOpened, events[0].Type)
+		assert.Equal(t, pvz.Id, events[0].PvzId)
+	})
+}
+
+func TestBackends_GetPvzListWithFilter(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		withReception, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		_, err = b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		_, err = b.Reception.StartReception(ctx, withReception.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+
+		all, err := b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		from := time.Now().Add(-time.Hour)
+		filtered, err := b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{StartDate: &from, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, filtered, 1)
+		assert.Equal(t, withReception.Id, filtered[0].Pvz.Id)
+
+		future := time.Now().Add(time.Hour)
+		empty, err := b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{StartDate: &future, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+}
+
+func TestBackends_GetPvzListPagination(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		var ids []int
+		for i := 0; i < 5; i++ {
+			pvz, err := b.Pvz.OpenPvz(ctx, "Казань")
+			require.NoError(t, err)
+			ids = append(ids, pvz.Id)
+		}
+		// Several receptions on one PVZ must not shrink the page.
+		for i := 0; i < 3; i++ {
+			_, err := b.Reception.StartReception(ctx, ids[0], domain.ReceptionKindDelivery)
+			require.NoError(t, err)
+			_, err = b.Reception.CloseReception(ctx, ids[0])
+			require.NoError(t, err)
+		}
+
+		page, err := b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, ids[0], page[0].Pvz.Id)
+		assert.Len(t, page[0].Receptions, 3)
+		assert.Equal(t, ids[1], page[1].Pvz.Id)
+
+		page, err = b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{Offset: 2, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, ids[2], page[0].Pvz.Id)
+
+		page, err = b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{AfterId: ids[3], Offset: 2, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, ids[4], page[0].Pvz.Id)
+
+		total, err := b.Pvz.CountPvzWithFilter(ctx, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 5, total)
+
+		from := time.Now().Add(-time.Hour)
+		total, err = b.Pvz.CountPvzWithFilter(ctx, &from, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+	})
+}
+
+func TestBackends_Capacity(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+		pvzService := service.NewPvzService(b.Pvz, b.City, b.ProductType, b.TxManager, testutils.Metrics())
+		productService := service.NewProductService(b.Product, b.ProductType, b.Reception, b.Pvz, b.TxManager, testutils.Metrics())
+		orderService := service.NewOrderService(b.Order, b.Pvz, b.TxManager)
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		other, err := b.Pvz.OpenPvz(ctx, "Казань")
+		require.NoError(t, err)
+
+		updated, err := pvzService.SetCapacity(ctx, pvz.Id, 3, map[string]int{"обувь": 1})
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated.Capacity)
+		assert.Equal(t, map[string]int{"shoes": 1}, updated.TypeCapacity)
+		_, err = pvzService.SetCapacity(ctx, 999, 3, nil)
+		assert.ErrorIs(t, err, repository.NotFound)
+
+		got, err := b.Pvz.GetPvz(ctx, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, updated.TypeCapacity, got.TypeCapacity)
+
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "shoes", OrderNumber: "ORD-1"}, pvz.Id)
+		require.NoError(t, err)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "shoes"}, pvz.Id)
+		assert.ErrorIs(t, err, usecases.ErrCapacityExceeded)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "clothes"}, pvz.Id)
+		require.NoError(t, err)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "electronics"}, pvz.Id)
+		require.NoError(t, err)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "electronics"}, pvz.Id)
+		assert.ErrorIs(t, err, usecases.ErrCapacityExceeded)
+
+		occupancy, err := b.Pvz.GetOccupancy(ctx, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Occupancy{Total: 3, ByType: map[string]int{"shoes": 1, "clothes": 1, "electronics": 1}}, occupancy)
+
+		// Issued products free their place.
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		order, err := orderService.CreateOrder(ctx, pvz.Id, "ORD-1")
+		require.NoError(t, err)
+		_, err = orderService.ChangeOrderStatus(ctx, order.Order.Id, domain.OrderStatusReadyForPickup)
+		require.NoError(t, err)
+		_, err = orderService.ChangeOrderStatus(ctx, order.Order.Id, domain.OrderStatusIssued)
+		require.NoError(t, err)
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "shoes"}, pvz.Id)
+		require.NoError(t, err)
+
+		page, err := pvzService.GetPvzListWithFilter(ctx, usecases.PvzFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 2)
+		assert.Equal(t, 3, page.Items[0].Occupancy.Total)
+		assert.Equal(t, 3, page.Items[0].Pvz.Capacity)
+		assert.Equal(t, map[string]int{"shoes": 1}, page.Items[0].Pvz.TypeCapacity)
+		assert.Equal(t, other.Id, page.Items[1].Pvz.Id)
+		assert.Equal(t, domain.Occupancy{ByType: map[string]int{}}, page.Items[1].Occupancy)
+
+		// Lifting the limits lets intake continue.
+		_, err = pvzService.SetCapacity(ctx, pvz.Id, 0, nil)
+		require.NoError(t, err)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "shoes"}, pvz.Id)
+		require.NoError(t, err)
+	})
+}
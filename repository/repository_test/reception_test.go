@@ -3,10 +3,23 @@ package repository
 import (
 	"avito_test/domain"
 	"avito_test/pkg/postgres_connect"
+	"avito_test/pkg/testutils"
+	"avito_test/repository"
 	"avito_test/repository/postgreSQL"
+	"avito_test/repository/prometheus"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -32,9 +45,17 @@ func TestReceptionRepo_StartReception(t *testing.T) {
 			pvzId: 1,
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectBegin()
 				mock.ExpectQuery(`INSERT INTO receptions`).
-					WithArgs(1, sqlmock.AnyArg(), "in_progress").
+					WithArgs(1, sqlmock.AnyArg(), "in_progress", domain.ReceptionKindDelivery).
 					WillReturnRows(rows)
+				mock.ExpectQuery(`INSERT INTO outbox`).
+					WithArgs(domain.EventReceptionStarted, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(`SELECT pg_notify`).
+					WithArgs("pvz_events", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
 			want: domain.Reception{
 				Id:        1,
@@ -48,9 +69,11 @@ func TestReceptionRepo_StartReception(t *testing.T) {
 			name:  "database error",
 			pvzId: 1,
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectQuery(`INSERT INTO receptions`).
-					WithArgs(1, sqlmock.AnyArg(), "in_progress").
+					WithArgs(1, sqlmock.AnyArg(), "in_progress", domain.ReceptionKindDelivery).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			want:    domain.Reception{},
 			wantErr: true,
@@ -61,7 +84,7 @@ func TestReceptionRepo_StartReception(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.StartReception(tt.pvzId)
+			got, err := repo.StartReception(context.Background(), tt.pvzId, domain.ReceptionKindDelivery)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -99,12 +122,21 @@ func TestReceptionRepo_CloseReception(t *testing.T) {
 			pvzId: 1,
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectBegin()
 				mock.ExpectQuery(`SELECT id FROM receptions`).
 					WithArgs(1).
 					WillReturnRows(rows)
-				mock.ExpectExec(`UPDATE receptions SET status`).
+				mock.ExpectQuery(`UPDATE receptions SET status`).
 					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "kind"}).
+						AddRow(time.Now(), domain.ReceptionKindDelivery))
+				mock.ExpectQuery(`INSERT INTO outbox`).
+					WithArgs(domain.EventReceptionClosed, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(`SELECT pg_notify`).
+					WithArgs("pvz_events", sqlmock.AnyArg()).
 					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
 			want: domain.Reception{
 				Id:     1,
@@ -117,9 +149,11 @@ func TestReceptionRepo_CloseReception(t *testing.T) {
 			name:  "database error",
 			pvzId: 1,
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectQuery(`SELECT id FROM receptions`).
 					WithArgs(1).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			want:    domain.Reception{},
 			wantErr: true,
@@ -130,7 +164,7 @@ func TestReceptionRepo_CloseReception(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.CloseReception(tt.pvzId)
+			got, err := repo.CloseReception(context.Background(), tt.pvzId)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -155,12 +189,12 @@ func TestReceptionRepo_GetLastReception_NotFound(t *testing.T) {
 
 	repo := postgreSQL.NewReceptionRepo(&postgres_connect.PostgresStorage{Db: db})
 
-	mock.ExpectQuery(`SELECT id, created_at,status`).
+	mock.ExpectQuery(`SELECT id, created_at, status, kind`).
 		WithArgs(1).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = repo.GetLastReception(1)
-	assert.Error(t, err)
+	_, err = repo.GetLastReception(context.Background(), 1)
+	assert.ErrorIs(t, err, repository.NotFound)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -173,9 +207,10 @@ func TestReceptionRepo_AddProduct_DBError(t *testing.T) {
 
 	repo := postgreSQL.NewReceptionRepo(&postgres_connect.PostgresStorage{Db: db})
 
-	rows := sqlmock.NewRows([]string{"id", "created_at", "status"}).
-		AddRow(1, time.Now(), "in_progress")
-	mock.ExpectQuery(`SELECT id, created_at,status`).
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "created_at", "status", "kind"}).
+		AddRow(1, time.Now(), "in_progress", domain.ReceptionKindDelivery)
+	mock.ExpectQuery(`SELECT id, created_at, status, kind`).
 		WithArgs(1).
 		WillReturnRows(rows)
 
@@ -183,7 +218,9 @@ func TestReceptionRepo_AddProduct_DBError(t *testing.T) {
 		WithArgs(1, 1).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.AddProduct(1, 1)
+	mock.ExpectRollback()
+
+	err = repo.AddProduct(context.Background(), 1, 1)
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -197,9 +234,10 @@ func TestReceptionRepo_DeleteProduct_NoProducts(t *testing.T) {
 
 	repo := postgreSQL.NewReceptionRepo(&postgres_connect.PostgresStorage{Db: db})
 
-	rows := sqlmock.NewRows([]string{"id", "created_at", "status"}).
-		AddRow(1, time.Now(), "in_progress")
-	mock.ExpectQuery(`SELECT id, created_at,status`).
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "created_at", "status", "kind"}).
+		AddRow(1, time.Now(), "in_progress", domain.ReceptionKindDelivery)
+	mock.ExpectQuery(`SELECT id, created_at, status, kind`).
 		WithArgs(1).
 		WillReturnRows(rows)
 
@@ -207,7 +245,496 @@ func TestReceptionRepo_DeleteProduct_NoProducts(t *testing.T) {
 		WithArgs(1).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = repo.DeleteProduct(1)
-	assert.Error(t, err)
+	mock.ExpectRollback()
+
+	_, err = repo.DeleteProduct(context.Background(), 1, 0, domain.RemovalReasonLastScan)
+	assert.ErrorIs(t, err, repository.ErrProductNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionRepo_DeleteProduct_ById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := postgreSQL.NewReceptionRepo(&postgres_connect.PostgresStorage{Db: db})
+
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "created_at", "status", "kind"}).
+		AddRow(3, time.Now(), "in_progress", domain.ReceptionKindDelivery)
+	mock.ExpectQuery(`SELECT id, created_at, status, kind`).
+		WithArgs(1).
+		WillReturnRows(rows)
+	mock.ExpectExec(`DELETE FROM reception_products`).
+		WithArgs(3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO product_removals`).
+		WithArgs(3, 7, domain.RemovalReasonDamaged, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"product_type"}).AddRow("shoes"))
+	mock.ExpectQuery(`INSERT INTO outbox`).
+		WithArgs(domain.EventProductRemoved, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`SELECT pg_notify`).
+		WithArgs("pvz_events", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	productId, err := repo.DeleteProduct(context.Background(), 1, 7, domain.RemovalReasonDamaged)
+	assert.NoError(t, err)
+	assert.Equal(t, "7", productId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionRepo_DeleteProduct_NotInReception(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := postgreSQL.NewReceptionRepo(&postgres_connect.PostgresStorage{Db: db})
+
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "created_at", "status", "kind"}).
+		AddRow(3, time.Now(), "in_progress", domain.ReceptionKindDelivery)
+	mock.ExpectQuery(`SELECT id, created_at, status, kind`).
+		WithArgs(1).
+		WillReturnRows(rows)
+	mock.ExpectExec(`DELETE FROM reception_products`).
+		WithArgs(3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectRollback()
+
+	_, err = repo.DeleteProduct(context.Background(), 1, 7, domain.RemovalReasonDamaged)
+	assert.ErrorIs(t, err, repository.ErrProductNotFound)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestReceptionRepo_StartReception_AlreadyInProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := postgreSQL.NewReceptionRepo(&postgres_connect.PostgresStorage{Db: db})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO receptions`).
+		WithArgs(1, sqlmock.AnyArg(), "in_progress", domain.ReceptionKindDelivery).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	mock.ExpectRollback()
+
+	_, err = repo.StartReception(context.Background(), 1, domain.ReceptionKindDelivery)
+	assert.ErrorIs(t, err, repository.ErrReceptionInProgress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionRepo_CloseReception_NothingInProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := postgreSQL.NewReceptionRepo(&postgres_connect.PostgresStorage{Db: db})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM receptions`).
+		WithArgs(1).
+		WillReturnError(sql.ErrNoRows)
+
+	mock.ExpectRollback()
+
+	_, err = repo.CloseReception(context.Background(), 1)
+	assert.ErrorIs(t, err, repository.ErrReceptionClosed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionRepo_AddProduct_ClosedConcurrently(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := postgreSQL.NewReceptionRepo(&postgres_connect.PostgresStorage{Db: db})
+
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "created_at", "status", "kind"}).
+		AddRow(1, time.Now(), "in_progress", domain.ReceptionKindDelivery)
+	mock.ExpectQuery(`SELECT id, created_at, status, kind`).
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	mock.ExpectExec(`INSERT INTO reception_products`).
+		WithArgs(1, 1).
+		WillReturnError(&pq.Error{Code: "23514"})
+
+	mock.ExpectRollback()
+
+	err = repo.AddProduct(context.Background(), 1, 1)
+	assert.ErrorIs(t, err, repository.ErrReceptionClosed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackends_StartAndCloseReception(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+
+		_, err = b.Reception.GetLastReception(ctx, pvz.Id)
+		assert.ErrorIs(t, err, repository.NotFound)
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		assert.ErrorIs(t, err, repository.ErrReceptionClosed)
+
+		started, err := b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		assert.NotZero(t, started.Id)
+		assert.Equal(t, pvz.Id, started.PvzId)
+		assert.Equal(t, "in_progress", started.Status)
+		assert.Equal(t, domain.ReceptionKindDelivery, started.Kind)
+		assert.False(t, started.StartDate.IsZero())
+
+		last, err := b.Reception.GetLastReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, started.Id, last.Id)
+		assert.Equal(t, "in_progress", last.Status)
+
+		// Nothing to delete from an empty reception, and only its own products can be deleted.
+		_, err = b.Reception.DeleteProduct(ctx, pvz.Id, 0, domain.RemovalReasonLastScan)
+		assert.ErrorIs(t, err, repository.ErrProductNotFound)
+		product, err := b.Product.AddProduct(ctx, domain.Product{Type: "shoes"})
+		require.NoError(t, err)
+		_, err = b.Reception.DeleteProduct(ctx, pvz.Id, product.Id, domain.RemovalReasonDamaged)
+		assert.ErrorIs(t, err, repository.ErrProductNotFound)
+
+		closed, err := b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, started.Id, closed.Id)
+		assert.Equal(t, pvz.Id, closed.PvzId)
+		assert.Equal(t, "closed", closed.Status)
+
+		events, err := b.Outbox.ListPending(ctx, 10)
+		require.NoError(t, err)
+		var types []string
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		assert.Equal(t, []string{domain.EventPvzOpened, domain.EventReceptionStarted, domain.EventReceptionClosed}, types)
+	})
+}
+
+func TestBackends_ReceptionLifecycle(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Казань")
+		require.NoError(t, err)
+
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		assert.ErrorIs(t, err, repository.ErrReceptionInProgress)
+
+		var ids []int
+		for _, productType := range []string{"shoes", "clothes", "electronics"} {
+			product, err := b.Product.AddProduct(ctx, domain.Product{Type: productType})
+			require.NoError(t, err)
+			require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, product.Id))
+			ids = append(ids, product.Id)
+		}
+
+		deleted, err := b.Reception.DeleteProduct(ctx, pvz.Id, 0, domain.RemovalReasonLastScan)
+		require.NoError(t, err)
+		assert.Equal(t, "3", deleted)
+
+		closed, err := b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "closed", closed.Status)
+
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		assert.ErrorIs(t, err, repository.ErrReceptionClosed)
+		assert.ErrorIs(t, b.Reception.AddProduct(ctx, pvz.Id, ids[0]), repository.ErrReceptionClosed)
+
+		list, err := b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		require.Len(t, list[0].Receptions, 1)
+		assert.Len(t, list[0].Receptions[0].Products, 2)
+	})
+}
+
+func TestBackends_CountOpenReceptions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		empty, err := b.Reception.CountOpenReceptions(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+
+		for _, city := range []string{"Москва", "Москва", "Казань"} {
+			pvz, err := b.Pvz.OpenPvz(ctx, city)
+			require.NoError(t, err)
+			_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+			require.NoError(t, err)
+			if city == "Казань" {
+				_, err = b.Reception.CloseReception(ctx, pvz.Id)
+				require.NoError(t, err)
+			}
+		}
+
+		open, err := b.Reception.CountOpenReceptions(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"Москва": 2}, open)
+
+		registry := client.NewRegistry()
+		registry.MustRegister(prometheus.NewOpenReceptions(b.Reception.CountOpenReceptions))
+		expected := `
+# HELP receptions_open Number of receptions in progress
+# TYPE receptions_open gauge
+receptions_open{city="Москва"} 2
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "receptions_open"))
+	})
+}
+
+func TestBackends_ReceptionHistory(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+
+		first, err := b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		second, err := b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+
+		var scanned []int
+		for _, productType := range []string{"electronics", "clothes", "shoes"} {
+			product, err := b.Product.AddProduct(ctx, domain.Product{Type: productType})
+			require.NoError(t, err)
+			require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, product.Id))
+			scanned = append(scanned, product.Id)
+		}
+
+		all, err := b.Reception.ListReceptions(ctx, pvz.Id, usecases.ReceptionFilter{})
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, second.Id, all[0].Id)
+		assert.Equal(t, first.Id, all[1].Id)
+
+		closed, err := b.Reception.ListReceptions(ctx, pvz.Id, usecases.ReceptionFilter{Status: "closed"})
+		require.NoError(t, err)
+		require.Len(t, closed, 1)
+		assert.Equal(t, first.Id, closed[0].Id)
+
+		future := time.Now().Add(time.Hour)
+		none, err := b.Reception.ListReceptions(ctx, pvz.Id, usecases.ReceptionFilter{StartDate: &future})
+		require.NoError(t, err)
+		assert.Empty(t, none)
+
+		detail, err := b.Reception.GetReception(ctx, second.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "in_progress", detail.Reception.Status)
+		assert.Equal(t, pvz.Id, detail.Reception.PvzId)
+		require.Len(t, detail.Products, 3)
+		for i, product := range detail.Products {
+			assert.Equal(t, scanned[i], product.Id)
+		}
+
+		_, err = b.Reception.GetReception(ctx, second.Id+100)
+		assert.ErrorIs(t, err, repository.NotFound)
+	})
+}
+
+func TestBackends_DeleteProductById(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		reception, err := b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+
+		var ids []int
+		for _, productType := range []string{"electronics", "clothes", "shoes"} {
+			product, err := b.Product.AddProduct(ctx, domain.Product{Type: productType})
+			require.NoError(t, err)
+			require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, product.Id))
+			ids = append(ids, product.Id)
+		}
+
+		deleted, err := b.Reception.DeleteProduct(ctx, pvz.Id, ids[0], domain.RemovalReasonMisScan)
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(ids[0]), deleted)
+
+		_, err = b.Reception.DeleteProduct(ctx, pvz.Id, ids[0], domain.RemovalReasonMisScan)
+		assert.ErrorIs(t, err, repository.ErrProductNotFound)
+
+		detail, err := b.Reception.GetReception(ctx, reception.Id)
+		require.NoError(t, err)
+		require.Len(t, detail.Products, 2)
+		assert.Equal(t, ids[1], detail.Products[0].Id)
+
+		removals, err := b.Reception.ListRemovals(ctx, reception.Id)
+		require.NoError(t, err)
+		require.Len(t, removals, 1)
+		assert.Equal(t, ids[0], removals[0].ProductId)
+		assert.Equal(t, "electronics", removals[0].ProductType)
+		assert.Equal(t, domain.RemovalReasonMisScan, removals[0].Reason)
+		assert.False(t, removals[0].RemovedAt.IsZero())
+	})
+}
+
+func TestBackends_CustomerReturns(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+		receptionService := service.NewReceptionService(b.Reception, b.Pvz, b.TxManager, testutils.Metrics())
+		productService := service.NewProductService(b.Product, b.ProductType, b.Reception, b.Pvz, b.TxManager, testutils.Metrics())
+		orderService := service.NewOrderService(b.Order, b.Pvz, b.TxManager)
+		pvzService := service.NewPvzService(b.Pvz, b.City, b.ProductType, b.TxManager, testutils.Metrics())
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Казань")
+		require.NoError(t, err)
+
+		_, err = receptionService.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		delivered, err := productService.AddProduct(ctx, usecases.NewProduct{Type: "shoes", OrderNumber: "ORD-1"}, pvz.Id)
+		require.NoError(t, err)
+		inStock, err := productService.AddProduct(ctx, usecases.NewProduct{Type: "clothes", OrderNumber: "ORD-2"}, pvz.Id)
+		require.NoError(t, err)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "shoes", ReturnReason: domain.ReturnReasonDefective}, pvz.Id)
+		assert.ErrorIs(t, err, usecases.ErrUnexpectedReturnDetails)
+		_, err = receptionService.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+
+		order, err := orderService.CreateOrder(ctx, pvz.Id, "ORD-1")
+		require.NoError(t, err)
+		_, err = orderService.ChangeOrderStatus(ctx, order.Order.Id, domain.OrderStatusReadyForPickup)
+		require.NoError(t, err)
+		_, err = orderService.ChangeOrderStatus(ctx, order.Order.Id, domain.OrderStatusIssued)
+		require.NoError(t, err)
+
+		started, err := receptionService.StartReception(ctx, pvz.Id, domain.ReceptionKindCustomerReturn)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReceptionKindCustomerReturn, started.Kind)
+
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "shoes", OrderNumber: "ORD-1"}, pvz.Id)
+		assert.ErrorIs(t, err, usecases.ErrReturnDetailsRequired)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{Type: "shoes", ReturnReason: domain.ReturnReasonDefective}, pvz.Id)
+		assert.ErrorIs(t, err, usecases.ErrReturnDetailsRequired)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{
+			Type: "clothes", ReturnReason: domain.ReturnReasonWrongItem, OriginalProductId: inStock.Id,
+		}, pvz.Id)
+		assert.ErrorIs(t, err, usecases.ErrInvalidReturnReference)
+
+		returned, err := productService.AddProduct(ctx, usecases.NewProduct{
+			Type: "shoes", ReturnReason: domain.ReturnReasonDefective, OriginalProductId: delivered.Id,
+		}, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "ORD-1", returned.OrderNumber)
+		_, err = productService.AddProduct(ctx, usecases.NewProduct{
+			Type: "clothes", OrderNumber: "ORD-9", ReturnReason: domain.ReturnReasonChangedMind,
+		}, pvz.Id)
+		require.NoError(t, err)
+
+		got, err := b.Reception.GetReception(ctx, started.Id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReceptionKindCustomerReturn, got.Reception.Kind)
+		require.Len(t, got.Products, 2)
+		assert.Equal(t, domain.ReturnReasonDefective, got.Products[0].ReturnReason)
+		assert.Equal(t, delivered.Id, got.Products[0].OriginalProductId)
+		assert.Equal(t, "ORD-9", got.Products[1].OrderNumber)
+		assert.Zero(t, got.Products[1].OriginalProductId)
+
+		_, err = receptionService.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+
+		// Returned items do not go back to pickup orders.
+		orderable, err := b.Order.ListOrderableProducts(ctx, pvz.Id, "ORD-1")
+		require.NoError(t, err)
+		assert.Empty(t, orderable)
+
+		page, err := pvzService.GetPvzListWithFilter(ctx, usecases.PvzFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		require.Len(t, page.Items[0].Receptions, 1)
+		assert.Equal(t, domain.ReceptionKindDelivery, page.Items[0].Receptions[0].Reception.Kind)
+		require.Len(t, page.Items[0].Returns, 1)
+		assert.Equal(t, started.Id, page.Items[0].Returns[0].Reception.Id)
+		assert.Len(t, page.Items[0].Returns[0].Products, 2)
+	})
+}
+
+func TestBackends_ConcurrentReceptions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+		receptionService := service.NewReceptionService(b.Reception, b.Pvz, b.TxManager, testutils.Metrics())
+		productService := service.NewProductService(b.Product, b.ProductType, b.Reception, b.Pvz, b.TxManager, testutils.Metrics())
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+
+		var started, added, closed int
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := receptionService.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+				if err == nil {
+					mu.Lock()
+					started++
+					mu.Unlock()
+				} else {
+					assert.ErrorIs(t, err, usecases.ErrUnclosedReception)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, started)
+
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if _, err := productService.AddProduct(ctx, usecases.NewProduct{Type: "обувь"}, pvz.Id); err == nil {
+					mu.Lock()
+					added++
+					mu.Unlock()
+				} else {
+					assert.ErrorIs(t, err, usecases.ErrAlreadyClosed)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				if _, err := receptionService.CloseReception(ctx, pvz.Id); err == nil {
+					mu.Lock()
+					closed++
+					mu.Unlock()
+				} else {
+					assert.ErrorIs(t, err, usecases.ErrAlreadyClosed)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, closed)
+
+		list, err := b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		require.Len(t, list[0].Receptions, 1)
+		assert.Len(t, list[0].Receptions[0].Products, added)
+	})
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBackends_Shipments(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+		orderService := service.NewOrderService(b.Order, b.Pvz, b.TxManager)
+		shipmentService := service.NewShipmentService(b.Shipment, b.Order, b.Pvz, b.TxManager)
+
+		_, err := b.ProductType.SetStorageDays(ctx, "electronics", 30)
+		require.NoError(t, err)
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+
+		var products []domain.Product
+		for _, product := range []domain.Product{
+			{Type: "shoes", Barcode: "4601234567890", OrderNumber: "ORD-1"},
+			{Type: "clothes"},
+			{Type: "electronics"},
+		} {
+			added, err := b.Product.AddProduct(ctx, product)
+			require.NoError(t, err)
+			require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, added.Id))
+			products = append(products, added)
+		}
+
+		// Products of a reception that is still open do not age.
+		marked, err := b.Shipment.MarkOverdue(ctx, time.Now().AddDate(0, 0, 10))
+		require.NoError(t, err)
+		assert.Zero(t, marked)
+
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		order, err := orderService.CreateOrder(ctx, pvz.Id, "ORD-1")
+		require.NoError(t, err)
+
+		marked, err = b.Shipment.MarkOverdue(ctx, time.Now().AddDate(0, 0, 5))
+		require.NoError(t, err)
+		assert.Zero(t, marked)
+
+		// Ten days later shoes and clothes are past their 7 days, electronics keep 30.
+		marked, err = b.Shipment.MarkOverdue(ctx, time.Now().AddDate(0, 0, 10))
+		require.NoError(t, err)
+		assert.Equal(t, 2, marked)
+		marked, err = b.Shipment.MarkOverdue(ctx, time.Now().AddDate(0, 0, 10))
+		require.NoError(t, err)
+		assert.Zero(t, marked)
+
+		overdue, err := shipmentService.GetOverdue(ctx, pvz.Id)
+		require.NoError(t, err)
+		require.Len(t, overdue, 2)
+		assert.Equal(t, products[0].Id, overdue[0].Id)
+		assert.Equal(t, products[1].Id, overdue[1].Id)
+		assert.NotNil(t, overdue[0].OverdueAt)
+
+		_, err = shipmentService.CreateShipment(ctx, pvz.Id, []int{products[2].Id})
+		assert.ErrorIs(t, err, usecases.ErrNotOverdue)
+
+		shipment, err := shipmentService.CreateShipment(ctx, pvz.Id, nil)
+		require.NoError(t, err)
+		assert.Equal(t, pvz.Id, shipment.Shipment.PvzId)
+		assert.Len(t, shipment.Products, 2)
+
+		_, err = shipmentService.CreateShipment(ctx, pvz.Id, nil)
+		assert.ErrorIs(t, err, usecases.ErrShipmentEmpty)
+		_, err = shipmentService.CreateShipment(ctx, pvz.Id, []int{products[0].Id})
+		assert.ErrorIs(t, err, usecases.ErrNotOverdue)
+
+		got, err := shipmentService.GetShipment(ctx, shipment.Shipment.Id)
+		require.NoError(t, err)
+		require.Len(t, got.Products, 2)
+		for _, product := range got.Products {
+			assert.NotNil(t, product.ShippedAt)
+		}
+
+		// Shipped products are out of stock and their pending order is returned.
+		_, err = b.Product.GetProductByBarcode(ctx, "4601234567890")
+		assert.ErrorIs(t, err, repository.NotFound)
+		returned, err := orderService.GetOrder(ctx, order.Order.Id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.OrderStatusReturned, returned.Order.Status)
+
+		_, err = shipmentService.GetShipment(ctx, 999)
+		assert.ErrorIs(t, err, repository.NotFound)
+		_, err = shipmentService.GetOverdue(ctx, 999)
+		assert.ErrorIs(t, err, repository.NotFound)
+	})
+}
+
+func TestBackends_ReturnedProductsGoOverdue(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+		shipmentService := service.NewShipmentService(b.Shipment, b.Order, b.Pvz, b.TxManager)
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		_, err = b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindCustomerReturn)
+		require.NoError(t, err)
+		returned, err := b.Product.AddProduct(ctx, domain.Product{
+			Type: "shoes", OrderNumber: "ORD-9", ReturnReason: domain.ReturnReasonChangedMind,
+		})
+		require.NoError(t, err)
+		require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, returned.Id))
+		_, err = b.Reception.CloseReception(ctx, pvz.Id)
+		require.NoError(t, err)
+
+		marked, err := b.Shipment.MarkOverdue(ctx, time.Now().AddDate(0, 0, 5))
+		require.NoError(t, err)
+		assert.Zero(t, marked)
+
+		// A returned item left past its storage period is shipped back like a delivered one.
+		marked, err = b.Shipment.MarkOverdue(ctx, time.Now().AddDate(0, 0, 10))
+		require.NoError(t, err)
+		assert.Equal(t, 1, marked)
+
+		overdue, err := shipmentService.GetOverdue(ctx, pvz.Id)
+		require.NoError(t, err)
+		require.Len(t, overdue, 1)
+		assert.Equal(t, returned.Id, overdue[0].Id)
+
+		shipment, err := shipmentService.CreateShipment(ctx, pvz.Id, nil)
+		require.NoError(t, err)
+		require.Len(t, shipment.Products, 1)
+		assert.Equal(t, returned.Id, shipment.Products[0].Id)
+	})
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/postgres_connect"
+	"avito_test/repository"
+	"avito_test/repository/postgreSQL"
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"testing"
+)
+
+func TestTxManager_Do(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(mock sqlmock.Sqlmock)
+		linkErr error
+		wantErr bool
+	}{
+		{
+			name: "commit",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO products`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "rollback",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO products`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			linkErr: errors.New("link failed"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			storage := &postgres_connect.PostgresStorage{Db: db}
+			txManager := postgreSQL.NewTxManager(storage)
+			repo := postgreSQL.NewProductRepo(storage)
+			tt.mock(mock)
+
+			err = txManager.Do(context.Background(), func(ctx context.Context) error {
+				if _, err := repo.AddProduct(ctx, domain.Product{Type: "shoes"}); err != nil {
+					return err
+				}
+				return tt.linkErr
+			})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExecutor_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := postgreSQL.NewPvzRepo(&postgres_connect.PostgresStorage{Db: db})
+
+	mock.ExpectQuery(`SELECT id, city, registration_date, capacity FROM pvz`).
+		WithArgs(1).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectQuery(`SELECT id, city, registration_date, capacity FROM pvz`).
+		WithArgs(1).
+		WillReturnError(errors.New("connection reset"))
+
+	// Statements outside of a span are not traced.
+	_, err = repo.GetPvz(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "Pvz.GetPvz")
+	_, err = repo.GetPvz(ctx, 1)
+	assert.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		statement := spans[0]
+		assert.Equal(t, "SELECT", statement.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), statement.Parent().SpanID())
+		assert.Equal(t, codes.Error, statement.Status().Code)
+		assert.Contains(t, statement.Attributes(), attribute.String("db.system", "postgresql"))
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackends_TxManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		linkErr error
+		wantPvz int
+	}{
+		{
+			name:    "commit",
+			wantPvz: 1,
+		},
+		{
+			name:    "rollback",
+			linkErr: errors.New("link failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, b backend) {
+				ctx := context.Background()
+
+				err := b.TxManager.Do(ctx, func(ctx context.Context) error {
+					if _, err := b.Pvz.OpenPvz(ctx, "Москва"); err != nil {
+						return err
+					}
+					return tt.linkErr
+				})
+				if tt.linkErr != nil {
+					assert.ErrorIs(t, err, tt.linkErr)
+				} else {
+					assert.NoError(t, err)
+				}
+
+				list, err := b.Pvz.GetPvzListWithFilter(ctx, repository.PvzListFilter{Limit: 10})
+				require.NoError(t, err)
+				assert.Len(t, list, tt.wantPvz)
+				events, err := b.Outbox.ListPending(ctx, 10)
+				require.NoError(t, err)
+				assert.Len(t, events, tt.wantPvz)
+			})
+		})
+	}
+}
+
+func TestBackends_TxManagerRollsBackChanges(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+		rec, err := b.Reception.StartReception(ctx, pvz.Id, domain.ReceptionKindDelivery)
+		require.NoError(t, err)
+		product, err := b.Product.AddProduct(ctx, domain.Product{Type: "shoes"})
+		require.NoError(t, err)
+		require.NoError(t, b.Reception.AddProduct(ctx, pvz.Id, product.Id))
+		before, err := b.Outbox.ListPending(ctx, 10)
+		require.NoError(t, err)
+
+		linkErr := errors.New("link failed")
+		err = b.TxManager.Do(ctx, func(ctx context.Context) error {
+			if _, err := b.Reception.DeleteProduct(ctx, pvz.Id, product.Id, domain.RemovalReasonDamaged); err != nil {
+				return err
+			}
+			if _, err := b.Reception.CloseReception(ctx, pvz.Id); err != nil {
+				return err
+			}
+			return linkErr
+		})
+		assert.ErrorIs(t, err, linkErr)
+
+		last, err := b.Reception.GetLastReception(ctx, pvz.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "in_progress", last.Status)
+		got, err := b.Reception.GetReception(ctx, rec.Id)
+		require.NoError(t, err)
+		if assert.Len(t, got.Products, 1) {
+			assert.Equal(t, product.Id, got.Products[0].Id)
+		}
+		removals, err := b.Reception.ListRemovals(ctx, rec.Id)
+		require.NoError(t, err)
+		assert.Empty(t, removals)
+		after, err := b.Outbox.ListPending(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+}
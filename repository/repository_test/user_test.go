@@ -5,11 +5,13 @@ import (
 	"avito_test/pkg/postgres_connect"
 	"avito_test/repository"
 	"avito_test/repository/postgreSQL"
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -69,7 +71,7 @@ func TestUserRepo_Register(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.Register(tt.email, tt.password, tt.role)
+			got, err := repo.Register(context.Background(), tt.email, tt.password, tt.role)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -140,7 +142,7 @@ func TestUserRepo_Login(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.Login(tt.email)
+			got, err := repo.Login(context.Background(), tt.email)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -173,7 +175,7 @@ func TestUserRepo_Register_UnknownDBError(t *testing.T) {
 		WithArgs("test@example.com", "password123", "user").
 		WillReturnError(errors.New("unknown error"))
 
-	_, err = repo.Register("test@example.com", "password123", "user")
+	_, err = repo.Register(context.Background(), "test@example.com", "password123", "user")
 	assert.Error(t, err)
 	assert.NotErrorIs(t, err, repository.ErrEmailAlreadyExists)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -194,7 +196,29 @@ func TestUserRepo_Login_ScanError(t *testing.T) {
 		WithArgs("test@example.com").
 		WillReturnRows(rows)
 
-	_, err = repo.Login("test@example.com")
+	_, err = repo.Login(context.Background(), "test@example.com")
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestBackends_UserRepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		registered, err := b.User.Register(ctx, "test@example.com", "hash", "employee")
+		require.NoError(t, err)
+		assert.NotZero(t, registered.Id)
+		assert.Equal(t, "test@example.com", registered.Email)
+		assert.Equal(t, "hash", registered.Password)
+		assert.Equal(t, "employee", registered.Role)
+		_, err = b.User.Register(ctx, "test@example.com", "hash", "employee")
+		assert.ErrorIs(t, err, repository.ErrEmailAlreadyExists)
+
+		user, err := b.User.Login(ctx, "test@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, registered, user)
+
+		_, err = b.User.Login(ctx, "missing@example.com")
+		assert.ErrorIs(t, err, repository.NotFound)
+	})
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBackends_Webhooks(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, b backend) {
+		ctx := context.Background()
+
+		pvz, err := b.Pvz.OpenPvz(ctx, "Москва")
+		require.NoError(t, err)
+
+		_, err = b.Webhook.CreateWebhook(ctx, domain.Webhook{URL: "https://partner.example/a", Secret: "a", PvzId: 999})
+		assert.ErrorIs(t, err, repository.NotFound)
+
+		all, err := b.Webhook.CreateWebhook(ctx, domain.Webhook{URL: "https://partner.example/a", Secret: "a"})
+		require.NoError(t, err)
+		filtered, err := b.Webhook.CreateWebhook(ctx, domain.Webhook{
+			URL:        "https://partner.example/b",
+			Secret:     "b",
+			EventTypes: []string{domain.EventProductAdded, domain.EventProductRemoved},
+			PvzId:      pvz.Id,
+			City:       "Москва",
+		})
+		require.NoError(t, err)
+
+		got, err := b.Webhook.GetWebhook(ctx, filtered.Id)
+		require.NoError(t, err)
+		assert.Equal(t, filtered.EventTypes, got.EventTypes)
+		assert.Equal(t, pvz.Id, got.PvzId)
+		assert.Equal(t, "Москва", got.City)
+		assert.Equal(t, "b", got.Secret)
+		webhooks, err := b.Webhook.ListWebhooks(ctx)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 2)
+		assert.Empty(t, webhooks[0].EventTypes)
+		assert.Zero(t, webhooks[0].PvzId)
+
+		now := time.Now()
+		for _, eventId := range []int{1, 2, 2} {
+			require.NoError(t, b.Webhook.AddDelivery(ctx, domain.WebhookDelivery{
+				WebhookId:     all.Id,
+				EventId:       eventId,
+				EventType:     domain.EventProductAdded,
+				Payload:       json.RawMessage(`{"id":` + strconv.Itoa(eventId) + `}`),
+				Status:        domain.DeliveryStatusPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			}))
+		}
+		require.NoError(t, b.Webhook.AddDelivery(ctx, domain.WebhookDelivery{
+			WebhookId: filtered.Id, EventId: 1, EventType: domain.EventProductAdded, Payload: json.RawMessage(`{}`),
+			Status: domain.DeliveryStatusPending, NextAttemptAt: now, CreatedAt: now,
+		}))
+
+		// The same event is queued once per webhook.
+		deliveries, err := b.Webhook.ListDeliveries(ctx, all.Id, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, 2, deliveries[0].EventId)
+		assert.JSONEq(t, `{"id":2}`, string(deliveries[0].Payload))
+
+		due, err := b.Webhook.ClaimDueDeliveries(ctx, now.Add(time.Second), now.Add(time.Hour), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 3)
+		assert.Equal(t, all.Id, due[0].WebhookId)
+		assert.WithinDuration(t, now.Add(time.Hour), due[0].NextAttemptAt, time.Second)
+
+		// Claimed deliveries are not due for anyone else until the claim runs out.
+		claimed, err := b.Webhook.ClaimDueDeliveries(ctx, now.Add(time.Second), now.Add(time.Hour), 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		retry := due[0]
+		retry.Attempts = 1
+		retry.LastError = "unexpected status 503"
+		retry.ResponseStatus = 503
+		retry.NextAttemptAt = now.Add(time.Minute)
+		require.NoError(t, b.Webhook.UpdateDelivery(ctx, retry))
+
+		delivered := due[1]
+		deliveredAt := now.Add(time.Second)
+		delivered.Attempts = 1
+		delivered.Status = domain.DeliveryStatusDelivered
+		delivered.DeliveredAt = &deliveredAt
+		require.NoError(t, b.Webhook.UpdateDelivery(ctx, delivered))
+
+		due, err = b.Webhook.ClaimDueDeliveries(ctx, now.Add(2*time.Hour), now.Add(3*time.Hour), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		assert.Equal(t, retry.Id, due[0].Id)
+		assert.Equal(t, filtered.Id, due[1].WebhookId)
+
+		stored, err := b.Webhook.GetDelivery(ctx, retry.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "unexpected status 503", stored.LastError)
+		assert.Equal(t, 503, stored.ResponseStatus)
+		assert.Nil(t, stored.DeliveredAt)
+		stored, err = b.Webhook.GetDelivery(ctx, delivered.Id)
+		require.NoError(t, err)
+		assert.NotNil(t, stored.DeliveredAt)
+
+		require.NoError(t, b.Webhook.DeleteWebhook(ctx, all.Id))
+		assert.ErrorIs(t, b.Webhook.DeleteWebhook(ctx, all.Id), repository.NotFound)
+		_, err = b.Webhook.GetDelivery(ctx, retry.Id)
+		assert.ErrorIs(t, err, repository.NotFound)
+	})
+}
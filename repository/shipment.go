@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"context"
+	"time"
+)
+
+type Shipment interface {
+	// MarkOverdue stamps overdue_at on products of closed receptions, deliveries and
+	// customer returns alike, that are still in stock after the storage period of
+	// their type, counted from added_at, and returns how many were marked.
+	MarkOverdue(ctx context.Context, now time.Time) (int, error)
+	// ListOverdueProducts returns the overdue products in stock at the PVZ, oldest first.
+	ListOverdueProducts(ctx context.Context, pvzId int) ([]domain.Product, error)
+	// CreateShipment takes the products out of stock into a new return shipment.
+	// It fails with ErrProductNotInStock if one of them has already left.
+	CreateShipment(ctx context.Context, pvzId int, productIds []int) (domain.ReturnShipment, error)
+	GetShipment(ctx context.Context, shipmentId int) (domain.ReturnShipmentWithProducts, error)
+}
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+type AuditRepo struct {
+	audit *sqlite_connect.SqliteStorage
+}
+
+func NewAuditRepo(audit *sqlite_connect.SqliteStorage) *AuditRepo {
+	return &AuditRepo{audit: audit}
+}
+
+func (a *AuditRepo) AddEntry(ctx context.Context, entry domain.AuditEntry) error {
+	targetIds := []byte("{}")
+	if len(entry.TargetIds) > 0 {
+		var err error
+		if targetIds, err = json.Marshal(entry.TargetIds); err != nil {
+			return err
+		}
+	}
+	var payload *string
+	if len(entry.Payload) > 0 {
+		s := string(entry.Payload)
+		payload = &s
+	}
+	// A target that is not a number cannot name a PVZ and is only kept in target_ids.
+	pvzId, _ := strconv.Atoi(entry.TargetIds[domain.AuditTargetPvz])
+
+	_, err := executor(ctx, a.audit).ExecContext(ctx, `
+		INSERT INTO audit_log (actor_id, role, action, target_ids, pvz_id, payload, status, ip, created_at)
+		VALUES (?, ?, ?, ?, NULLIF(?, 0), ?, ?, ?, ?)`,
+		entry.ActorId, entry.Role, entry.Action, string(targetIds), pvzId, payload, entry.Status, entry.IP,
+		entry.CreatedAt.UTC())
+	return err
+}
+
+func (a *AuditRepo) ListEntries(ctx context.Context, filter repository.AuditFilter) ([]domain.AuditEntry, error) {
+	cond, args := auditCondition(filter)
+	rows, err := executor(ctx, a.audit).QueryContext(ctx, `
+		SELECT id, actor_id, role, action, target_ids, payload, status, ip, created_at
+		FROM audit_log WHERE 1 = 1`+cond+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0)
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var targetIds string
+		var payload *string
+		if err := rows.Scan(&entry.Id, &entry.ActorId, &entry.Role, &entry.Action, &targetIds, &payload,
+			&entry.Status, &entry.IP, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(targetIds), &entry.TargetIds); err != nil {
+			return nil, err
+		}
+		if len(entry.TargetIds) == 0 {
+			entry.TargetIds = nil
+		}
+		if payload != nil {
+			entry.Payload = json.RawMessage(*payload)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (a *AuditRepo) CountEntries(ctx context.Context, filter repository.AuditFilter) (int, error) {
+	cond, args := auditCondition(filter)
+	var count int
+	err := executor(ctx, a.audit).QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log WHERE 1 = 1`+cond, args...).
+		Scan(&count)
+	return count, err
+}
+
+func auditCondition(filter repository.AuditFilter) (string, []interface{}) {
+	var cond string
+	var args []interface{}
+	if filter.ActorId != "" {
+		cond += " AND actor_id = ?"
+		args = append(args, filter.ActorId)
+	}
+	if filter.Role != "" {
+		cond += " AND role = ?"
+		args = append(args, filter.Role)
+	}
+	if filter.Action != "" {
+		cond += " AND action = ?"
+		args = append(args, filter.Action)
+	}
+	if filter.PvzId != 0 {
+		cond += " AND pvz_id = ?"
+		args = append(args, filter.PvzId)
+	}
+	if filter.From != nil {
+		cond += " AND created_at >= ?"
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		cond += " AND created_at <= ?"
+		args = append(args, filter.To.UTC())
+	}
+	return cond, args
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type CityRepo struct {
+	cities *sqlite_connect.SqliteStorage
+}
+
+func NewCityRepo(cities *sqlite_connect.SqliteStorage) *CityRepo {
+	return &CityRepo{cities: cities}
+}
+
+func (c *CityRepo) CreateCity(ctx context.Context, name string) (domain.City, error) {
+	city := domain.City{Name: name, Enabled: true}
+	err := executor(ctx, c.cities).QueryRowContext(ctx,
+		`INSERT INTO cities (name, enabled) VALUES (?, ?) RETURNING id`,
+		city.Name, city.Enabled,
+	).Scan(&city.Id)
+	if sqliteErrorCode(err) == uniqueViolation {
+		return domain.City{}, repository.ErrCityAlreadyExists
+	} else if err != nil {
+		return domain.City{}, err
+	}
+	return city, nil
+}
+
+func (c *CityRepo) GetCity(ctx context.Context, cityId int) (domain.City, error) {
+	return c.getCity(ctx, `SELECT id, name, enabled FROM cities WHERE id = ?`, cityId)
+}
+
+func (c *CityRepo) GetCityByName(ctx context.Context, name string) (domain.City, error) {
+	return c.getCity(ctx, `SELECT id, name, enabled FROM cities WHERE name = ?`, name)
+}
+
+func (c *CityRepo) getCity(ctx context.Context, query string, arg interface{}) (domain.City, error) {
+	var city domain.City
+	err := executor(ctx, c.cities).QueryRowContext(ctx, query, arg).Scan(&city.Id, &city.Name, &city.Enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.City{}, repository.NotFound
+	} else if err != nil {
+		return domain.City{}, err
+	}
+	return city, nil
+}
+
+func (c *CityRepo) ListCities(ctx context.Context) ([]domain.City, error) {
+	rows, err := executor(ctx, c.cities).QueryContext(ctx, `SELECT id, name, enabled FROM cities ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cities := make([]domain.City, 0)
+	for rows.Next() {
+		var city domain.City
+		if err := rows.Scan(&city.Id, &city.Name, &city.Enabled); err != nil {
+			return nil, err
+		}
+		cities = append(cities, city)
+	}
+	return cities, rows.Err()
+}
+
+func (c *CityRepo) UpdateCity(ctx context.Context, city domain.City) (domain.City, error) {
+	res, err := executor(ctx, c.cities).ExecContext(ctx,
+		`UPDATE cities SET name = ?, enabled = ? WHERE id = ?`,
+		city.Name, city.Enabled, city.Id,
+	)
+	if sqliteErrorCode(err) == uniqueViolation {
+		return domain.City{}, repository.ErrCityAlreadyExists
+	} else if err != nil {
+		return domain.City{}, err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return domain.City{}, err
+	} else if affected == 0 {
+		return domain.City{}, repository.NotFound
+	}
+	return city, nil
+}
+
+func (c *CityRepo) DeleteCity(ctx context.Context, cityId int) error {
+	res, err := executor(ctx, c.cities).ExecContext(ctx, `DELETE FROM cities WHERE id = ?`, cityId)
+	if sqliteErrorCode(err) == foreignKeyViolation {
+		return repository.ErrCityInUse
+	} else if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return repository.NotFound
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package sqlite
+
+import (
+	"errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	uniqueViolation     = sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	primaryKeyViolation = sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	triggerViolation    = sqlite3.SQLITE_CONSTRAINT_TRIGGER
+	foreignKeyViolation = sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+)
+
+func sqliteErrorCode(err error) int {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code()
+	}
+	return 0
+}
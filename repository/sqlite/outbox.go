@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type OutboxRepo struct {
+	outbox *sqlite_connect.SqliteStorage
+}
+
+func NewOutboxRepo(outbox *sqlite_connect.SqliteStorage) *OutboxRepo {
+	return &OutboxRepo{outbox: outbox}
+}
+
+func (o *OutboxRepo) Lease(ctx context.Context, name string, owner string, now time.Time, until time.Time) (bool, error) {
+	err := executor(ctx, o.outbox).QueryRowContext(ctx, `
+		INSERT INTO outbox_leases (name, owner, leased_until) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, leased_until = excluded.leased_until
+		WHERE outbox_leases.owner = excluded.owner OR outbox_leases.leased_until <= ?
+		RETURNING name`, name, owner, until.UTC(), now.UTC()).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (o *OutboxRepo) ListPending(ctx context.Context, limit int) ([]domain.Event, error) {
+	rows, err := executor(ctx, o.outbox).QueryContext(ctx, `
+		SELECT id, type, pvz_id, payload, created_at
+		FROM outbox
+		WHERE dispatched_at IS NULL
+		ORDER BY id
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (o *OutboxRepo) ListEvents(ctx context.Context, pvzId int, afterId int, limit int) ([]domain.Event, error) {
+	rows, err := executor(ctx, o.outbox).QueryContext(ctx, `
+		SELECT id, type, pvz_id, payload, created_at
+		FROM outbox
+		WHERE pvz_id = ? AND id > ?
+		ORDER BY id
+		LIMIT ?`, pvzId, afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+// scanEvents reads and closes the rows.
+func scanEvents(rows *sql.Rows) ([]domain.Event, error) {
+	defer rows.Close()
+
+	events := make([]domain.Event, 0)
+	for rows.Next() {
+		var event domain.Event
+		var payload []byte
+		if err := rows.Scan(&event.Id, &event.Type, &event.PvzId, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// Dispatch queues the event before marking it dispatched, so a dispatch cut short
+// is repeated in full.
+func (o *OutboxRepo) Dispatch(ctx context.Context, eventId int, consumers []string) error {
+	now := time.Now().UTC()
+	for _, consumer := range consumers {
+		_, err := executor(ctx, o.outbox).ExecContext(ctx, `
+			INSERT INTO outbox_deliveries (consumer, event_id, next_attempt_at) VALUES (?, ?, ?)
+			ON CONFLICT (consumer, event_id) DO NOTHING`, consumer, eventId, now)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := executor(ctx, o.outbox).ExecContext(ctx, `UPDATE outbox SET dispatched_at = ? WHERE id = ?`, now, eventId)
+	return err
+}
+
+func (o *OutboxRepo) ListQueued(ctx context.Context, consumer string, limit int) ([]domain.OutboxDelivery, error) {
+	rows, err := executor(ctx, o.outbox).QueryContext(ctx, `
+		SELECT d.consumer, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at,
+		       o.id, o.type, o.pvz_id, o.payload, o.created_at
+		FROM outbox_deliveries d
+		JOIN outbox o ON o.id = d.event_id
+		WHERE d.consumer = ? AND d.status = ?
+		ORDER BY d.event_id
+		LIMIT ?`, consumer, domain.DeliveryStatusPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.OutboxDelivery, 0)
+	for rows.Next() {
+		var delivery domain.OutboxDelivery
+		var payload []byte
+		err := rows.Scan(&delivery.Consumer, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt,
+			&delivery.LastError, &delivery.DeliveredAt, &delivery.Event.Id, &delivery.Event.Type,
+			&delivery.Event.PvzId, &payload, &delivery.Event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Event.Payload = payload
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (o *OutboxRepo) UpdateDelivery(ctx context.Context, delivery domain.OutboxDelivery) error {
+	var deliveredAt *time.Time
+	if delivery.DeliveredAt != nil {
+		utc := delivery.DeliveredAt.UTC()
+		deliveredAt = &utc
+	}
+
+	res, err := executor(ctx, o.outbox).ExecContext(ctx, `
+		UPDATE outbox_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, delivered_at = ?
+		WHERE consumer = ? AND event_id = ?`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt.UTC(), delivery.LastError, deliveredAt,
+		delivery.Consumer, delivery.Event.Id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return repository.NotFound
+	}
+	return nil
+}
+
+// addEvent records an event in the outbox; callers run it in the transaction of the change it describes.
+func addEvent(ctx context.Context, storage *sqlite_connect.SqliteStorage, eventType string, pvzId int, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = executor(ctx, storage).ExecContext(ctx,
+		`INSERT INTO outbox (type, pvz_id, payload, created_at) VALUES (?, ?, ?, ?)`,
+		eventType, pvzId, string(raw), time.Now().UTC())
+	return err
+}
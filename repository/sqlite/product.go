@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type ProductRepo struct {
+	products *sqlite_connect.SqliteStorage
+}
+
+func NewProductRepo(products *sqlite_connect.SqliteStorage) *ProductRepo {
+	return &ProductRepo{products: products}
+}
+
+func (r *ProductRepo) AddProduct(ctx context.Context, product domain.Product) (domain.Product, error) {
+	product.DateTime = time.Now().UTC()
+	err := executor(ctx, r.products).QueryRowContext(ctx,
+		`INSERT INTO products (type, added_at, barcode, order_number)
+		VALUES (?, ?, NULLIF(?, ''), NULLIF(?, '')) RETURNING id`,
+		product.Type, product.DateTime, product.Barcode, product.OrderNumber,
+	).Scan(&product.Id)
+	if sqliteErrorCode(err) == uniqueViolation {
+		return domain.Product{}, repository.ErrBarcodeAlreadyExists
+	} else if err != nil {
+		return domain.Product{}, err
+	}
+
+	if product.ReturnReason != "" {
+		_, err = executor(ctx, r.products).ExecContext(ctx,
+			`INSERT INTO product_returns (product_id, original_product_id, reason) VALUES (?, NULLIF(?, 0), ?)`,
+			product.Id, product.OriginalProductId, product.ReturnReason,
+		)
+		if err != nil {
+			return domain.Product{}, err
+		}
+	}
+	return product, nil
+}
+
+func (r *ProductRepo) GetProduct(ctx context.Context, productId int) (domain.Product, error) {
+	var product domain.Product
+	err := executor(ctx, r.products).QueryRowContext(ctx, `
+		SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), COALESCE(p.order_number, ''), p.issued_at,
+		       COALESCE(pr.reason, ''), COALESCE(pr.original_product_id, 0)
+		FROM products p
+		JOIN product_types pt ON pt.code = p.type
+		LEFT JOIN product_returns pr ON pr.product_id = p.id
+		WHERE p.id = ?`, productId,
+	).Scan(&product.Id, &product.Type, &product.TypeName, &product.DateTime, &product.Barcode, &product.OrderNumber,
+		&product.IssuedAt, &product.ReturnReason, &product.OriginalProductId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Product{}, repository.NotFound
+	} else if err != nil {
+		return domain.Product{}, err
+	}
+	return product, nil
+}
+
+func (r *ProductRepo) DeleteProduct(ctx context.Context, productId int) error {
+	_, err := executor(ctx, r.products).ExecContext(ctx, `DELETE FROM products WHERE id = ?`, productId)
+	return err
+}
+
+func (r *ProductRepo) GetProductByBarcode(ctx context.Context, barcode string) (domain.ProductLocation, error) {
+	var location domain.ProductLocation
+	err := executor(ctx, r.products).QueryRowContext(ctx, `
+		SELECT p.id, p.type, pt.name, p.added_at, p.barcode, COALESCE(p.order_number, ''),
+		       COALESCE(pr.reason, ''), COALESCE(pr.original_product_id, 0), r.id, r.pvz_id
+		FROM products p
+		JOIN product_types pt ON pt.code = p.type
+		JOIN reception_products rp ON rp.product_id = p.id
+		JOIN receptions r ON r.id = rp.reception_id
+		LEFT JOIN product_returns pr ON pr.product_id = p.id
+		WHERE p.barcode = ? AND p.issued_at IS NULL AND p.shipped_at IS NULL`, barcode,
+	).Scan(&location.Product.Id, &location.Product.Type, &location.Product.TypeName, &location.Product.DateTime,
+		&location.Product.Barcode, &location.Product.OrderNumber, &location.Product.ReturnReason,
+		&location.Product.OriginalProductId, &location.ReceptionId, &location.PvzId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ProductLocation{}, repository.NotFound
+	} else if err != nil {
+		return domain.ProductLocation{}, err
+	}
+	return location, nil
+}
@@ -0,0 +1,363 @@
+package sqlite
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+type PvzRepo struct {
+	pvz *sqlite_connect.SqliteStorage
+}
+
+func NewPvzRepo(pvz *sqlite_connect.SqliteStorage) *PvzRepo {
+	return &PvzRepo{pvz: pvz}
+}
+
+func (p *PvzRepo) OpenPvz(ctx context.Context, city string) (pvz domain.Pvz, err error) {
+	pvz = domain.Pvz{City: city, RegistrationDate: time.Now().UTC()}
+	err = inTx(ctx, p.pvz, func(ctx context.Context) error {
+		err := executor(ctx, p.pvz).QueryRowContext(ctx,
+			`INSERT INTO pvz (city, registration_date) VALUES (?, ?) RETURNING id`,
+			city, pvz.RegistrationDate,
+		).Scan(&pvz.Id)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, p.pvz, domain.EventPvzOpened, pvz.Id, pvz)
+	})
+	if err != nil {
+		return domain.Pvz{}, err
+	}
+	return pvz, nil
+}
+
+func (p *PvzRepo) GetPvz(ctx context.Context, pvzID int) (domain.Pvz, error) {
+	row := executor(ctx, p.pvz).QueryRowContext(ctx, `SELECT id, city, registration_date, capacity FROM pvz WHERE id = ?`, pvzID)
+
+	var pvz domain.Pvz
+	err := row.Scan(&pvz.Id, &pvz.City, &pvz.RegistrationDate, &pvz.Capacity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Pvz{}, repository.NotFound
+	} else if err != nil {
+		return domain.Pvz{}, err
+	}
+
+	typeCapacities, err := p.getTypeCapacitiesMap(ctx, []int{pvz.Id})
+	if err != nil {
+		return domain.Pvz{}, err
+	}
+	pvz.TypeCapacity = typeCapacities[pvz.Id]
+	return pvz, nil
+}
+
+func (p *PvzRepo) SetCapacity(ctx context.Context, pvzId int, capacity int, typeCapacity map[string]int) (pvz domain.Pvz, err error) {
+	pvz = domain.Pvz{Capacity: capacity}
+	if len(typeCapacity) > 0 {
+		pvz.TypeCapacity = typeCapacity
+	}
+	err = inTx(ctx, p.pvz, func(ctx context.Context) error {
+		err := executor(ctx, p.pvz).QueryRowContext(ctx,
+			`UPDATE pvz SET capacity = ? WHERE id = ? RETURNING id, city, registration_date`,
+			capacity, pvzId,
+		).Scan(&pvz.Id, &pvz.City, &pvz.RegistrationDate)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.NotFound
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := executor(ctx, p.pvz).ExecContext(ctx, `DELETE FROM pvz_type_capacity WHERE pvz_id = ?`, pvzId); err != nil {
+			return err
+		}
+		for productType, limit := range typeCapacity {
+			_, err := executor(ctx, p.pvz).ExecContext(ctx,
+				`INSERT INTO pvz_type_capacity (pvz_id, type, capacity) VALUES (?, ?, ?)`,
+				pvzId, productType, limit)
+			if err != nil {
+				return err
+			}
+		}
+		return addEvent(ctx, p.pvz, domain.EventPvzCapacityChanged, pvzId, pvz)
+	})
+	if err != nil {
+		return domain.Pvz{}, err
+	}
+	return pvz, nil
+}
+
+func (p *PvzRepo) GetOccupancy(ctx context.Context, pvzId int) (domain.Occupancy, error) {
+	occupancies, err := p.getOccupancyMap(ctx, []int{pvzId})
+	if err != nil {
+		return domain.Occupancy{}, err
+	}
+	return occupancies[pvzId], nil
+}
+
+// GetPvzListWithFilter pages over PVZs first and joins receptions afterwards, so a page
+// always holds whole PVZs. Timestamps are compared as text, so the bounds are converted
+// to UTC to match the format the rows were written in.
+func (p *PvzRepo) GetPvzListWithFilter(ctx context.Context, filter repository.PvzListFilter) ([]usecases.PvzWithReceptions, error) {
+	receptionCond, receptionArgs := receptionDateCondition(filter.StartDate, filter.EndDate)
+
+	var args []interface{}
+	var where []string
+	if receptionCond != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM receptions r WHERE r.pvz_id = p.id"+receptionCond+")")
+		args = append(args, receptionArgs...)
+	}
+	offset := filter.Offset
+	if filter.AfterId > 0 {
+		offset = 0
+		where = append(where, "p.id > ?")
+		args = append(args, filter.AfterId)
+	}
+
+	page := `SELECT p.id, p.city, p.registration_date, p.capacity FROM pvz p`
+	if len(where) > 0 {
+		page += " WHERE " + strings.Join(where, " AND ")
+	}
+	page += " ORDER BY p.id LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, offset)
+
+	query := `
+        WITH page AS (` + page + `)
+        SELECT p.id, p.city, p.registration_date, p.capacity,
+               r.id, r.created_at, r.status, r.kind
+        FROM page p
+        LEFT JOIN receptions r ON p.id = r.pvz_id` + receptionCond + `
+        ORDER BY p.id, r.created_at DESC, r.id DESC
+    `
+	args = append(args, receptionArgs...)
+
+	rows, err := executor(ctx, p.pvz).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []usecases.PvzWithReceptions
+	receptionIDs := make([]int, 0)
+
+	for rows.Next() {
+		var pvz domain.Pvz
+		var receptionId sql.NullInt64
+		var createdAt sql.NullTime
+		var status sql.NullString
+		var kind sql.NullString
+
+		err := rows.Scan(
+			&pvz.Id, &pvz.City, &pvz.RegistrationDate, &pvz.Capacity,
+			&receptionId, &createdAt, &status, &kind,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(result) == 0 || result[len(result)-1].Pvz.Id != pvz.Id {
+			result = append(result, usecases.PvzWithReceptions{
+				Pvz:        pvz,
+				Receptions: []domain.ReceptionWithProducts{},
+			})
+		}
+
+		if receptionId.Valid {
+			current := &result[len(result)-1]
+			current.Receptions = append(current.Receptions, domain.ReceptionWithProducts{
+				Reception: domain.Reception{
+					Id:        int(receptionId.Int64),
+					PvzId:     pvz.Id,
+					StartDate: createdAt.Time,
+					Status:    status.String,
+					Kind:      kind.String,
+				},
+			})
+			receptionIDs = append(receptionIDs, int(receptionId.Int64))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	productsMap, err := p.getProductsForReceptionsMap(ctx, receptionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pvzIDs := make([]int, len(result))
+	for i := range result {
+		pvzIDs[i] = result[i].Pvz.Id
+	}
+	typeCapacities, err := p.getTypeCapacitiesMap(ctx, pvzIDs)
+	if err != nil {
+		return nil, err
+	}
+	occupancies, err := p.getOccupancyMap(ctx, pvzIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		result[i].Pvz.TypeCapacity = typeCapacities[result[i].Pvz.Id]
+		result[i].Occupancy = occupancies[result[i].Pvz.Id]
+		for j := range result[i].Receptions {
+			rid := result[i].Receptions[j].Reception.Id
+			result[i].Receptions[j].Products = productsMap[rid]
+		}
+	}
+
+	return result, nil
+}
+
+func (p *PvzRepo) CountPvzWithFilter(ctx context.Context, startDate, endDate *time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM pvz p`
+	cond, args := receptionDateCondition(startDate, endDate)
+	if cond != "" {
+		query += " WHERE EXISTS (SELECT 1 FROM receptions r WHERE r.pvz_id = p.id" + cond + ")"
+	}
+
+	var total int
+	err := executor(ctx, p.pvz).QueryRowContext(ctx, query, args...).Scan(&total)
+	return total, err
+}
+
+// receptionDateCondition returns the " AND ..." clause restricting receptions aliased r
+// to the date range together with its parameters.
+func receptionDateCondition(startDate, endDate *time.Time) (string, []interface{}) {
+	var cond string
+	var args []interface{}
+	if startDate != nil {
+		cond += " AND r.created_at >= ?"
+		args = append(args, startDate.UTC())
+	}
+	if endDate != nil {
+		cond += " AND r.created_at <= ?"
+		args = append(args, endDate.UTC())
+	}
+	return cond, args
+}
+
+func (p *PvzRepo) getProductsForReceptionsMap(ctx context.Context, receptionIDs []int) (map[int][]domain.Product, error) {
+	if len(receptionIDs) == 0 {
+		return make(map[int][]domain.Product), nil
+	}
+
+	args := make([]interface{}, len(receptionIDs))
+	for i, id := range receptionIDs {
+		args[i] = id
+	}
+
+	query := `
+        SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), COALESCE(p.order_number, ''), p.issued_at,
+               COALESCE(pr.reason, ''), COALESCE(pr.original_product_id, 0), rp.reception_id
+        FROM products p
+        JOIN product_types pt ON pt.code = p.type
+        JOIN reception_products rp ON p.id = rp.product_id
+        LEFT JOIN product_returns pr ON pr.product_id = p.id
+        WHERE rp.reception_id IN (?` + strings.Repeat(", ?", len(args)-1) + `)
+        ORDER BY p.id
+    `
+
+	rows, err := executor(ctx, p.pvz).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int][]domain.Product)
+
+	for rows.Next() {
+		var product domain.Product
+		var receptionID int
+		if err := rows.Scan(&product.Id, &product.Type, &product.TypeName, &product.DateTime, &product.Barcode, &product.OrderNumber, &product.IssuedAt,
+			&product.ReturnReason, &product.OriginalProductId, &receptionID); err != nil {
+			return nil, err
+		}
+		result[receptionID] = append(result[receptionID], product)
+	}
+
+	return result, rows.Err()
+}
+
+// inPlaceholders returns the "?, ?, ..." list and arguments for an IN clause over ids.
+func inPlaceholders(ids []int) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return "?" + strings.Repeat(", ?", len(ids)-1), args
+}
+
+func (p *PvzRepo) getTypeCapacitiesMap(ctx context.Context, pvzIDs []int) (map[int]map[string]int, error) {
+	result := make(map[int]map[string]int)
+	if len(pvzIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders, args := inPlaceholders(pvzIDs)
+	rows, err := executor(ctx, p.pvz).QueryContext(ctx,
+		`SELECT pvz_id, type, capacity FROM pvz_type_capacity WHERE pvz_id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pvzID, capacity int
+		var productType string
+		if err := rows.Scan(&pvzID, &productType, &capacity); err != nil {
+			return nil, err
+		}
+		if result[pvzID] == nil {
+			result[pvzID] = make(map[string]int)
+		}
+		result[pvzID][productType] = capacity
+	}
+	return result, rows.Err()
+}
+
+// getOccupancyMap counts the products in stock per PVZ and type, whatever the
+// status of the reception that brought them in.
+func (p *PvzRepo) getOccupancyMap(ctx context.Context, pvzIDs []int) (map[int]domain.Occupancy, error) {
+	result := make(map[int]domain.Occupancy, len(pvzIDs))
+	for _, id := range pvzIDs {
+		result[id] = domain.Occupancy{ByType: make(map[string]int)}
+	}
+	if len(pvzIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders, args := inPlaceholders(pvzIDs)
+	rows, err := executor(ctx, p.pvz).QueryContext(ctx, `
+        SELECT r.pvz_id, p.type, COUNT(*)
+        FROM products p
+        JOIN reception_products rp ON rp.product_id = p.id
+        JOIN receptions r ON r.id = rp.reception_id
+        WHERE r.pvz_id IN (`+placeholders+`) AND p.issued_at IS NULL AND p.shipped_at IS NULL
+        GROUP BY r.pvz_id, p.type
+    `, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pvzID, count int
+		var productType string
+		if err := rows.Scan(&pvzID, &productType, &count); err != nil {
+			return nil, err
+		}
+		occupancy := result[pvzID]
+		occupancy.Total += count
+		occupancy.ByType[productType] = count
+		result[pvzID] = occupancy
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,288 @@
+package sqlite
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+)
+
+type ReceptionRepo struct {
+	receptions *sqlite_connect.SqliteStorage
+}
+
+func NewReceptionRepo(receptions *sqlite_connect.SqliteStorage) *ReceptionRepo {
+	return &ReceptionRepo{receptions: receptions}
+}
+
+func (r *ReceptionRepo) StartReception(ctx context.Context, pvzId int, kind string) (reception domain.Reception, err error) {
+	reception = domain.Reception{PvzId: pvzId, StartDate: time.Now().UTC(), Status: "in_progress", Kind: kind}
+	err = inTx(ctx, r.receptions, func(ctx context.Context) error {
+		err := executor(ctx, r.receptions).QueryRowContext(ctx,
+			`INSERT INTO receptions (pvz_id, created_at, status, kind) VALUES (?, ?, ?, ?) RETURNING id`,
+			pvzId, reception.StartDate, reception.Status, kind,
+		).Scan(&reception.Id)
+		if sqliteErrorCode(err) == uniqueViolation {
+			return repository.ErrReceptionInProgress
+		} else if err != nil {
+			return err
+		}
+		return addEvent(ctx, r.receptions, domain.EventReceptionStarted, pvzId, reception)
+	})
+	if err != nil {
+		return domain.Reception{}, err
+	}
+	return reception, nil
+}
+
+func (r *ReceptionRepo) CloseReception(ctx context.Context, pvzId int) (reception domain.Reception, err error) {
+	err = inTx(ctx, r.receptions, func(ctx context.Context) error {
+		err := executor(ctx, r.receptions).QueryRowContext(ctx, `
+			SELECT id, created_at, kind FROM receptions
+			WHERE pvz_id = ? AND status = 'in_progress'
+			ORDER BY created_at DESC, id DESC LIMIT 1`, pvzId).Scan(&reception.Id, &reception.StartDate, &reception.Kind)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrReceptionClosed
+		} else if err != nil {
+			return err
+		}
+
+		_, err = executor(ctx, r.receptions).ExecContext(ctx, `
+			UPDATE receptions SET status = 'closed'
+			WHERE id = ?`, reception.Id)
+		if err != nil {
+			return err
+		}
+
+		reception.Status = "closed"
+		reception.PvzId = pvzId
+		return addEvent(ctx, r.receptions, domain.EventReceptionClosed, pvzId, reception)
+	})
+	if err != nil {
+		return domain.Reception{}, err
+	}
+	return reception, nil
+}
+
+func (r *ReceptionRepo) GetLastReception(ctx context.Context, pvzId int) (domain.Reception, error) {
+	var rec domain.Reception
+	err := executor(ctx, r.receptions).QueryRowContext(ctx, `
+		SELECT id, created_at, status, kind
+		FROM receptions
+		WHERE pvz_id = ?
+		ORDER BY created_at DESC, id DESC LIMIT 1`, pvzId).
+		Scan(&rec.Id, &rec.StartDate, &rec.Status, &rec.Kind)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Reception{}, repository.NotFound
+	} else if err != nil {
+		return domain.Reception{}, err
+	}
+
+	rec.PvzId = pvzId
+	return rec, nil
+}
+
+func (r *ReceptionRepo) AddProduct(ctx context.Context, pvzId int, productId int) error {
+	return inTx(ctx, r.receptions, func(ctx context.Context) error {
+		rec, err := r.GetLastReception(ctx, pvzId)
+		if err != nil {
+			return err
+		}
+		if rec.Status != "in_progress" {
+			return repository.ErrReceptionClosed
+		}
+
+		_, err = executor(ctx, r.receptions).ExecContext(ctx,
+			`INSERT INTO reception_products (reception_id, product_id) VALUES (?, ?)`,
+			rec.Id, productId,
+		)
+		if sqliteErrorCode(err) == triggerViolation {
+			return repository.ErrReceptionClosed
+		} else if err != nil {
+			return err
+		}
+
+		event := domain.ProductEvent{ReceptionId: rec.Id, ProductId: productId}
+		err = executor(ctx, r.receptions).QueryRowContext(ctx,
+			`SELECT type FROM products WHERE id = ?`, productId).Scan(&event.Type)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, r.receptions, domain.EventProductAdded, pvzId, event)
+	})
+}
+
+func (r *ReceptionRepo) DeleteProduct(ctx context.Context, pvzId int, productId int, reason string) (deletedId string, err error) {
+	err = inTx(ctx, r.receptions, func(ctx context.Context) error {
+		rec, err := r.GetLastReception(ctx, pvzId)
+		if err != nil {
+			return err
+		}
+		if rec.Status != "in_progress" {
+			return repository.ErrReceptionClosed
+		}
+
+		if productId == 0 {
+			err = executor(ctx, r.receptions).QueryRowContext(ctx, `
+				SELECT product_id FROM reception_products
+				WHERE reception_id = ?
+				ORDER BY product_id DESC LIMIT 1`, rec.Id).Scan(&productId)
+			if errors.Is(err, sql.ErrNoRows) {
+				return repository.ErrProductNotFound
+			} else if err != nil {
+				return err
+			}
+		}
+
+		res, err := executor(ctx, r.receptions).ExecContext(ctx, `
+			DELETE FROM reception_products WHERE reception_id = ? AND product_id = ?`,
+			rec.Id, productId,
+		)
+		if sqliteErrorCode(err) == triggerViolation {
+			return repository.ErrReceptionClosed
+		} else if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return repository.ErrProductNotFound
+		}
+
+		event := domain.ProductEvent{ReceptionId: rec.Id, ProductId: productId, Reason: reason}
+		err = executor(ctx, r.receptions).QueryRowContext(ctx, `
+			INSERT INTO product_removals (reception_id, product_id, product_type, reason, removed_at)
+			SELECT ?, id, type, ?, ? FROM products WHERE id = ?
+			RETURNING product_type`,
+			rec.Id, reason, time.Now().UTC(), productId,
+		).Scan(&event.Type)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, r.receptions, domain.EventProductRemoved, pvzId, event)
+	})
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(productId), nil
+}
+
+func (r *ReceptionRepo) ListRemovals(ctx context.Context, receptionId int) ([]domain.ProductRemoval, error) {
+	rows, err := executor(ctx, r.receptions).QueryContext(ctx, `
+		SELECT id, reception_id, product_id, product_type, reason, removed_at
+		FROM product_removals
+		WHERE reception_id = ?
+		ORDER BY removed_at, id`, receptionId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	removals := make([]domain.ProductRemoval, 0)
+	for rows.Next() {
+		var removal domain.ProductRemoval
+		err := rows.Scan(&removal.Id, &removal.ReceptionId, &removal.ProductId, &removal.ProductType, &removal.Reason, &removal.RemovedAt)
+		if err != nil {
+			return nil, err
+		}
+		removals = append(removals, removal)
+	}
+	return removals, rows.Err()
+}
+
+func (r *ReceptionRepo) ListReceptions(ctx context.Context, pvzId int, filter usecases.ReceptionFilter) ([]domain.Reception, error) {
+	query := `SELECT id, created_at, status, kind FROM receptions r WHERE pvz_id = ?`
+	args := []interface{}{pvzId}
+
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	cond, condArgs := receptionDateCondition(filter.StartDate, filter.EndDate)
+	query += cond + " ORDER BY created_at DESC, id DESC"
+	args = append(args, condArgs...)
+
+	rows, err := executor(ctx, r.receptions).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receptions := make([]domain.Reception, 0)
+	for rows.Next() {
+		rec := domain.Reception{PvzId: pvzId}
+		if err := rows.Scan(&rec.Id, &rec.StartDate, &rec.Status, &rec.Kind); err != nil {
+			return nil, err
+		}
+		receptions = append(receptions, rec)
+	}
+	return receptions, rows.Err()
+}
+
+func (r *ReceptionRepo) CountOpenReceptions(ctx context.Context) (map[string]int, error) {
+	rows, err := executor(ctx, r.receptions).QueryContext(ctx, `
+		SELECT p.city, COUNT(*) FROM receptions r
+		JOIN pvz p ON p.id = r.pvz_id
+		WHERE r.status = 'in_progress'
+		GROUP BY p.city`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	open := make(map[string]int)
+	for rows.Next() {
+		var city string
+		var count int
+		if err := rows.Scan(&city, &count); err != nil {
+			return nil, err
+		}
+		open[city] = count
+	}
+	return open, rows.Err()
+}
+
+func (r *ReceptionRepo) GetReception(ctx context.Context, receptionId int) (domain.ReceptionWithProducts, error) {
+	var result domain.ReceptionWithProducts
+	err := executor(ctx, r.receptions).QueryRowContext(ctx, `
+		SELECT id, pvz_id, created_at, status, kind FROM receptions
+		WHERE id = ?`, receptionId).
+		Scan(&result.Reception.Id, &result.Reception.PvzId, &result.Reception.StartDate, &result.Reception.Status, &result.Reception.Kind)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ReceptionWithProducts{}, repository.NotFound
+	} else if err != nil {
+		return domain.ReceptionWithProducts{}, err
+	}
+
+	rows, err := executor(ctx, r.receptions).QueryContext(ctx, `
+		SELECT p.id, p.type, pt.name, p.added_at, COALESCE(p.barcode, ''), COALESCE(p.order_number, ''), p.issued_at,
+		       COALESCE(pr.reason, ''), COALESCE(pr.original_product_id, 0)
+		FROM products p
+		JOIN product_types pt ON pt.code = p.type
+		JOIN reception_products rp ON p.id = rp.product_id
+		LEFT JOIN product_returns pr ON pr.product_id = p.id
+		WHERE rp.reception_id = ?
+		ORDER BY p.added_at, p.id`, receptionId)
+	if err != nil {
+		return domain.ReceptionWithProducts{}, err
+	}
+	defer rows.Close()
+
+	result.Products = make([]domain.Product, 0)
+	for rows.Next() {
+		var product domain.Product
+		if err := rows.Scan(&product.Id, &product.Type, &product.TypeName, &product.DateTime, &product.Barcode, &product.OrderNumber, &product.IssuedAt,
+			&product.ReturnReason, &product.OriginalProductId); err != nil {
+			return domain.ReceptionWithProducts{}, err
+		}
+		result.Products = append(result.Products, product)
+	}
+	return result, rows.Err()
+}
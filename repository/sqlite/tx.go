@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"avito_test/pkg/logger"
+	"avito_test/pkg/sqlite_connect"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type txKey struct{}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// executor returns the transaction stored in ctx by TxManager.Do, or the plain connection pool.
+func executor(ctx context.Context, storage *sqlite_connect.SqliteStorage) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return storage.Db
+}
+
+type TxManager struct {
+	storage *sqlite_connect.SqliteStorage
+}
+
+func NewTxManager(storage *sqlite_connect.SqliteStorage) *TxManager {
+	return &TxManager{storage: storage}
+}
+
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.storage.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		// The driver has already rolled back a transaction whose context is done.
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			logger.FromContext(ctx).Warn("transaction rollback failed", "error", rollbackErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// inTx runs fn in the transaction carried by ctx or in a new one, so that a change
+// and its outbox event are committed together.
+func inTx(ctx context.Context, storage *sqlite_connect.SqliteStorage, fn func(ctx context.Context) error) error {
+	return NewTxManager(storage).Do(ctx, fn)
+}
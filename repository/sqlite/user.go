@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type UserRepo struct {
+	users *sqlite_connect.SqliteStorage
+}
+
+func NewUserRepo(users *sqlite_connect.SqliteStorage) *UserRepo {
+	return &UserRepo{users: users}
+}
+
+func (u *UserRepo) Register(ctx context.Context, email string, password string, role string) (domain.User, error) {
+	var id int
+	err := executor(ctx, u.users).QueryRowContext(ctx,
+		`INSERT INTO users (email, password_hash, role) VALUES (?, ?, ?) RETURNING id`,
+		email, password, role,
+	).Scan(&id)
+	if err != nil {
+		if sqliteErrorCode(err) == uniqueViolation {
+			return domain.User{}, repository.ErrEmailAlreadyExists
+		}
+		return domain.User{}, err
+	}
+	return domain.User{Id: id, Email: email, Password: password, Role: role}, nil
+}
+
+func (u *UserRepo) Login(ctx context.Context, email string) (domain.User, error) {
+	row := executor(ctx, u.users).QueryRowContext(ctx,
+		`SELECT id, email, password_hash, role FROM users WHERE email = ?`,
+		email,
+	)
+
+	var user domain.User
+	err := row.Scan(&user.Id, &user.Email, &user.Password, &user.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.User{}, repository.NotFound
+	} else if err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,206 @@
+package sqlite
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/sqlite_connect"
+	"avito_test/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+type WebhookRepo struct {
+	webhooks *sqlite_connect.SqliteStorage
+}
+
+func NewWebhookRepo(webhooks *sqlite_connect.SqliteStorage) *WebhookRepo {
+	return &WebhookRepo{webhooks: webhooks}
+}
+
+const webhookColumns = `id, url, secret, event_types, COALESCE(pvz_id, 0), city, created_at`
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_error, response_status, created_at, delivered_at`
+
+func (w *WebhookRepo) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	eventTypes, err := json.Marshal(append([]string{}, webhook.EventTypes...))
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook.CreatedAt = time.Now().UTC()
+	err = executor(ctx, w.webhooks).QueryRowContext(ctx, `
+		INSERT INTO webhooks (url, secret, event_types, pvz_id, city, created_at)
+		VALUES (?, ?, ?, NULLIF(?, 0), ?, ?)
+		RETURNING id`,
+		webhook.URL, webhook.Secret, string(eventTypes), webhook.PvzId, webhook.City, webhook.CreatedAt,
+	).Scan(&webhook.Id)
+	if sqliteErrorCode(err) == foreignKeyViolation {
+		return domain.Webhook{}, repository.NotFound
+	} else if err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (w *WebhookRepo) GetWebhook(ctx context.Context, webhookId int) (domain.Webhook, error) {
+	webhook, err := scanWebhook(executor(ctx, w.webhooks).QueryRowContext(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, webhookId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Webhook{}, repository.NotFound
+	} else if err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (w *WebhookRepo) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := executor(ctx, w.webhooks).QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]domain.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (w *WebhookRepo) DeleteWebhook(ctx context.Context, webhookId int) error {
+	res, err := executor(ctx, w.webhooks).ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, webhookId)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return repository.NotFound
+	}
+	return nil
+}
+
+func (w *WebhookRepo) AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	_, err := executor(ctx, w.webhooks).ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING`,
+		delivery.WebhookId, delivery.EventId, delivery.EventType, string(delivery.Payload), delivery.Status,
+		delivery.NextAttemptAt.UTC(), delivery.CreatedAt.UTC())
+	if sqliteErrorCode(err) == foreignKeyViolation {
+		return repository.NotFound
+	}
+	return err
+}
+
+func (w *WebhookRepo) GetDelivery(ctx context.Context, deliveryId int) (domain.WebhookDelivery, error) {
+	delivery, err := scanDelivery(executor(ctx, w.webhooks).QueryRowContext(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, deliveryId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.WebhookDelivery{}, repository.NotFound
+	} else if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func (w *WebhookRepo) ListDeliveries(ctx context.Context, webhookId int, limit int) ([]domain.WebhookDelivery, error) {
+	return w.listDeliveries(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC LIMIT ?`, webhookId, limit)
+}
+
+// ClaimDueDeliveries claims in a single statement, which SQLite runs under its write lock.
+func (w *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	deliveries, err := w.listDeliveries(ctx, `
+		UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at, id LIMIT ?
+		)
+		RETURNING `+deliveryColumns, until.UTC(), domain.DeliveryStatusPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Id < deliveries[j].Id })
+	return deliveries, nil
+}
+
+func (w *WebhookRepo) listDeliveries(ctx context.Context, query string, args ...interface{}) ([]domain.WebhookDelivery, error) {
+	rows, err := executor(ctx, w.webhooks).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (w *WebhookRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	var deliveredAt *time.Time
+	if delivery.DeliveredAt != nil {
+		utc := delivery.DeliveredAt.UTC()
+		deliveredAt = &utc
+	}
+
+	res, err := executor(ctx, w.webhooks).ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, response_status = ?, delivered_at = ?
+		WHERE id = ?`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt.UTC(), delivery.LastError, delivery.ResponseStatus,
+		deliveredAt, delivery.Id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return repository.NotFound
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row scanner) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	var eventTypes string
+	err := row.Scan(&webhook.Id, &webhook.URL, &webhook.Secret, &eventTypes, &webhook.PvzId, &webhook.City, &webhook.CreatedAt)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if err := json.Unmarshal([]byte(eventTypes), &webhook.EventTypes); err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func scanDelivery(row scanner) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var payload string
+	err := row.Scan(&delivery.Id, &delivery.WebhookId, &delivery.EventId, &delivery.EventType, &payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastError, &delivery.ResponseStatus,
+		&delivery.CreatedAt, &delivery.DeliveredAt)
+	delivery.Payload = json.RawMessage(payload)
+	return delivery, err
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "context"
+
+// TxManager runs fn as a single unit of work: repository calls made with the ctx
+// passed to fn are committed together or rolled back if fn returns an error.
+type TxManager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
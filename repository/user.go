@@ -1,8 +1,11 @@
 package repository
 
-import "avito_test/domain"
+import (
+	"avito_test/domain"
+	"context"
+)
 
 type User interface {
-	Register(email string, password string, role string) (domain.User, error)
-	Login(email string) (domain.User, error)
+	Register(ctx context.Context, email string, password string, role string) (domain.User, error)
+	Login(ctx context.Context, email string) (domain.User, error)
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"avito_test/domain"
+	"context"
+	"time"
+)
+
+type Webhook interface {
+	CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	GetWebhook(ctx context.Context, webhookId int) (domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	// DeleteWebhook also drops the delivery log of the webhook.
+	DeleteWebhook(ctx context.Context, webhookId int) error
+	// AddDelivery queues a delivery; a second one for the same webhook and event is ignored,
+	// so relaying an event again does not notify the partner twice.
+	AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, deliveryId int) (domain.WebhookDelivery, error)
+	// ListDeliveries returns up to limit deliveries of the webhook, newest first.
+	ListDeliveries(ctx context.Context, webhookId int, limit int) ([]domain.WebhookDelivery, error)
+	// ClaimDueDeliveries returns up to limit pending deliveries whose next attempt is due at now,
+	// oldest first, and puts their next attempt off until the given time, so that no other
+	// instance picks them up while the caller sends them. A claimed delivery the caller never
+	// updates is retried after until.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, until time.Time, limit int) ([]domain.WebhookDelivery, error)
+	// UpdateDelivery saves the outcome of a delivery attempt.
+	UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+}
@@ -0,0 +1,32 @@
+package usecases
+
+import (
+	"avito_test/domain"
+	"context"
+	"time"
+)
+
+// AuditFilter selects a page of the audit log; empty fields match every entry.
+type AuditFilter struct {
+	ActorId string
+	Role    string
+	Action  string
+	PvzId   int
+	From    *time.Time
+	To      *time.Time
+	Page    int
+	Limit   int
+}
+
+type AuditPage struct {
+	Items []domain.AuditEntry
+	Total int
+}
+
+type Audit interface {
+	// Record stores the entry of a mutating call. Payload is the raw request body: only a
+	// summary of it is kept, and the ids it names are added to TargetIds.
+	Record(ctx context.Context, entry domain.AuditEntry) error
+	// List returns the matching entries, newest first.
+	List(ctx context.Context, filter AuditFilter) (AuditPage, error)
+}
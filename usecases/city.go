@@ -0,0 +1,14 @@
+package usecases
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+type City interface {
+	CreateCity(ctx context.Context, name string) (domain.City, error)
+	ListCities(ctx context.Context) ([]domain.City, error)
+	// UpdateCity changes only the fields that are not nil.
+	UpdateCity(ctx context.Context, cityId int, name *string, enabled *bool) (domain.City, error)
+	DeleteCity(ctx context.Context, cityId int) error
+}
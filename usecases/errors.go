@@ -3,6 +3,24 @@ package usecases
 import "errors"
 
 var (
-	ErrUnclosedReception = errors.New("unclosed reception")
-	ErrAlreadyClosed     = errors.New("already closed")
+	ErrUnclosedReception       = errors.New("unclosed reception")
+	ErrAlreadyClosed           = errors.New("already closed")
+	ErrTimeout                 = errors.New("timeout")
+	ErrInvalidCursor           = errors.New("invalid cursor")
+	ErrInvalidCity             = errors.New("invalid city")
+	ErrCityDisabled            = errors.New("city is disabled")
+	ErrInvalidProductType      = errors.New("invalid product type")
+	ErrOrderEmpty              = errors.New("no products in stock for the order")
+	ErrInvalidTransition       = errors.New("order status transition is not allowed")
+	ErrInvalidReceptionKind    = errors.New("invalid reception kind")
+	ErrReturnDetailsRequired   = errors.New("return items need a known reason and an order or product reference")
+	ErrUnexpectedReturnDetails = errors.New("return details are only accepted in customer returns")
+	ErrInvalidReturnReference  = errors.New("original product is not an issued item of the order")
+	ErrInvalidStorageDays      = errors.New("storage period must be at least one day")
+	ErrShipmentEmpty           = errors.New("no overdue products to ship")
+	ErrNotOverdue              = errors.New("product is not overdue at this pvz")
+	ErrInvalidCapacity         = errors.New("capacity must not be negative and type limits must be positive")
+	ErrCapacityExceeded        = errors.New("pvz capacity exceeded")
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookFilter    = errors.New("webhook filter refers to an unknown event type, pvz or city")
 )
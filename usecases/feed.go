@@ -0,0 +1,22 @@
+package usecases
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+// ProgressSnapshot is the ReceptionProgress type sent first, describing the last reception as it is.
+const ProgressSnapshot = "snapshot"
+
+type ReceptionFeed interface {
+	// Subscribe streams the intake progress of the PVZ until ctx is done. A client that saw
+	// the event lastEventId before gets the events after it first; a new one, given zero,
+	// gets a ProgressSnapshot of the last reception if there is one. The channel is closed
+	// at the end, and early if the client falls behind, so that it resumes from its last event.
+	Subscribe(ctx context.Context, pvzId int, lastEventId int) (<-chan domain.ReceptionProgress, error)
+}
+
+// EventSubscriber hands out the events of a single PVZ.
+type EventSubscriber interface {
+	Subscribe(pvzId int) (<-chan domain.Event, func())
+}
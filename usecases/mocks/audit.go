@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"avito_test/usecases"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type Audit struct {
+	mock.Mock
+}
+
+func (m *Audit) Record(ctx context.Context, entry domain.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *Audit) List(ctx context.Context, filter usecases.AuditFilter) (usecases.AuditPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(usecases.AuditPage), args.Error(1)
+}
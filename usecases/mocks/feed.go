@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type ReceptionFeed struct {
+	mock.Mock
+}
+
+func (m *ReceptionFeed) Subscribe(ctx context.Context, pvzId int, lastEventId int) (<-chan domain.ReceptionProgress, error) {
+	args := m.Called(pvzId, lastEventId)
+	if ch, ok := args.Get(0).(chan domain.ReceptionProgress); ok {
+		return ch, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
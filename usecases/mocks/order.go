@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type Order struct {
+	mock.Mock
+}
+
+func (m *Order) CreateOrder(ctx context.Context, pvzId int, orderNumber string) (domain.OrderWithProducts, error) {
+	args := m.Called(pvzId, orderNumber)
+	return args.Get(0).(domain.OrderWithProducts), args.Error(1)
+}
+
+func (m *Order) GetOrder(ctx context.Context, orderId int) (domain.OrderWithProducts, error) {
+	args := m.Called(orderId)
+	return args.Get(0).(domain.OrderWithProducts), args.Error(1)
+}
+
+func (m *Order) GetOrders(ctx context.Context, pvzId int, status string) ([]domain.Order, error) {
+	args := m.Called(pvzId, status)
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *Order) ChangeOrderStatus(ctx context.Context, orderId int, status string) (domain.Order, error) {
+	args := m.Called(orderId, status)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"avito_test/domain"
+	"avito_test/usecases"
+	"context"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -9,12 +11,22 @@ type Product struct {
 	mock.Mock
 }
 
-func (m *Product) AddProduct(sort string, pvzId int) (domain.Product, error) {
-	args := m.Called(sort, pvzId)
+func (m *Product) AddProduct(ctx context.Context, product usecases.NewProduct, pvzId int) (domain.Product, error) {
+	args := m.Called(product, pvzId)
 	return args.Get(0).(domain.Product), args.Error(1)
 }
 
-func (m *Product) DeleteProduct(pvzId int) error {
+func (m *Product) DeleteProduct(ctx context.Context, pvzId int) error {
 	args := m.Called(pvzId)
 	return args.Error(0)
 }
+
+func (m *Product) DeleteProductById(ctx context.Context, pvzId int, productId int, reason string) error {
+	args := m.Called(pvzId, productId, reason)
+	return args.Error(0)
+}
+
+func (m *Product) GetProductByBarcode(ctx context.Context, barcode string) (domain.ProductLocation, error) {
+	args := m.Called(barcode)
+	return args.Get(0).(domain.ProductLocation), args.Error(1)
+}
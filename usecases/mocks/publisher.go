@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type Publisher struct {
+	mock.Mock
+}
+
+func (m *Publisher) Publish(ctx context.Context, event domain.Event) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
@@ -3,25 +3,30 @@ package mocks
 import (
 	"avito_test/domain"
 	"avito_test/usecases"
+	"context"
 	"github.com/stretchr/testify/mock"
-	"time"
 )
 
 type Pvz struct {
 	mock.Mock
 }
 
-func (m *Pvz) OpenPvz(city string) (domain.Pvz, error) {
+func (m *Pvz) OpenPvz(ctx context.Context, city string) (domain.Pvz, error) {
 	args := m.Called(city)
 	return args.Get(0).(domain.Pvz), args.Error(1)
 }
 
-func (m *Pvz) GetPvz(pvzId int) (domain.Pvz, error) {
+func (m *Pvz) GetPvz(ctx context.Context, pvzId int) (domain.Pvz, error) {
 	args := m.Called(pvzId)
 	return args.Get(0).(domain.Pvz), args.Error(1)
 }
 
-func (m *Pvz) GetPvzListWithFilter(startDate, endDate *time.Time, page, limit int) ([]usecases.PvzWithReceptions, error) {
-	args := m.Called(startDate, endDate, page, limit)
-	return args.Get(0).([]usecases.PvzWithReceptions), args.Error(1)
+func (m *Pvz) GetPvzListWithFilter(ctx context.Context, filter usecases.PvzFilter) (usecases.PvzPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(usecases.PvzPage), args.Error(1)
+}
+
+func (m *Pvz) SetCapacity(ctx context.Context, pvzId int, capacity int, typeCapacity map[string]int) (domain.Pvz, error) {
+	args := m.Called(pvzId, capacity, typeCapacity)
+	return args.Get(0).(domain.Pvz), args.Error(1)
 }
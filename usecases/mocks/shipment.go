@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type Shipment struct {
+	mock.Mock
+}
+
+func (m *Shipment) MarkOverdue(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *Shipment) GetOverdue(ctx context.Context, pvzId int) ([]domain.Product, error) {
+	args := m.Called(pvzId)
+	return args.Get(0).([]domain.Product), args.Error(1)
+}
+
+func (m *Shipment) CreateShipment(ctx context.Context, pvzId int, productIds []int) (domain.ReturnShipmentWithProducts, error) {
+	args := m.Called(pvzId, productIds)
+	return args.Get(0).(domain.ReturnShipmentWithProducts), args.Error(1)
+}
+
+func (m *Shipment) GetShipment(ctx context.Context, shipmentId int) (domain.ReturnShipmentWithProducts, error) {
+	args := m.Called(shipmentId)
+	return args.Get(0).(domain.ReturnShipmentWithProducts), args.Error(1)
+}
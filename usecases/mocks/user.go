@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"avito_test/domain"
+	"context"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -14,12 +15,12 @@ func (m *User) GetToken(id string, role string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
-func (m *User) Register(email string, password string, role string) (domain.User, error) {
+func (m *User) Register(ctx context.Context, email string, password string, role string) (domain.User, error) {
 	args := m.Called(email, password, role)
 	return args.Get(0).(domain.User), args.Error(1)
 }
 
-func (m *User) Login(email string, password string) (string, error) {
+func (m *User) Login(ctx context.Context, email string, password string) (string, error) {
 	args := m.Called(email, password)
 	return args.String(0), args.Error(1)
 }
@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"avito_test/domain"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type Webhook struct {
+	mock.Mock
+}
+
+func (m *Webhook) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *Webhook) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *Webhook) GetWebhook(ctx context.Context, webhookId int) (domain.Webhook, error) {
+	args := m.Called(webhookId)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *Webhook) DeleteWebhook(ctx context.Context, webhookId int) error {
+	args := m.Called(webhookId)
+	return args.Error(0)
+}
+
+func (m *Webhook) ListDeliveries(ctx context.Context, webhookId int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(webhookId)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *Webhook) Redeliver(ctx context.Context, webhookId int, deliveryId int) (domain.WebhookDelivery, error) {
+	args := m.Called(webhookId, deliveryId)
+	return args.Get(0).(domain.WebhookDelivery), args.Error(1)
+}
+
+type WebhookSender struct {
+	mock.Mock
+}
+
+func (m *WebhookSender) Send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	args := m.Called(webhook, delivery)
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,14 @@
+package usecases
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+type Order interface {
+	// CreateOrder gathers the stocked products of the PVZ that carry the order number.
+	CreateOrder(ctx context.Context, pvzId int, orderNumber string) (domain.OrderWithProducts, error)
+	GetOrder(ctx context.Context, orderId int) (domain.OrderWithProducts, error)
+	GetOrders(ctx context.Context, pvzId int, status string) ([]domain.Order, error)
+	ChangeOrderStatus(ctx context.Context, orderId int, status string) (domain.Order, error)
+}
@@ -0,0 +1,12 @@
+package usecases
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+// Publisher delivers outbox events to downstream systems. Delivery is at-least-once,
+// so consumers should deduplicate by Event.Id.
+type Publisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
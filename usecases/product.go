@@ -1,8 +1,25 @@
 package usecases
 
-import "avito_test/domain"
+import (
+	"avito_test/domain"
+	"context"
+)
+
+// NewProduct is what an employee scans into a reception. Type is either the
+// code or the display name of a product type; Barcode and OrderNumber are optional.
+// Items of a customer return also need ReturnReason and OrderNumber or
+// OriginalProductId, the issued product being returned.
+type NewProduct struct {
+	Type              string
+	Barcode           string
+	OrderNumber       string
+	ReturnReason      string
+	OriginalProductId int
+}
 
 type Product interface {
-	AddProduct(sort string, pvzId int) (domain.Product, error)
-	DeleteProduct(pvzId int) error
+	AddProduct(ctx context.Context, product NewProduct, pvzId int) (domain.Product, error)
+	DeleteProduct(ctx context.Context, pvzId int) error
+	DeleteProductById(ctx context.Context, pvzId int, productId int, reason string) error
+	GetProductByBarcode(ctx context.Context, barcode string) (domain.ProductLocation, error)
 }
@@ -0,0 +1,13 @@
+package usecases
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+type ProductType interface {
+	// CreateProductType falls back to domain.DefaultStorageDays when storageDays is not positive.
+	CreateProductType(ctx context.Context, code string, name string, storageDays int) (domain.ProductType, error)
+	ListProductTypes(ctx context.Context) ([]domain.ProductType, error)
+	SetStorageDays(ctx context.Context, code string, storageDays int) (domain.ProductType, error)
+}
@@ -2,16 +2,45 @@ package usecases
 
 import (
 	"avito_test/domain"
+	"context"
 	"time"
 )
 
+// PvzWithReceptions lists deliveries in Receptions and customer returns in Returns.
 type PvzWithReceptions struct {
 	Pvz        domain.Pvz
 	Receptions []domain.ReceptionWithProducts
+	Returns    []domain.ReceptionWithProducts
+	Occupancy  domain.Occupancy
+}
+
+// DefaultPvzListLimit is the size of a PVZ list page that asks for none. No page is
+// larger than MaxPvzListLimit, whichever transport asks for it.
+const (
+	DefaultPvzListLimit = 10
+	MaxPvzListLimit     = 100
+)
+
+// PvzFilter selects a page of PVZs either by Page or, when Cursor is set,
+// by the NextCursor returned with the previous page.
+type PvzFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	Page      int
+	Limit     int
+	Cursor    string
+}
+
+type PvzPage struct {
+	Items      []PvzWithReceptions
+	Total      int
+	NextCursor string
 }
 
 type Pvz interface {
-	OpenPvz(city string) (domain.Pvz, error)
-	GetPvz(pvzId int) (domain.Pvz, error)
-	GetPvzListWithFilter(startDate, endDate *time.Time, page, limit int) ([]PvzWithReceptions, error)
+	OpenPvz(ctx context.Context, city string) (domain.Pvz, error)
+	GetPvz(ctx context.Context, pvzId int) (domain.Pvz, error)
+	GetPvzListWithFilter(ctx context.Context, filter PvzFilter) (PvzPage, error)
+	// SetCapacity sets the total capacity, zero for unlimited, and replaces the per type limits.
+	SetCapacity(ctx context.Context, pvzId int, capacity int, typeCapacity map[string]int) (domain.Pvz, error)
 }
@@ -1,9 +1,23 @@
 package usecases
 
-import "avito_test/domain"
+import (
+	"avito_test/domain"
+	"context"
+	"time"
+)
+
+// ReceptionFilter narrows the reception history of a PVZ; zero fields are not applied.
+type ReceptionFilter struct {
+	Status    string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
 
 type Reception interface {
-	StartReception(pvzId int) (domain.Reception, error)
-	CloseReception(pvzId int) (domain.Reception, error)
-	CheckPvz(pvzId int) error
+	StartReception(ctx context.Context, pvzId int, kind string) (domain.Reception, error)
+	CloseReception(ctx context.Context, pvzId int) (domain.Reception, error)
+	CheckPvz(ctx context.Context, pvzId int) error
+	GetReceptions(ctx context.Context, pvzId int, filter ReceptionFilter) ([]domain.Reception, error)
+	GetReception(ctx context.Context, receptionId int) (domain.ReceptionWithProducts, error)
+	GetRemovals(ctx context.Context, receptionId int) ([]domain.ProductRemoval, error)
 }
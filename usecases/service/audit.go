@@ -0,0 +1,149 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of the request summary kept in the audit log.
+const (
+	maxAuditString = 128
+	maxAuditItems  = 20
+)
+
+// sensitiveAuditKeys are left out of the summary wherever a key contains one of them.
+var sensitiveAuditKeys = []string{"password", "secret", "token"}
+
+type Audit struct {
+	repo repository.Audit
+}
+
+func NewAuditService(repo repository.Audit) *Audit {
+	return &Audit{repo: repo}
+}
+
+func (s *Audit) Record(ctx context.Context, entry domain.AuditEntry) (err error) {
+	ctx, end := startSpan(ctx, "Audit.Record")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	entry.TargetIds, entry.Payload = summarizePayload(entry.TargetIds, entry.Payload)
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	return s.repo.AddEntry(ctx, entry)
+}
+
+func (s *Audit) List(ctx context.Context, filter usecases.AuditFilter) (_ usecases.AuditPage, err error) {
+	ctx, end := startSpan(ctx, "Audit.List")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	listFilter := repository.AuditFilter{
+		ActorId: filter.ActorId,
+		Role:    filter.Role,
+		Action:  filter.Action,
+		PvzId:   filter.PvzId,
+		From:    filter.From,
+		To:      filter.To,
+		Offset:  (filter.Page - 1) * filter.Limit,
+		Limit:   filter.Limit,
+	}
+	items, err := s.repo.ListEntries(ctx, listFilter)
+	if err != nil {
+		return usecases.AuditPage{}, err
+	}
+	total, err := s.repo.CountEntries(ctx, listFilter)
+	if err != nil {
+		return usecases.AuditPage{}, err
+	}
+	return usecases.AuditPage{Items: items, Total: total}, nil
+}
+
+// summarizePayload adds the top-level "...Id" fields of a JSON object body to targetIds,
+// keeping ids already known from the route, and shortens the body: credentials are
+// dropped, long strings and lists are cut. A body that is not an object is not kept.
+func summarizePayload(targetIds map[string]string, payload json.RawMessage) (map[string]string, json.RawMessage) {
+	if len(payload) == 0 {
+		return targetIds, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var body map[string]any
+	if err := decoder.Decode(&body); err != nil || body == nil {
+		return targetIds, nil
+	}
+
+	for key, value := range body {
+		if !strings.HasSuffix(key, "Id") {
+			continue
+		}
+		var id string
+		switch v := value.(type) {
+		case json.Number:
+			id = v.String()
+		case string:
+			id = v
+		}
+		if id == "" || targetIds[key] != "" {
+			continue
+		}
+		if targetIds == nil {
+			targetIds = make(map[string]string)
+		}
+		targetIds[key] = summarizeValue(id).(string)
+	}
+
+	summary, err := json.Marshal(summarizeValue(body))
+	if err != nil {
+		return targetIds, nil
+	}
+	return targetIds, summary
+}
+
+func summarizeValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		if utf8.RuneCountInString(v) > maxAuditString {
+			return string([]rune(v)[:maxAuditString]) + "…"
+		}
+		return v
+	case []any:
+		items := make([]any, 0, min(len(v), maxAuditItems+1))
+		for _, item := range v[:min(len(v), maxAuditItems)] {
+			items = append(items, summarizeValue(item))
+		}
+		if len(v) > maxAuditItems {
+			items = append(items, fmt.Sprintf("… %d more", len(v)-maxAuditItems))
+		}
+		return items
+	case map[string]any:
+		summary := make(map[string]any, len(v))
+		for key, item := range v {
+			if !isSensitiveKey(key) {
+				summary[key] = summarizeValue(item)
+			}
+		}
+		return summary
+	default:
+		return value
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveAuditKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
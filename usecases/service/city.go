@@ -0,0 +1,54 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"context"
+)
+
+type City struct {
+	repo repository.City
+}
+
+func NewCityService(repo repository.City) *City {
+	return &City{repo: repo}
+}
+
+func (c *City) CreateCity(ctx context.Context, name string) (_ domain.City, err error) {
+	ctx, end := startSpan(ctx, "City.CreateCity")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return c.repo.CreateCity(ctx, name)
+}
+
+func (c *City) ListCities(ctx context.Context) (_ []domain.City, err error) {
+	ctx, end := startSpan(ctx, "City.ListCities")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return c.repo.ListCities(ctx)
+}
+
+func (c *City) UpdateCity(ctx context.Context, cityId int, name *string, enabled *bool) (_ domain.City, err error) {
+	ctx, end := startSpan(ctx, "City.UpdateCity")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	city, err := c.repo.GetCity(ctx, cityId)
+	if err != nil {
+		return domain.City{}, err
+	}
+	if name != nil {
+		city.Name = *name
+	}
+	if enabled != nil {
+		city.Enabled = *enabled
+	}
+	return c.repo.UpdateCity(ctx, city)
+}
+
+func (c *City) DeleteCity(ctx context.Context, cityId int) (err error) {
+	ctx, end := startSpan(ctx, "City.DeleteCity")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return c.repo.DeleteCity(ctx, cityId)
+}
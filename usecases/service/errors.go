@@ -0,0 +1,30 @@
+package service
+
+import (
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// wrapTimeout reports an error caused by an expired request deadline as usecases.ErrTimeout,
+// so handlers can tell it apart from other storage failures.
+func wrapTimeout(ctx context.Context, err *error) {
+	if *err != nil && !errors.Is(*err, usecases.ErrTimeout) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		*err = fmt.Errorf("%w: %v", usecases.ErrTimeout, *err)
+	}
+}
+
+// receptionConflict translates invariant violations reported by the storage
+// into the usecase errors returned by the pre-checks.
+func receptionConflict(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrReceptionInProgress):
+		return usecases.ErrUnclosedReception
+	case errors.Is(err, repository.ErrReceptionClosed):
+		return usecases.ErrAlreadyClosed
+	default:
+		return err
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/logger"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// replayLimit bounds the events replayed to a client that resumes; one further behind
+// gets a snapshot instead.
+const replayLimit = 500
+
+// feedBuffer is how much progress a client may lag behind before its feed is closed.
+const feedBuffer = 64
+
+type ReceptionFeed struct {
+	events        usecases.EventSubscriber
+	receptionRepo repository.Reception
+	pvzRepo       repository.Pvz
+	outboxRepo    repository.Outbox
+
+	mu sync.Mutex
+	// feeds holds the PVZs watched by at least one client. The progress of an event is
+	// counted once per PVZ and handed to all of its clients.
+	feeds map[int]*pvzFeed
+}
+
+type pvzFeed struct {
+	subscribers map[chan domain.ReceptionProgress]struct{}
+	unsubscribe func()
+}
+
+func NewReceptionFeedService(events usecases.EventSubscriber, receptionRepo repository.Reception, pvzRepo repository.Pvz, outboxRepo repository.Outbox) *ReceptionFeed {
+	return &ReceptionFeed{
+		events:        events,
+		receptionRepo: receptionRepo,
+		pvzRepo:       pvzRepo,
+		outboxRepo:    outboxRepo,
+		feeds:         make(map[int]*pvzFeed),
+	}
+}
+
+func (f *ReceptionFeed) Subscribe(ctx context.Context, pvzId int, lastEventId int) (_ <-chan domain.ReceptionProgress, err error) {
+	ctx, end := startSpan(ctx, "ReceptionFeed.Subscribe")
+	defer end(&err)
+
+	if _, err := f.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+		return nil, err
+	}
+
+	// Joining before the backlog is read means no event falls in between.
+	progress, leave := f.join(pvzId)
+	backlog, err := f.backlog(ctx, pvzId, lastEventId)
+	if err != nil {
+		leave()
+		return nil, err
+	}
+
+	out := make(chan domain.ReceptionProgress)
+	go func() {
+		defer close(out)
+		defer leave()
+
+		send := func(progress domain.ReceptionProgress) bool {
+			select {
+			case out <- progress:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		replayed := make(map[int]bool, len(backlog))
+		for _, p := range backlog {
+			replayed[p.EventId] = true
+			if !send(p) {
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case p, ok := <-progress:
+				if !ok {
+					return
+				}
+				// An event committed while the backlog was read comes both ways.
+				if replayed[p.EventId] {
+					continue
+				}
+				if !send(p) {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// backlog is what a client gets before the live progress: the events after lastEventId or,
+// for a new client or one too far behind, a snapshot of the last reception. Past counts are
+// not kept, so replayed events carry the counts of their reception as it is now.
+func (f *ReceptionFeed) backlog(ctx context.Context, pvzId int, lastEventId int) ([]domain.ReceptionProgress, error) {
+	if lastEventId > 0 {
+		events, err := f.outboxRepo.ListEvents(ctx, pvzId, lastEventId, replayLimit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(events) <= replayLimit {
+			return f.replay(ctx, events)
+		}
+	}
+
+	last, err := f.receptionRepo.GetLastReception(ctx, pvzId)
+	if errors.Is(err, repository.NotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	snapshot, err := f.progress(ctx, last.Id)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Type = usecases.ProgressSnapshot
+	return []domain.ReceptionProgress{snapshot}, nil
+}
+
+func (f *ReceptionFeed) replay(ctx context.Context, events []domain.Event) ([]domain.ReceptionProgress, error) {
+	counted := make(map[int]domain.ReceptionProgress)
+	backlog := make([]domain.ReceptionProgress, 0, len(events))
+	for _, event := range events {
+		receptionId, product, ok := eventReception(ctx, event)
+		if !ok {
+			continue
+		}
+		progress, ok := counted[receptionId]
+		if !ok {
+			var err error
+			if progress, err = f.progress(ctx, receptionId); err != nil {
+				return nil, err
+			}
+			counted[receptionId] = progress
+		}
+		backlog = append(backlog, withEvent(progress, event, product))
+	}
+	return backlog, nil
+}
+
+// join adds a client to the feed of the PVZ, starting the feed for the first one. The
+// returned function takes the client off again and closes its channel.
+func (f *ReceptionFeed) join(pvzId int) (<-chan domain.ReceptionProgress, func()) {
+	ch := make(chan domain.ReceptionProgress, feedBuffer)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	feed, ok := f.feeds[pvzId]
+	if !ok {
+		events, unsubscribe := f.events.Subscribe(pvzId)
+		feed = &pvzFeed{subscribers: make(map[chan domain.ReceptionProgress]struct{}), unsubscribe: unsubscribe}
+		f.feeds[pvzId] = feed
+		go f.fanOut(pvzId, feed, events)
+	}
+	feed.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.leave(pvzId, feed, ch)
+	}
+}
+
+// leave takes the client off the feed and stops the feed after the last one; the caller
+// must hold f.mu.
+func (f *ReceptionFeed) leave(pvzId int, feed *pvzFeed, ch chan domain.ReceptionProgress) {
+	if _, ok := feed.subscribers[ch]; !ok {
+		return
+	}
+	delete(feed.subscribers, ch)
+	close(ch)
+	if len(feed.subscribers) == 0 {
+		if f.feeds[pvzId] == feed {
+			delete(f.feeds, pvzId)
+		}
+		feed.unsubscribe()
+	}
+}
+
+// fanOut counts the progress of every event of the PVZ and hands it to its clients until
+// the feed is stopped. A client too slow to take it is dropped, so that it reconnects and
+// resumes from its last event instead of silently missing this one.
+func (f *ReceptionFeed) fanOut(pvzId int, feed *pvzFeed, events <-chan domain.Event) {
+	ctx := context.Background()
+	for event := range events {
+		progress, ok := f.eventProgress(ctx, event)
+		if !ok {
+			continue
+		}
+
+		f.mu.Lock()
+		for ch := range feed.subscribers {
+			select {
+			case ch <- progress:
+			default:
+				logger.FromContext(ctx).Warn("reception feed: dropped a slow client", "pvzId", pvzId, "eventId", event.Id)
+				f.leave(pvzId, feed, ch)
+			}
+		}
+		f.mu.Unlock()
+	}
+}
+
+// eventProgress reports false for events that are not about intake or cannot be read.
+func (f *ReceptionFeed) eventProgress(ctx context.Context, event domain.Event) (domain.ReceptionProgress, bool) {
+	receptionId, product, ok := eventReception(ctx, event)
+	if !ok {
+		return domain.ReceptionProgress{}, false
+	}
+
+	progress, err := f.progress(ctx, receptionId)
+	if err != nil {
+		logger.FromContext(ctx).Error("reception feed: counting products", "receptionId", receptionId, "error", err)
+		return domain.ReceptionProgress{}, false
+	}
+	return withEvent(progress, event, product), true
+}
+
+// eventReception reads the reception an intake event is about, and the product for the
+// events of a product. It reports false for other events and for those it cannot read.
+func eventReception(ctx context.Context, event domain.Event) (int, domain.ProductEvent, bool) {
+	var product domain.ProductEvent
+	switch event.Type {
+	case domain.EventReceptionStarted, domain.EventReceptionClosed:
+		var reception domain.Reception
+		if err := json.Unmarshal(event.Payload, &reception); err != nil {
+			logger.FromContext(ctx).Warn("reception feed: malformed event", "eventId", event.Id, "error", err)
+			return 0, product, false
+		}
+		return reception.Id, product, true
+	case domain.EventProductAdded, domain.EventProductRemoved:
+		if err := json.Unmarshal(event.Payload, &product); err != nil {
+			logger.FromContext(ctx).Warn("reception feed: malformed event", "eventId", event.Id, "error", err)
+			return 0, product, false
+		}
+		return product.ReceptionId, product, true
+	default:
+		return 0, product, false
+	}
+}
+
+func withEvent(progress domain.ReceptionProgress, event domain.Event, product domain.ProductEvent) domain.ReceptionProgress {
+	progress.EventId = event.Id
+	progress.Type = event.Type
+	progress.ProductId = product.ProductId
+	progress.ProductType = product.Type
+	return progress
+}
+
+// progress counts the products the reception holds right now.
+func (f *ReceptionFeed) progress(ctx context.Context, receptionId int) (domain.ReceptionProgress, error) {
+	reception, err := f.receptionRepo.GetReception(ctx, receptionId)
+	if err != nil {
+		return domain.ReceptionProgress{}, err
+	}
+
+	progress := domain.ReceptionProgress{
+		ReceptionId: receptionId,
+		Status:      reception.Reception.Status,
+		Counts:      make(map[string]int),
+		Total:       len(reception.Products),
+	}
+	for _, product := range reception.Products {
+		progress.Counts[product.Type]++
+	}
+	return progress, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"errors"
+)
+
+type Order struct {
+	repo      repository.Order
+	pvzRepo   repository.Pvz
+	txManager repository.TxManager
+}
+
+func NewOrderService(orderRepo repository.Order, pvzRepo repository.Pvz, txManager repository.TxManager) *Order {
+	return &Order{repo: orderRepo, pvzRepo: pvzRepo, txManager: txManager}
+}
+
+func (o *Order) CreateOrder(ctx context.Context, pvzId int, orderNumber string) (result domain.OrderWithProducts, err error) {
+	ctx, end := startSpan(ctx, "Order.CreateOrder")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	err = o.txManager.Do(ctx, func(ctx context.Context) error {
+		if _, err := o.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+			return err
+		}
+
+		products, err := o.repo.ListOrderableProducts(ctx, pvzId, orderNumber)
+		if err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			return usecases.ErrOrderEmpty
+		}
+
+		productIds := make([]int, len(products))
+		for i, product := range products {
+			productIds[i] = product.Id
+		}
+		order, err := o.repo.CreateOrder(ctx, domain.Order{
+			PvzId:       pvzId,
+			OrderNumber: orderNumber,
+			Status:      domain.OrderStatusAccepted,
+		}, productIds)
+		if err != nil {
+			return err
+		}
+		result = domain.OrderWithProducts{Order: order, Products: products}
+		return nil
+	})
+	if err != nil {
+		return domain.OrderWithProducts{}, err
+	}
+	return result, nil
+}
+
+func (o *Order) GetOrder(ctx context.Context, orderId int) (_ domain.OrderWithProducts, err error) {
+	ctx, end := startSpan(ctx, "Order.GetOrder")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return o.repo.GetOrder(ctx, orderId)
+}
+
+func (o *Order) GetOrders(ctx context.Context, pvzId int, status string) (_ []domain.Order, err error) {
+	ctx, end := startSpan(ctx, "Order.GetOrders")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if _, err := o.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+		return nil, err
+	}
+	return o.repo.ListOrders(ctx, pvzId, status)
+}
+
+// ChangeOrderStatus moves the order along its lifecycle; issuing it takes its
+// products out of the PVZ stock in the same transaction.
+func (o *Order) ChangeOrderStatus(ctx context.Context, orderId int, status string) (order domain.Order, err error) {
+	ctx, end := startSpan(ctx, "Order.ChangeOrderStatus")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	err = o.txManager.Do(ctx, func(ctx context.Context) error {
+		current, err := o.repo.GetOrder(ctx, orderId)
+		if err != nil {
+			return err
+		}
+		if !domain.CanChangeOrderStatus(current.Order.Status, status) {
+			return usecases.ErrInvalidTransition
+		}
+
+		order, err = o.repo.UpdateOrderStatus(ctx, orderId, current.Order.Status, status)
+		if errors.Is(err, repository.ErrOrderStatusChanged) {
+			return usecases.ErrInvalidTransition
+		} else if err != nil {
+			return err
+		}
+
+		if status == domain.OrderStatusIssued {
+			return o.repo.IssueOrderProducts(ctx, orderId)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return order, nil
+}
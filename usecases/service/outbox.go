@@ -0,0 +1,221 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/logger"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// dispatchLease names the lease that lets one instance at a time dispatch the outbox;
+// every consumer has a lease of its own, named by consumerLease.
+const dispatchLease = "dispatch"
+
+func consumerLease(consumer string) string {
+	return "deliver:" + consumer
+}
+
+// OutboxConsumer is a downstream system that gets every outbox event. Consumers are
+// relayed independently, so one that keeps failing does not hold up the others.
+type OutboxConsumer struct {
+	Name      string
+	Publisher usecases.Publisher
+}
+
+type OutboxRelay struct {
+	repo      repository.Outbox
+	consumers []OutboxConsumer
+	retry     RetryPolicy
+	batchSize int
+	// owner tells this instance apart from the others taking the leases.
+	owner string
+	lease time.Duration
+	// queued wakes the delivery loop of every consumer once events are dispatched to it.
+	queued map[string]chan struct{}
+}
+
+// NewOutboxRelay holds each of its leases for lease at a time; a lease is renewed before
+// every event, so it only has to outlast a single delivery.
+func NewOutboxRelay(repo repository.Outbox, consumers []OutboxConsumer, retry RetryPolicy, batchSize int, lease time.Duration) (*OutboxRelay, error) {
+	owner := make([]byte, 8)
+	if _, err := rand.Read(owner); err != nil {
+		return nil, err
+	}
+
+	queued := make(map[string]chan struct{}, len(consumers))
+	for _, consumer := range consumers {
+		queued[consumer.Name] = make(chan struct{}, 1)
+	}
+	return &OutboxRelay{
+		repo:      repo,
+		consumers: consumers,
+		retry:     retry,
+		batchSize: batchSize,
+		owner:     hex.EncodeToString(owner),
+		lease:     lease,
+		queued:    queued,
+	}, nil
+}
+
+// Dispatch queues one batch of pending events for every consumer and returns how many
+// were dispatched. While another instance holds the dispatch lease it dispatches nothing.
+func (o *OutboxRelay) Dispatch(ctx context.Context) (dispatched int, err error) {
+	if held, err := o.holdLease(ctx, dispatchLease); err != nil || !held {
+		return 0, err
+	}
+	events, err := o.repo.ListPending(ctx, o.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	consumers := make([]string, 0, len(o.consumers))
+	for _, consumer := range o.consumers {
+		consumers = append(consumers, consumer.Name)
+	}
+	for i, event := range events {
+		if i > 0 {
+			if held, err := o.holdLease(ctx, dispatchLease); err != nil || !held {
+				return dispatched, err
+			}
+		}
+		if err := o.repo.Dispatch(ctx, event.Id, consumers); err != nil {
+			return dispatched, err
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+// Deliver publishes one batch of the events queued for the consumer by id and returns how
+// many were delivered. A failed event is retried after the backoff of the retry policy and
+// holds back the later ones until then; out of attempts, it is marked failed and the consumer
+// moves on. Ids come from a sequence, and transactions can commit out of id order, so an
+// event may still be queued and delivered after one with a higher id: consumers must not
+// rely on the order. While another instance holds the lease of the consumer it delivers nothing.
+func (o *OutboxRelay) Deliver(ctx context.Context, consumer OutboxConsumer) (delivered int, err error) {
+	lease := consumerLease(consumer.Name)
+	if held, err := o.holdLease(ctx, lease); err != nil || !held {
+		return 0, err
+	}
+	deliveries, err := o.repo.ListQueued(ctx, consumer.Name, o.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, delivery := range deliveries {
+		if delivery.NextAttemptAt.After(time.Now()) {
+			return delivered, nil
+		}
+		if i > 0 {
+			if held, err := o.holdLease(ctx, lease); err != nil || !held {
+				return delivered, err
+			}
+		}
+
+		delivery, err := o.attempt(ctx, consumer, delivery)
+		if err != nil {
+			return delivered, err
+		}
+		switch delivery.Status {
+		case domain.DeliveryStatusDelivered:
+			delivered++
+		case domain.DeliveryStatusFailed:
+			logger.FromContext(ctx).Error("gave up delivering outbox event",
+				"consumer", consumer.Name, "eventId", delivery.Event.Id, "attempts", delivery.Attempts,
+				"error", delivery.LastError)
+		default:
+			logger.FromContext(ctx).Warn("failed to deliver outbox event, will retry",
+				"consumer", consumer.Name, "eventId", delivery.Event.Id, "attempts", delivery.Attempts,
+				"retryAt", delivery.NextAttemptAt, "error", delivery.LastError)
+			return delivered, nil
+		}
+	}
+	return delivered, nil
+}
+
+// attempt publishes the event to the consumer once and records the outcome. A failed publish
+// is not an error of attempt: it schedules the next retry or, out of attempts, fails the delivery.
+func (o *OutboxRelay) attempt(ctx context.Context, consumer OutboxConsumer, delivery domain.OutboxDelivery) (domain.OutboxDelivery, error) {
+	publishErr := consumer.Publisher.Publish(ctx, delivery.Event)
+
+	now := time.Now()
+	delivery.Attempts++
+	switch {
+	case publishErr == nil:
+		delivery.Status = domain.DeliveryStatusDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= o.retry.MaxAttempts:
+		delivery.Status = domain.DeliveryStatusFailed
+		delivery.LastError = publishErr.Error()
+	default:
+		delivery.Status = domain.DeliveryStatusPending
+		delivery.LastError = publishErr.Error()
+		delivery.NextAttemptAt = now.Add(o.retry.delay(delivery.Attempts))
+	}
+
+	if err := o.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return domain.OutboxDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func (o *OutboxRelay) holdLease(ctx context.Context, name string) (bool, error) {
+	now := time.Now()
+	return o.repo.Lease(ctx, name, o.owner, now, now.Add(o.lease))
+}
+
+// Run dispatches pending events and delivers them to every consumer right away and then
+// every interval until ctx is done. Each consumer is delivered to on its own goroutine.
+func (o *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	var wg sync.WaitGroup
+	for _, consumer := range o.consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			every(ctx, interval, o.queued[consumer.Name], func() {
+				if _, err := o.Deliver(ctx, consumer); err != nil {
+					logger.FromContext(ctx).Error("failed to deliver outbox events", "consumer", consumer.Name, "error", err)
+				}
+			})
+		}()
+	}
+
+	every(ctx, interval, nil, func() {
+		dispatched, err := o.Dispatch(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to dispatch outbox events", "error", err)
+		}
+		if dispatched > 0 {
+			for _, queued := range o.queued {
+				select {
+				case queued <- struct{}{}:
+				default:
+				}
+			}
+		}
+	})
+	wg.Wait()
+}
+
+// every calls fn right away and then every interval, or sooner when woken, until ctx is done.
+func every(ctx context.Context, interval time.Duration, wake <-chan struct{}, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
@@ -3,59 +3,181 @@ package service
 import (
 	"avito_test/domain"
 	"avito_test/repository"
+	"avito_test/repository/prometheus"
 	"avito_test/usecases"
+	"context"
+	"errors"
 	"strconv"
 )
 
 type Product struct {
-	productRepo   repository.Product
-	receptionRepo repository.Reception
-	pvzRepo       repository.Pvz
+	productRepo     repository.Product
+	productTypeRepo repository.ProductType
+	receptionRepo   repository.Reception
+	pvzRepo         repository.Pvz
+	txManager       repository.TxManager
+	metrics         *prometheus.Metrics
 }
 
-func NewProductService(productRepo repository.Product, receptionRepo repository.Reception, pvzRepo repository.Pvz) *Product {
-	return &Product{productRepo: productRepo, receptionRepo: receptionRepo, pvzRepo: pvzRepo}
+func NewProductService(productRepo repository.Product, productTypeRepo repository.ProductType, receptionRepo repository.Reception, pvzRepo repository.Pvz, txManager repository.TxManager, metrics *prometheus.Metrics) *Product {
+	return &Product{productRepo: productRepo, productTypeRepo: productTypeRepo, receptionRepo: receptionRepo, pvzRepo: pvzRepo, txManager: txManager, metrics: metrics}
 }
 
-func (p *Product) AddProduct(sort string, pvzId int) (domain.Product, error) {
-	if _, err := p.pvzRepo.GetPvz(pvzId); err != nil {
+func (p *Product) AddProduct(ctx context.Context, newProduct usecases.NewProduct, pvzId int) (product domain.Product, err error) {
+	ctx, end := startSpan(ctx, "Product.AddProduct")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	productType, err := p.productTypeRepo.GetProductType(ctx, newProduct.Type)
+	if errors.Is(err, repository.NotFound) {
+		return domain.Product{}, usecases.ErrInvalidProductType
+	} else if err != nil {
 		return domain.Product{}, err
 	}
 
-	lastReception, err := p.receptionRepo.GetLastReception(pvzId)
-	if err != nil {
-		return domain.Product{}, repository.NotFound
-	}
-	lastReceptionStatus := lastReception.Status
-	if lastReceptionStatus == "closed" {
-		return domain.Product{}, usecases.ErrAlreadyClosed
-	}
+	var pvz domain.Pvz
+	err = p.txManager.Do(ctx, func(ctx context.Context) error {
+		if pvz, err = p.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+			return err
+		}
+
+		lastReception, err := p.receptionRepo.GetLastReception(ctx, pvzId)
+		if err != nil {
+			return repository.NotFound
+		}
+		lastReceptionStatus := lastReception.Status
+		if lastReceptionStatus == "closed" {
+			return usecases.ErrAlreadyClosed
+		}
+		if err := p.checkCapacity(ctx, pvz, productType.Code); err != nil {
+			return err
+		}
+
+		product = domain.Product{
+			Type:        productType.Code,
+			TypeName:    productType.Name,
+			Barcode:     newProduct.Barcode,
+			OrderNumber: newProduct.OrderNumber,
+		}
+		if lastReception.Kind == domain.ReceptionKindCustomerReturn {
+			if err := p.returnDetails(ctx, newProduct, &product); err != nil {
+				return err
+			}
+		} else if newProduct.ReturnReason != "" || newProduct.OriginalProductId != 0 {
+			return usecases.ErrUnexpectedReturnDetails
+		}
 
-	product, err := p.productRepo.AddProduct(sort)
+		product, err = p.productRepo.AddProduct(ctx, product)
+		if err != nil {
+			return err
+		}
+		return receptionConflict(p.receptionRepo.AddProduct(ctx, pvzId, product.Id))
+	})
 	if err != nil {
 		return domain.Product{}, err
 	}
-	_ = p.receptionRepo.AddProduct(pvzId, product.Id)
-	return product, err
+	p.metrics.RecordProductAdded(product.Type, pvz.City)
+	return product, nil
 }
 
-func (p *Product) DeleteProduct(pvzId int) error {
-	if _, err := p.pvzRepo.GetPvz(pvzId); err != nil {
-		return repository.NotFound
+// checkCapacity runs after GetLastReception has locked the reception, so concurrent
+// intake at the same PVZ is counted one product at a time.
+func (p *Product) checkCapacity(ctx context.Context, pvz domain.Pvz, productType string) error {
+	if pvz.Capacity == 0 && len(pvz.TypeCapacity) == 0 {
+		return nil
 	}
-
-	lastReception, err := p.receptionRepo.GetLastReception(pvzId)
+	occupancy, err := p.pvzRepo.GetOccupancy(ctx, pvz.Id)
 	if err != nil {
-		return repository.NotFound
+		return err
+	}
+	if !occupancy.Fits(pvz, productType) {
+		return usecases.ErrCapacityExceeded
+	}
+	return nil
+}
+
+// returnDetails checks the reason and reference of a returned item and fills them
+// into product. An item referring to the original product takes its order number.
+func (p *Product) returnDetails(ctx context.Context, newProduct usecases.NewProduct, product *domain.Product) error {
+	if !domain.IsReturnReason(newProduct.ReturnReason) || (newProduct.OrderNumber == "" && newProduct.OriginalProductId == 0) {
+		return usecases.ErrReturnDetailsRequired
 	}
-	if lastReception.Status == "closed" {
-		return usecases.ErrAlreadyClosed
+	product.ReturnReason = newProduct.ReturnReason
+
+	if newProduct.OriginalProductId != 0 {
+		original, err := p.productRepo.GetProduct(ctx, newProduct.OriginalProductId)
+		if errors.Is(err, repository.NotFound) {
+			return usecases.ErrInvalidReturnReference
+		} else if err != nil {
+			return err
+		}
+		if original.IssuedAt == nil {
+			return usecases.ErrInvalidReturnReference
+		}
+		if product.OrderNumber == "" {
+			product.OrderNumber = original.OrderNumber
+		} else if original.OrderNumber != "" && original.OrderNumber != product.OrderNumber {
+			return usecases.ErrInvalidReturnReference
+		}
+		product.OriginalProductId = original.Id
 	}
-	productId, err := p.receptionRepo.DeleteProduct(pvzId)
+	return nil
+}
+
+// DeleteProduct removes the last scanned product, the LIFO undo of AddProduct.
+func (p *Product) DeleteProduct(ctx context.Context, pvzId int) (err error) {
+	ctx, end := startSpan(ctx, "Product.DeleteProduct")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return p.deleteProduct(ctx, pvzId, 0, domain.RemovalReasonLastScan)
+}
+
+func (p *Product) DeleteProductById(ctx context.Context, pvzId int, productId int, reason string) (err error) {
+	ctx, end := startSpan(ctx, "Product.DeleteProductById")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return p.deleteProduct(ctx, pvzId, productId, reason)
+}
+
+func (p *Product) deleteProduct(ctx context.Context, pvzId int, productId int, reason string) error {
+	var pvz domain.Pvz
+	var deleted domain.Product
+	err := p.txManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if pvz, err = p.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+			return repository.NotFound
+		}
+
+		lastReception, err := p.receptionRepo.GetLastReception(ctx, pvzId)
+		if err != nil {
+			return repository.NotFound
+		}
+		if lastReception.Status == "closed" {
+			return usecases.ErrAlreadyClosed
+		}
+		deletedId, err := p.receptionRepo.DeleteProduct(ctx, pvzId, productId, reason)
+		if err != nil {
+			return receptionConflict(err)
+		}
+		productIdInt, err := strconv.Atoi(deletedId)
+		if err != nil {
+			return err
+		}
+		if deleted, err = p.productRepo.GetProduct(ctx, productIdInt); err != nil {
+			return err
+		}
+		return p.productRepo.DeleteProduct(ctx, productIdInt)
+	})
 	if err != nil {
 		return err
 	}
-	productIdInt, _ := strconv.Atoi(productId)
-	err = p.productRepo.DeleteProduct(productIdInt)
-	return err
+	p.metrics.RecordProductDeleted(deleted.Type, pvz.City)
+	return nil
+}
+
+func (p *Product) GetProductByBarcode(ctx context.Context, barcode string) (_ domain.ProductLocation, err error) {
+	ctx, end := startSpan(ctx, "Product.GetProductByBarcode")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return p.productRepo.GetProductByBarcode(ctx, barcode)
 }
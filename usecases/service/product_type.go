@@ -0,0 +1,45 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+)
+
+type ProductType struct {
+	repo repository.ProductType
+}
+
+func NewProductTypeService(repo repository.ProductType) *ProductType {
+	return &ProductType{repo: repo}
+}
+
+func (p *ProductType) CreateProductType(ctx context.Context, code string, name string, storageDays int) (_ domain.ProductType, err error) {
+	ctx, end := startSpan(ctx, "ProductType.CreateProductType")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if storageDays <= 0 {
+		storageDays = domain.DefaultStorageDays
+	}
+	return p.repo.CreateProductType(ctx, domain.ProductType{Code: code, Name: name, StorageDays: storageDays})
+}
+
+func (p *ProductType) ListProductTypes(ctx context.Context) (_ []domain.ProductType, err error) {
+	ctx, end := startSpan(ctx, "ProductType.ListProductTypes")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return p.repo.ListProductTypes(ctx)
+}
+
+func (p *ProductType) SetStorageDays(ctx context.Context, code string, storageDays int) (_ domain.ProductType, err error) {
+	ctx, end := startSpan(ctx, "ProductType.SetStorageDays")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if storageDays <= 0 {
+		return domain.ProductType{}, usecases.ErrInvalidStorageDays
+	}
+	return p.repo.SetStorageDays(ctx, code, storageDays)
+}
@@ -3,27 +3,172 @@ package service
 import (
 	"avito_test/domain"
 	"avito_test/repository"
+	"avito_test/repository/prometheus"
 	"avito_test/usecases"
-	"time"
+	"context"
+	"encoding/base64"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
 )
 
+const cursorPrefix = "pvz:"
+
 type Pvz struct {
-	repo repository.Pvz
+	repo            repository.Pvz
+	cityRepo        repository.City
+	productTypeRepo repository.ProductType
+	txManager       repository.TxManager
+	metrics         *prometheus.Metrics
+}
+
+func NewPvzService(repo repository.Pvz, cityRepo repository.City, productTypeRepo repository.ProductType, txManager repository.TxManager, metrics *prometheus.Metrics) *Pvz {
+	return &Pvz{repo: repo, cityRepo: cityRepo, productTypeRepo: productTypeRepo, txManager: txManager, metrics: metrics}
+}
+
+// OpenPvz only accepts enabled cities; PVZs already open in a disabled city keep working.
+func (p *Pvz) OpenPvz(ctx context.Context, city string) (_ domain.Pvz, err error) {
+	ctx, end := startSpan(ctx, "Pvz.OpenPvz")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	registered, err := p.cityRepo.GetCityByName(ctx, city)
+	if errors.Is(err, repository.NotFound) {
+		return domain.Pvz{}, usecases.ErrInvalidCity
+	} else if err != nil {
+		return domain.Pvz{}, err
+	}
+	if !registered.Enabled {
+		return domain.Pvz{}, usecases.ErrCityDisabled
+	}
+
+	pvz, err := p.repo.OpenPvz(ctx, city)
+	if err != nil {
+		return domain.Pvz{}, err
+	}
+	p.metrics.RecordPVZCreated()
+	return pvz, nil
 }
 
-func NewPvzService(repo repository.Pvz) *Pvz {
-	return &Pvz{repo: repo}
+func (p *Pvz) GetPvz(ctx context.Context, pvzId int) (_ domain.Pvz, err error) {
+	ctx, end := startSpan(ctx, "Pvz.GetPvz")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return p.repo.GetPvz(ctx, pvzId)
+}
+
+// SetCapacity accepts product types by code or name and stores them by code. Lowering
+// the capacity below the current occupancy only blocks further intake.
+func (p *Pvz) SetCapacity(ctx context.Context, pvzId int, capacity int, typeCapacity map[string]int) (pvz domain.Pvz, err error) {
+	ctx, end := startSpan(ctx, "Pvz.SetCapacity")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if capacity < 0 {
+		return domain.Pvz{}, usecases.ErrInvalidCapacity
+	}
+	byCode := make(map[string]int, len(typeCapacity))
+	for codeOrName, limit := range typeCapacity {
+		if limit <= 0 {
+			return domain.Pvz{}, usecases.ErrInvalidCapacity
+		}
+		productType, err := p.productTypeRepo.GetProductType(ctx, codeOrName)
+		if errors.Is(err, repository.NotFound) {
+			return domain.Pvz{}, usecases.ErrInvalidProductType
+		} else if err != nil {
+			return domain.Pvz{}, err
+		}
+		byCode[productType.Code] = limit
+	}
+
+	err = p.txManager.Do(ctx, func(ctx context.Context) error {
+		pvz, err = p.repo.SetCapacity(ctx, pvzId, capacity, byCode)
+		return err
+	})
+	if err != nil {
+		return domain.Pvz{}, err
+	}
+	return pvz, nil
+}
+
+// GetPvzListWithFilter asks the repository for one PVZ more than the limit to find out
+// whether a next page exists without a second query. The limit is bounded by
+// usecases.MaxPvzListLimit.
+func (p *Pvz) GetPvzListWithFilter(ctx context.Context, filter usecases.PvzFilter) (_ usecases.PvzPage, err error) {
+	ctx, end := startSpan(ctx, "Pvz.GetPvzListWithFilter")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if filter.Limit <= 0 {
+		filter.Limit = usecases.DefaultPvzListLimit
+	}
+	filter.Limit = min(filter.Limit, usecases.MaxPvzListLimit)
+	// Pages this far are empty anyway; bounding them keeps the offset from overflowing.
+	filter.Page = min(max(filter.Page, 1), math.MaxInt32)
+
+	listFilter := repository.PvzListFilter{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Offset:    (filter.Page - 1) * filter.Limit,
+		Limit:     filter.Limit + 1,
+	}
+	if filter.Cursor != "" {
+		listFilter.AfterId, err = decodeCursor(filter.Cursor)
+		if err != nil {
+			return usecases.PvzPage{}, err
+		}
+		listFilter.Offset = 0
+	}
+
+	items, err := p.repo.GetPvzListWithFilter(ctx, listFilter)
+	if err != nil {
+		return usecases.PvzPage{}, err
+	}
+
+	total, err := p.repo.CountPvzWithFilter(ctx, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return usecases.PvzPage{}, err
+	}
+
+	for i := range items {
+		splitReturns(&items[i])
+	}
+
+	page := usecases.PvzPage{Items: items, Total: total}
+	if len(items) > filter.Limit {
+		page.Items = items[:filter.Limit]
+		page.NextCursor = encodeCursor(page.Items[len(page.Items)-1].Pvz.Id)
+	}
+	return page, nil
 }
 
-func (p *Pvz) OpenPvz(city string) (domain.Pvz, error) {
-	return p.repo.OpenPvz(city)
+// splitReturns moves customer returns out of the receptions the repository lists.
+func splitReturns(item *usecases.PvzWithReceptions) {
+	deliveries := make([]domain.ReceptionWithProducts, 0, len(item.Receptions))
+	item.Returns = make([]domain.ReceptionWithProducts, 0)
+	for _, rec := range item.Receptions {
+		if rec.Reception.Kind == domain.ReceptionKindCustomerReturn {
+			item.Returns = append(item.Returns, rec)
+		} else {
+			deliveries = append(deliveries, rec)
+		}
+	}
+	item.Receptions = deliveries
 }
 
-func (p *Pvz) GetPvz(pvzId int) (domain.Pvz, error) {
-	return p.repo.GetPvz(pvzId)
+func encodeCursor(pvzId int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(pvzId)))
 }
 
-func (p *Pvz) GetPvzListWithFilter(startDate, endDate *time.Time, page, limit int) ([]usecases.PvzWithReceptions, error) {
-	offset := (page - 1) * limit
-	return p.repo.GetPvzListWithFilter(startDate, endDate, offset, limit)
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, usecases.ErrInvalidCursor
+	}
+	pvzId, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || pvzId < 0 {
+		return 0, usecases.ErrInvalidCursor
+	}
+	return pvzId, nil
 }
@@ -3,53 +3,119 @@ package service
 import (
 	"avito_test/domain"
 	"avito_test/repository"
+	"avito_test/repository/prometheus"
 	"avito_test/usecases"
+	"context"
+	"time"
 )
 
 type Reception struct {
-	repo    repository.Reception
-	pvzRepo repository.Pvz
+	repo      repository.Reception
+	pvzRepo   repository.Pvz
+	txManager repository.TxManager
+	metrics   *prometheus.Metrics
 }
 
-func NewReceptionService(receptionRepo repository.Reception, pvzRepo repository.Pvz) *Reception {
+func NewReceptionService(receptionRepo repository.Reception, pvzRepo repository.Pvz, txManager repository.TxManager, metrics *prometheus.Metrics) *Reception {
 	return &Reception{
-		repo:    receptionRepo,
-		pvzRepo: pvzRepo,
+		repo:      receptionRepo,
+		pvzRepo:   pvzRepo,
+		txManager: txManager,
+		metrics:   metrics,
 	}
 }
 
-func (r *Reception) StartReception(pvzId int) (domain.Reception, error) {
-	if err := r.CheckPvz(pvzId); err != nil {
-		return domain.Reception{}, err
-	}
+func (r *Reception) StartReception(ctx context.Context, pvzId int, kind string) (reception domain.Reception, err error) {
+	ctx, end := startSpan(ctx, "Reception.StartReception")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
 
-	LastReception, _ := r.repo.GetLastReception(pvzId)
-	LastReceptionStatus := LastReception.Status
-	if LastReceptionStatus == "in_progress" {
-		return domain.Reception{}, usecases.ErrUnclosedReception
+	if !domain.IsReceptionKind(kind) {
+		return domain.Reception{}, usecases.ErrInvalidReceptionKind
 	}
 
-	return r.repo.StartReception(pvzId)
-}
+	var pvz domain.Pvz
+	err = r.txManager.Do(ctx, func(ctx context.Context) error {
+		if pvz, err = r.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+			return err
+		}
 
-func (r *Reception) CloseReception(pvzId int) (domain.Reception, error) {
-	if err := r.CheckPvz(pvzId); err != nil {
+		LastReception, _ := r.repo.GetLastReception(ctx, pvzId)
+		LastReceptionStatus := LastReception.Status
+		if LastReceptionStatus == "in_progress" {
+			return usecases.ErrUnclosedReception
+		}
+
+		reception, err = r.repo.StartReception(ctx, pvzId, kind)
+		return receptionConflict(err)
+	})
+	if err != nil {
 		return domain.Reception{}, err
 	}
+	r.metrics.RecordReceptionStarted(pvz.City)
+	return reception, nil
+}
 
-	LastReception, _ := r.repo.GetLastReception(pvzId)
-	LastReceptionStatus := LastReception.Status
-	if LastReceptionStatus == "closed" {
-		return domain.Reception{}, usecases.ErrAlreadyClosed
-	}
+func (r *Reception) CloseReception(ctx context.Context, pvzId int) (reception domain.Reception, err error) {
+	ctx, end := startSpan(ctx, "Reception.CloseReception")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	var pvz domain.Pvz
+	err = r.txManager.Do(ctx, func(ctx context.Context) error {
+		if pvz, err = r.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+			return err
+		}
+
+		LastReception, _ := r.repo.GetLastReception(ctx, pvzId)
+		LastReceptionStatus := LastReception.Status
+		if LastReceptionStatus == "closed" {
+			return usecases.ErrAlreadyClosed
+		}
 
-	return r.repo.CloseReception(pvzId)
+		reception, err = r.repo.CloseReception(ctx, pvzId)
+		return receptionConflict(err)
+	})
+	if err != nil {
+		return domain.Reception{}, err
+	}
+	r.metrics.RecordReceptionClosed(pvz.City, time.Since(reception.StartDate))
+	return reception, nil
 }
 
-func (r *Reception) CheckPvz(pvzId int) error {
-	_, err := r.pvzRepo.GetPvz(pvzId)
+func (r *Reception) CheckPvz(ctx context.Context, pvzId int) (err error) {
+	ctx, end := startSpan(ctx, "Reception.CheckPvz")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	_, err = r.pvzRepo.GetPvz(ctx, pvzId)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (r *Reception) GetReceptions(ctx context.Context, pvzId int, filter usecases.ReceptionFilter) (_ []domain.Reception, err error) {
+	ctx, end := startSpan(ctx, "Reception.GetReceptions")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if err := r.CheckPvz(ctx, pvzId); err != nil {
+		return nil, err
+	}
+	return r.repo.ListReceptions(ctx, pvzId, filter)
+}
+
+func (r *Reception) GetReception(ctx context.Context, receptionId int) (_ domain.ReceptionWithProducts, err error) {
+	ctx, end := startSpan(ctx, "Reception.GetReception")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return r.repo.GetReception(ctx, receptionId)
+}
+
+func (r *Reception) GetRemovals(ctx context.Context, receptionId int) (_ []domain.ProductRemoval, err error) {
+	ctx, end := startSpan(ctx, "Reception.GetRemovals")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return r.repo.ListRemovals(ctx, receptionId)
+}
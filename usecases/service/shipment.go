@@ -0,0 +1,140 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/logger"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"errors"
+	"time"
+)
+
+type Shipment struct {
+	repo      repository.Shipment
+	orderRepo repository.Order
+	pvzRepo   repository.Pvz
+	txManager repository.TxManager
+}
+
+func NewShipmentService(shipmentRepo repository.Shipment, orderRepo repository.Order, pvzRepo repository.Pvz, txManager repository.TxManager) *Shipment {
+	return &Shipment{repo: shipmentRepo, orderRepo: orderRepo, pvzRepo: pvzRepo, txManager: txManager}
+}
+
+func (s *Shipment) MarkOverdue(ctx context.Context) (_ int, err error) {
+	ctx, end := startSpan(ctx, "Shipment.MarkOverdue")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return s.repo.MarkOverdue(ctx, time.Now())
+}
+
+// RunOverdueMarker marks overdue products right away and then every interval until ctx is done.
+func (s *Shipment) RunOverdueMarker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if marked, err := s.MarkOverdue(ctx); err != nil {
+			logger.FromContext(ctx).Error("failed to mark overdue products", "error", err)
+		} else if marked > 0 {
+			logger.FromContext(ctx).Info("marked overdue products", "count", marked)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Shipment) GetOverdue(ctx context.Context, pvzId int) (_ []domain.Product, err error) {
+	ctx, end := startSpan(ctx, "Shipment.GetOverdue")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if _, err := s.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+		return nil, err
+	}
+	return s.repo.ListOverdueProducts(ctx, pvzId)
+}
+
+// CreateShipment also returns the pending orders the shipped products belonged to,
+// since those can no longer be handed out.
+func (s *Shipment) CreateShipment(ctx context.Context, pvzId int, productIds []int) (result domain.ReturnShipmentWithProducts, err error) {
+	ctx, end := startSpan(ctx, "Shipment.CreateShipment")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	err = s.txManager.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.pvzRepo.GetPvz(ctx, pvzId); err != nil {
+			return err
+		}
+
+		overdue, err := s.repo.ListOverdueProducts(ctx, pvzId)
+		if err != nil {
+			return err
+		}
+		products, err := selectOverdue(overdue, productIds)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, len(products))
+		for i, product := range products {
+			ids[i] = product.Id
+		}
+		shipment, err := s.repo.CreateShipment(ctx, pvzId, ids)
+		if errors.Is(err, repository.ErrProductNotInStock) {
+			return usecases.ErrNotOverdue
+		} else if err != nil {
+			return err
+		}
+		if err := s.orderRepo.ReturnOrdersOfProducts(ctx, ids); err != nil {
+			return err
+		}
+
+		for i := range products {
+			products[i].ShippedAt = &shipment.CreatedAt
+		}
+		result = domain.ReturnShipmentWithProducts{Shipment: shipment, Products: products}
+		return nil
+	})
+	if err != nil {
+		return domain.ReturnShipmentWithProducts{}, err
+	}
+	return result, nil
+}
+
+func (s *Shipment) GetShipment(ctx context.Context, shipmentId int) (_ domain.ReturnShipmentWithProducts, err error) {
+	ctx, end := startSpan(ctx, "Shipment.GetShipment")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return s.repo.GetShipment(ctx, shipmentId)
+}
+
+// selectOverdue picks the requested products out of the overdue ones, or all of
+// them when none are requested.
+func selectOverdue(overdue []domain.Product, productIds []int) ([]domain.Product, error) {
+	if len(productIds) == 0 {
+		if len(overdue) == 0 {
+			return nil, usecases.ErrShipmentEmpty
+		}
+		return overdue, nil
+	}
+
+	byId := make(map[int]domain.Product, len(overdue))
+	for _, product := range overdue {
+		byId[product.Id] = product
+	}
+	selected := make([]domain.Product, 0, len(productIds))
+	for _, id := range productIds {
+		product, ok := byId[id]
+		if !ok {
+			return nil, usecases.ErrNotOverdue
+		}
+		delete(byId, id)
+		selected = append(selected, product)
+	}
+	return selected, nil
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"avito_test/pkg/tracing"
+	"context"
+)
+
+// startSpan opens the span of a usecase method. The returned func ends it with the
+// final error of the method, so it is deferred before wrapTimeout.
+func startSpan(ctx context.Context, name string) (context.Context, func(*error)) {
+	ctx, span := tracing.Start(ctx, name)
+	return ctx, func(err *error) { tracing.End(span, *err) }
+}
@@ -4,6 +4,7 @@ import (
 	"avito_test/config"
 	"avito_test/domain"
 	"avito_test/repository"
+	"context"
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -33,14 +34,22 @@ func (u *User) GetToken(id string, role string) (string, error) {
 	return tokenString, nil
 }
 
-func (u *User) Register(email string, password string, role string) (domain.User, error) {
+func (u *User) Register(ctx context.Context, email string, password string, role string) (_ domain.User, err error) {
+	ctx, end := startSpan(ctx, "User.Register")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
 	hashPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	hashPasswordStr := string(hashPassword)
-	return u.repo.Register(email, hashPasswordStr, role)
+	return u.repo.Register(ctx, email, hashPasswordStr, role)
 }
 
-func (u *User) Login(email string, password string) (string, error) {
-	user, err := u.repo.Login(email)
+func (u *User) Login(ctx context.Context, email string, password string) (_ string, err error) {
+	ctx, end := startSpan(ctx, "User.Login")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	user, err := u.repo.Login(ctx, email)
 	if err != nil {
 		return "", errors.New("wrong email")
 	}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/logger"
+	"avito_test/repository"
+	"avito_test/usecases"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// deliveryLogLimit caps how many deliveries ListDeliveries returns.
+const deliveryLogLimit = 100
+
+// RetryPolicy spaces out failed delivery attempts: the n-th retry waits Backoff*2^(n-1),
+// at most MaxBackoff, and a delivery fails for good after MaxAttempts attempts.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+func (p RetryPolicy) delay(attempts int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+type Webhook struct {
+	repo      repository.Webhook
+	pvzRepo   repository.Pvz
+	cityRepo  repository.City
+	sender    usecases.WebhookSender
+	retry     RetryPolicy
+	batchSize int
+	lease     time.Duration
+}
+
+// NewWebhookService claims due deliveries batchSize at a time for lease, so that no other
+// instance sends them meanwhile; lease has to outlast sending the whole batch.
+func NewWebhookService(repo repository.Webhook, pvzRepo repository.Pvz, cityRepo repository.City, sender usecases.WebhookSender, retry RetryPolicy, batchSize int, lease time.Duration) *Webhook {
+	return &Webhook{repo: repo, pvzRepo: pvzRepo, cityRepo: cityRepo, sender: sender, retry: retry, batchSize: batchSize, lease: lease}
+}
+
+func (s *Webhook) CreateWebhook(ctx context.Context, webhook domain.Webhook) (_ domain.Webhook, err error) {
+	ctx, end := startSpan(ctx, "Webhook.CreateWebhook")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if target, err := url.Parse(webhook.URL); err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return domain.Webhook{}, usecases.ErrInvalidWebhookURL
+	}
+	for _, eventType := range webhook.EventTypes {
+		if !slices.Contains(domain.WebhookEventTypes, eventType) {
+			return domain.Webhook{}, usecases.ErrInvalidWebhookFilter
+		}
+	}
+	if webhook.City != "" {
+		if _, err := s.cityRepo.GetCityByName(ctx, webhook.City); errors.Is(err, repository.NotFound) {
+			return domain.Webhook{}, usecases.ErrInvalidWebhookFilter
+		} else if err != nil {
+			return domain.Webhook{}, err
+		}
+	}
+	if webhook.Secret == "" {
+		if webhook.Secret, err = newSecret(); err != nil {
+			return domain.Webhook{}, err
+		}
+	}
+
+	created, err := s.repo.CreateWebhook(ctx, webhook)
+	if errors.Is(err, repository.NotFound) {
+		return domain.Webhook{}, usecases.ErrInvalidWebhookFilter
+	}
+	return created, err
+}
+
+func (s *Webhook) ListWebhooks(ctx context.Context) (_ []domain.Webhook, err error) {
+	ctx, end := startSpan(ctx, "Webhook.ListWebhooks")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	webhooks, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+func (s *Webhook) GetWebhook(ctx context.Context, webhookId int) (_ domain.Webhook, err error) {
+	ctx, end := startSpan(ctx, "Webhook.GetWebhook")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	webhook, err := s.repo.GetWebhook(ctx, webhookId)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (s *Webhook) DeleteWebhook(ctx context.Context, webhookId int) (err error) {
+	ctx, end := startSpan(ctx, "Webhook.DeleteWebhook")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+	return s.repo.DeleteWebhook(ctx, webhookId)
+}
+
+func (s *Webhook) ListDeliveries(ctx context.Context, webhookId int) (_ []domain.WebhookDelivery, err error) {
+	ctx, end := startSpan(ctx, "Webhook.ListDeliveries")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	if _, err := s.repo.GetWebhook(ctx, webhookId); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, webhookId, deliveryLogLimit)
+}
+
+func (s *Webhook) Redeliver(ctx context.Context, webhookId int, deliveryId int) (_ domain.WebhookDelivery, err error) {
+	ctx, end := startSpan(ctx, "Webhook.Redeliver")
+	defer end(&err)
+	defer wrapTimeout(ctx, &err)
+
+	webhook, err := s.repo.GetWebhook(ctx, webhookId)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	delivery, err := s.repo.GetDelivery(ctx, deliveryId)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	if delivery.WebhookId != webhookId {
+		return domain.WebhookDelivery{}, repository.NotFound
+	}
+	return s.attempt(ctx, webhook, delivery)
+}
+
+// Publish queues a delivery of the event for every webhook whose filters it passes,
+// which makes the service a Publisher for the outbox relay.
+func (s *Webhook) Publish(ctx context.Context, event domain.Event) error {
+	if !slices.Contains(domain.WebhookEventTypes, event.Type) {
+		return nil
+	}
+
+	webhooks, err := s.repo.ListWebhooks(ctx)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	pvz, err := s.pvzRepo.GetPvz(ctx, event.PvzId)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Matches(event, pvz.City) {
+			continue
+		}
+		err := s.repo.AddDelivery(ctx, domain.WebhookDelivery{
+			WebhookId:     webhook.Id,
+			EventId:       event.Id,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		// The webhook may have been deleted since it was listed.
+		if err != nil && !errors.Is(err, repository.NotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue claims a batch of due deliveries, makes one attempt for each and returns how
+// many succeeded. Deliveries it does not get to are retried once the claim runs out.
+func (s *Webhook) DeliverDue(ctx context.Context) (delivered int, err error) {
+	now := time.Now()
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, now, now.Add(s.lease), s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[int]domain.Webhook)
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookId]
+		if !ok {
+			if webhook, err = s.repo.GetWebhook(ctx, delivery.WebhookId); errors.Is(err, repository.NotFound) {
+				continue
+			} else if err != nil {
+				return delivered, err
+			}
+			webhooks[webhook.Id] = webhook
+		}
+
+		delivery, err := s.attempt(ctx, webhook, delivery)
+		if err != nil {
+			return delivered, err
+		}
+		if delivery.Status == domain.DeliveryStatusDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// RunDeliveries sends due deliveries right away and then every interval until ctx is done.
+func (s *Webhook) RunDeliveries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DeliverDue(ctx); err != nil {
+			logger.FromContext(ctx).Error("failed to deliver webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// attempt sends the delivery once and records the outcome. A failed send is not an
+// error of attempt: it schedules the next retry or, out of attempts, fails the delivery.
+func (s *Webhook) attempt(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	status, sendErr := s.sender.Send(ctx, webhook, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.DeliveryStatusDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.retry.MaxAttempts:
+		delivery.Status = domain.DeliveryStatusFailed
+		delivery.LastError = sendErr.Error()
+	default:
+		delivery.Status = domain.DeliveryStatusPending
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = now.Add(s.retry.delay(delivery.Attempts))
+	}
+
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/repository/mocks"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditService_Record(t *testing.T) {
+	tests := []struct {
+		name            string
+		targetIds       map[string]string
+		payload         string
+		expectedTargets map[string]string
+		expectedPayload string
+	}{
+		{
+			name:            "ids in the body become targets",
+			targetIds:       map[string]string{},
+			payload:         `{"pvzId": 3, "type": "electronics", "originalProductId": "12"}`,
+			expectedTargets: map[string]string{"pvzId": "3", "originalProductId": "12"},
+			expectedPayload: `{"pvzId": 3, "type": "electronics", "originalProductId": "12"}`,
+		},
+		{
+			name:            "route ids win over the body",
+			targetIds:       map[string]string{"pvzId": "1"},
+			payload:         `{"pvzId": 2}`,
+			expectedTargets: map[string]string{"pvzId": "1"},
+			expectedPayload: `{"pvzId": 2}`,
+		},
+		{
+			name:            "credentials are dropped",
+			payload:         `{"email": "a@b.c", "password": "qwerty", "settings": {"apiToken": "x", "secret": "y", "url": "u"}}`,
+			expectedPayload: `{"email": "a@b.c", "settings": {"url": "u"}}`,
+		},
+		{
+			name:            "long values are cut",
+			payload:         `{"barcode": "` + strings.Repeat("7", 200) + `", "productIds": [1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22]}`,
+			expectedPayload: `{"barcode": "` + strings.Repeat("7", 128) + `…", "productIds": [1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,"… 2 more"]}`,
+		},
+		{
+			name:    "body that is not an object",
+			payload: `[1, 2]`,
+		},
+		{
+			name: "no body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Audit)
+			var stored domain.AuditEntry
+			mockRepo.On("AddEntry", mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(0).(domain.AuditEntry)
+			}).Return(nil)
+			s := service.NewAuditService(mockRepo)
+
+			err := s.Record(context.Background(), domain.AuditEntry{
+				ActorId:   "1",
+				Role:      "employee",
+				Action:    domain.AuditProductAdd,
+				TargetIds: tt.targetIds,
+				Payload:   json.RawMessage(tt.payload),
+				Status:    201,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, domain.AuditProductAdd, stored.Action)
+			assert.False(t, stored.CreatedAt.IsZero())
+			if len(tt.expectedTargets) > 0 {
+				assert.Equal(t, tt.expectedTargets, stored.TargetIds)
+			} else {
+				assert.Empty(t, stored.TargetIds)
+			}
+			if tt.expectedPayload != "" {
+				assert.JSONEq(t, tt.expectedPayload, string(stored.Payload))
+			} else {
+				assert.Empty(t, stored.Payload)
+			}
+		})
+	}
+}
+
+func TestAuditService_List(t *testing.T) {
+	mockRepo := new(mocks.Audit)
+	from := time.Now().Add(-time.Hour)
+	filter := repository.AuditFilter{ActorId: "1", PvzId: 2, From: &from, Offset: 20, Limit: 10}
+	entries := []domain.AuditEntry{{Id: 5, Action: domain.AuditPvzOpen}}
+	mockRepo.On("ListEntries", filter).Return(entries, nil)
+	mockRepo.On("CountEntries", filter).Return(21, nil)
+	s := service.NewAuditService(mockRepo)
+
+	page, err := s.List(context.Background(), usecases.AuditFilter{ActorId: "1", PvzId: 2, From: &from, Page: 3, Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, entries, page.Items)
+	assert.Equal(t, 21, page.Total)
+	mockRepo.AssertExpectations(t)
+}
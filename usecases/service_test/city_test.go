@@ -0,0 +1,62 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/repository/mocks"
+	"avito_test/usecases/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCityService_UpdateCity(t *testing.T) {
+	disabled := false
+	renamed := "Казань-2"
+
+	tests := []struct {
+		name        string
+		newName     *string
+		enabled     *bool
+		mockGetErr  error
+		expected    domain.City
+		expectedErr error
+	}{
+		{
+			name:     "disable keeps name",
+			enabled:  &disabled,
+			expected: domain.City{Id: 3, Name: "Казань", Enabled: false},
+		},
+		{
+			name:     "rename keeps enabled",
+			newName:  &renamed,
+			expected: domain.City{Id: 3, Name: "Казань-2", Enabled: true},
+		},
+		{
+			name:        "city not found",
+			mockGetErr:  repository.NotFound,
+			expectedErr: repository.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.City)
+			mockRepo.On("GetCity", 3).Return(domain.City{Id: 3, Name: "Казань", Enabled: true}, tt.mockGetErr)
+			if tt.mockGetErr == nil {
+				mockRepo.On("UpdateCity", tt.expected).Return(tt.expected, nil)
+			}
+
+			cityService := service.NewCityService(mockRepo)
+			city, err := cityService.UpdateCity(context.Background(), 3, tt.newName, tt.enabled)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, city)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
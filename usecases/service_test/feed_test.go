@@ -0,0 +1,124 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/pkg/broker"
+	"avito_test/repository"
+	"avito_test/repository/mocks"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestReceptionFeedService_Subscribe(t *testing.T) {
+	mockReceptionRepo := new(mocks.Reception)
+	mockPvzRepo := new(mocks.Pvz)
+	mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1}, nil)
+	mockReceptionRepo.On("GetLastReception", 1).Return(domain.Reception{Id: 3, PvzId: 1, Status: "in_progress"}, nil)
+	mockReceptionRepo.On("GetReception", 3).Return(domain.ReceptionWithProducts{
+		Reception: domain.Reception{Id: 3, Status: "in_progress"},
+		Products:  []domain.Product{{Id: 1, Type: "shoes"}},
+	}, nil).Twice()
+	// The progress of an event is counted once for all the clients of the PVZ.
+	mockReceptionRepo.On("GetReception", 3).Return(domain.ReceptionWithProducts{
+		Reception: domain.Reception{Id: 3, Status: "in_progress"},
+		Products:  []domain.Product{{Id: 1, Type: "shoes"}, {Id: 2, Type: "shoes"}, {Id: 4, Type: "clothes"}},
+	}, nil).Once()
+
+	b := broker.NewBroker()
+	feed := service.NewReceptionFeedService(b, mockReceptionRepo, mockPvzRepo, new(mocks.Outbox))
+	ctx, cancel := context.WithCancel(context.Background())
+	first, err := feed.Subscribe(ctx, 1, 0)
+	require.NoError(t, err)
+	second, err := feed.Subscribe(ctx, 1, 0)
+	require.NoError(t, err)
+
+	for _, progress := range []<-chan domain.ReceptionProgress{first, second} {
+		snapshot := <-progress
+		assert.Equal(t, usecases.ProgressSnapshot, snapshot.Type)
+		assert.Equal(t, map[string]int{"shoes": 1}, snapshot.Counts)
+	}
+
+	payload, _ := json.Marshal(domain.ProductEvent{ReceptionId: 3, ProductId: 4, Type: "clothes"})
+	require.NoError(t, b.Publish(ctx, domain.Event{Id: 9, Type: domain.EventPvzOpened, PvzId: 1, Payload: json.RawMessage(`{}`)}))
+	require.NoError(t, b.Publish(ctx, domain.Event{Id: 10, Type: domain.EventProductAdded, PvzId: 1, Payload: payload}))
+
+	for _, progress := range []<-chan domain.ReceptionProgress{first, second} {
+		added := <-progress
+		assert.Equal(t, domain.ReceptionProgress{
+			EventId:     10,
+			Type:        domain.EventProductAdded,
+			ReceptionId: 3,
+			Status:      "in_progress",
+			ProductId:   4,
+			ProductType: "clothes",
+			Counts:      map[string]int{"shoes": 2, "clothes": 1},
+			Total:       3,
+		}, added)
+	}
+
+	cancel()
+	for _, progress := range []<-chan domain.ReceptionProgress{first, second} {
+		select {
+		case _, open := <-progress:
+			assert.False(t, open)
+		case <-time.After(time.Second):
+			t.Fatal("feed was not closed")
+		}
+	}
+	mockReceptionRepo.AssertExpectations(t)
+}
+
+func TestReceptionFeedService_Subscribe_Resume(t *testing.T) {
+	added := func(id int, productId int) domain.Event {
+		payload, _ := json.Marshal(domain.ProductEvent{ReceptionId: 3, ProductId: productId, Type: "shoes"})
+		return domain.Event{Id: id, Type: domain.EventProductAdded, PvzId: 1, Payload: payload}
+	}
+	mockReceptionRepo := new(mocks.Reception)
+	mockPvzRepo := new(mocks.Pvz)
+	mockOutboxRepo := new(mocks.Outbox)
+	mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1}, nil)
+	mockOutboxRepo.On("ListEvents", 1, 8, 501).Return([]domain.Event{
+		{Id: 9, Type: domain.EventPvzCapacityChanged, PvzId: 1, Payload: json.RawMessage(`{}`)},
+		added(10, 1),
+		added(11, 2),
+	}, nil)
+	mockReceptionRepo.On("GetReception", 3).Return(domain.ReceptionWithProducts{
+		Reception: domain.Reception{Id: 3, Status: "in_progress"},
+		Products:  []domain.Product{{Id: 1, Type: "shoes"}, {Id: 2, Type: "shoes"}},
+	}, nil)
+
+	b := broker.NewBroker()
+	feed := service.NewReceptionFeedService(b, mockReceptionRepo, mockPvzRepo, mockOutboxRepo)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	progress, err := feed.Subscribe(ctx, 1, 8)
+	require.NoError(t, err)
+
+	// The events after the last one the client saw come first, without a snapshot.
+	assert.Equal(t, 10, (<-progress).EventId)
+	assert.Equal(t, 11, (<-progress).EventId)
+
+	// An event replayed already is not sent again when it comes live.
+	require.NoError(t, b.Publish(ctx, added(11, 2)))
+	require.NoError(t, b.Publish(ctx, added(12, 5)))
+	assert.Equal(t, 12, (<-progress).EventId)
+
+	mockReceptionRepo.AssertNotCalled(t, "GetLastReception", 1)
+	mockOutboxRepo.AssertExpectations(t)
+}
+
+func TestReceptionFeedService_Subscribe_UnknownPvz(t *testing.T) {
+	mockPvzRepo := new(mocks.Pvz)
+	mockPvzRepo.On("GetPvz", 2).Return(domain.Pvz{}, repository.NotFound)
+
+	feed := service.NewReceptionFeedService(broker.NewBroker(), new(mocks.Reception), mockPvzRepo, new(mocks.Outbox))
+	_, err := feed.Subscribe(context.Background(), 2, 0)
+
+	assert.ErrorIs(t, err, repository.NotFound)
+}
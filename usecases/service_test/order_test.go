@@ -0,0 +1,138 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/repository/mocks"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOrderService_CreateOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockPvzErr   error
+		mockProducts []domain.Product
+		expectedErr  error
+	}{
+		{
+			name:         "order from stock",
+			mockProducts: []domain.Product{{Id: 3, OrderNumber: "ORD-1"}, {Id: 5, OrderNumber: "ORD-1"}},
+		},
+		{
+			name:         "nothing in stock",
+			mockProducts: []domain.Product{},
+			expectedErr:  usecases.ErrOrderEmpty,
+		},
+		{
+			name:        "pvz not found",
+			mockPvzErr:  repository.NotFound,
+			expectedErr: repository.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockOrderRepo := new(mocks.Order)
+			mockPvzRepo := new(mocks.Pvz)
+
+			mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1}, tt.mockPvzErr)
+			if tt.mockPvzErr == nil {
+				mockOrderRepo.On("ListOrderableProducts", 1, "ORD-1").Return(tt.mockProducts, nil)
+			}
+			if len(tt.mockProducts) > 0 {
+				mockOrderRepo.On("CreateOrder", domain.Order{PvzId: 1, OrderNumber: "ORD-1", Status: domain.OrderStatusAccepted}, []int{3, 5}).
+					Return(domain.Order{Id: 7, PvzId: 1, OrderNumber: "ORD-1", Status: domain.OrderStatusAccepted}, nil)
+			}
+
+			orderService := service.NewOrderService(mockOrderRepo, mockPvzRepo, &mocks.TxManager{})
+			order, err := orderService.CreateOrder(context.Background(), 1, "ORD-1")
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 7, order.Order.Id)
+				assert.Equal(t, tt.mockProducts, order.Products)
+			}
+			mockPvzRepo.AssertExpectations(t)
+			mockOrderRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderService_ChangeOrderStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		currentStatus string
+		status        string
+		mockUpdateErr error
+		expectIssue   bool
+		expectedErr   error
+	}{
+		{
+			name:          "ready for pickup",
+			currentStatus: domain.OrderStatusAccepted,
+			status:        domain.OrderStatusReadyForPickup,
+		},
+		{
+			name:          "issue takes products out of stock",
+			currentStatus: domain.OrderStatusReadyForPickup,
+			status:        domain.OrderStatusIssued,
+			expectIssue:   true,
+		},
+		{
+			name:          "refused at pickup",
+			currentStatus: domain.OrderStatusReadyForPickup,
+			status:        domain.OrderStatusRefused,
+		},
+		{
+			name:          "issue before ready",
+			currentStatus: domain.OrderStatusAccepted,
+			status:        domain.OrderStatusIssued,
+			expectedErr:   usecases.ErrInvalidTransition,
+		},
+		{
+			name:          "already issued",
+			currentStatus: domain.OrderStatusIssued,
+			status:        domain.OrderStatusReturned,
+			expectedErr:   usecases.ErrInvalidTransition,
+		},
+		{
+			name:          "changed concurrently",
+			currentStatus: domain.OrderStatusReadyForPickup,
+			status:        domain.OrderStatusIssued,
+			mockUpdateErr: repository.ErrOrderStatusChanged,
+			expectedErr:   usecases.ErrInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockOrderRepo := new(mocks.Order)
+
+			mockOrderRepo.On("GetOrder", 7).Return(domain.OrderWithProducts{Order: domain.Order{Id: 7, Status: tt.currentStatus}}, nil)
+			if domain.CanChangeOrderStatus(tt.currentStatus, tt.status) {
+				mockOrderRepo.On("UpdateOrderStatus", 7, tt.currentStatus, tt.status).
+					Return(domain.Order{Id: 7, Status: tt.status}, tt.mockUpdateErr)
+			}
+			if tt.expectIssue {
+				mockOrderRepo.On("IssueOrderProducts", 7).Return(nil)
+			}
+
+			orderService := service.NewOrderService(mockOrderRepo, new(mocks.Pvz), &mocks.TxManager{})
+			order, err := orderService.ChangeOrderStatus(context.Background(), 7, tt.status)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.status, order.Status)
+			}
+			mockOrderRepo.AssertExpectations(t)
+		})
+	}
+}
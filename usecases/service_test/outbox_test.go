@@ -0,0 +1,157 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository/mocks"
+	usecasesMocks "avito_test/usecases/mocks"
+	"avito_test/usecases/service"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var outboxRetry = service.RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour}
+
+func TestOutboxRelay_Dispatch(t *testing.T) {
+	events := []domain.Event{
+		{Id: 1, Type: domain.EventPvzOpened, PvzId: 1},
+		{Id: 2, Type: domain.EventReceptionStarted, PvzId: 1},
+	}
+	consumers := []service.OutboxConsumer{{Name: "a"}, {Name: "b"}}
+
+	tests := []struct {
+		name           string
+		leasedByOther  bool
+		wantDispatched int
+	}{
+		{
+			name:           "queues every event for every consumer",
+			wantDispatched: 2,
+		},
+		{
+			name:          "another instance holds the lease",
+			leasedByOther: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Outbox)
+			mockRepo.On("Lease", "dispatch", mock.Anything, mock.Anything, mock.Anything).Return(!tt.leasedByOther, nil)
+			if !tt.leasedByOther {
+				mockRepo.On("ListPending", 10).Return(events, nil)
+				for _, event := range events {
+					mockRepo.On("Dispatch", event.Id, []string{"a", "b"}).Return(nil)
+				}
+			}
+
+			relay, err := service.NewOutboxRelay(mockRepo, consumers, outboxRetry, 10, time.Minute)
+			require.NoError(t, err)
+			dispatched, err := relay.Dispatch(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDispatched, dispatched)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOutboxRelay_Deliver(t *testing.T) {
+	now := time.Now()
+	queued := func(id int, attempts int, nextAttemptAt time.Time) domain.OutboxDelivery {
+		return domain.OutboxDelivery{
+			Consumer:      "a",
+			Event:         domain.Event{Id: id, Type: domain.EventProductAdded, PvzId: 1},
+			Status:        domain.DeliveryStatusPending,
+			Attempts:      attempts,
+			NextAttemptAt: nextAttemptAt,
+		}
+	}
+	withStatus := func(id int, status string) any {
+		return mock.MatchedBy(func(delivery domain.OutboxDelivery) bool {
+			return delivery.Event.Id == id && delivery.Status == status
+		})
+	}
+	publishErr := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		deliveries    []domain.OutboxDelivery
+		leasedByOther bool
+		setup         func(repo *mocks.Outbox, publisher *usecasesMocks.Publisher)
+		wantDelivered int
+	}{
+		{
+			name:       "all delivered",
+			deliveries: []domain.OutboxDelivery{queued(1, 0, now), queued(2, 0, now)},
+			setup: func(repo *mocks.Outbox, publisher *usecasesMocks.Publisher) {
+				publisher.On("Publish", mock.Anything).Return(nil)
+				repo.On("UpdateDelivery", withStatus(1, domain.DeliveryStatusDelivered)).Return(nil)
+				repo.On("UpdateDelivery", withStatus(2, domain.DeliveryStatusDelivered)).Return(nil)
+			},
+			wantDelivered: 2,
+		},
+		{
+			name:       "a failure is retried later and holds back the next events",
+			deliveries: []domain.OutboxDelivery{queued(1, 0, now), queued(2, 0, now), queued(3, 0, now)},
+			setup: func(repo *mocks.Outbox, publisher *usecasesMocks.Publisher) {
+				publisher.On("Publish", domain.Event{Id: 1, Type: domain.EventProductAdded, PvzId: 1}).Return(nil)
+				publisher.On("Publish", domain.Event{Id: 2, Type: domain.EventProductAdded, PvzId: 1}).Return(publishErr)
+				repo.On("UpdateDelivery", withStatus(1, domain.DeliveryStatusDelivered)).Return(nil)
+				repo.On("UpdateDelivery", mock.MatchedBy(func(delivery domain.OutboxDelivery) bool {
+					return delivery.Event.Id == 2 && delivery.Status == domain.DeliveryStatusPending &&
+						delivery.Attempts == 1 && delivery.LastError == publishErr.Error() &&
+						delivery.NextAttemptAt.After(now.Add(59*time.Second))
+				})).Return(nil)
+			},
+			wantDelivered: 1,
+		},
+		{
+			name:       "out of attempts the event fails and the next ones go on",
+			deliveries: []domain.OutboxDelivery{queued(1, 2, now), queued(2, 0, now)},
+			setup: func(repo *mocks.Outbox, publisher *usecasesMocks.Publisher) {
+				publisher.On("Publish", domain.Event{Id: 1, Type: domain.EventProductAdded, PvzId: 1}).Return(publishErr)
+				publisher.On("Publish", domain.Event{Id: 2, Type: domain.EventProductAdded, PvzId: 1}).Return(nil)
+				repo.On("UpdateDelivery", withStatus(1, domain.DeliveryStatusFailed)).Return(nil)
+				repo.On("UpdateDelivery", withStatus(2, domain.DeliveryStatusDelivered)).Return(nil)
+			},
+			wantDelivered: 1,
+		},
+		{
+			name:       "waits for the backoff of the oldest event",
+			deliveries: []domain.OutboxDelivery{queued(1, 1, now.Add(time.Minute)), queued(2, 0, now)},
+		},
+		{
+			name:          "another instance holds the lease",
+			leasedByOther: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Outbox)
+			mockPublisher := new(usecasesMocks.Publisher)
+			mockRepo.On("Lease", "deliver:a", mock.Anything, mock.Anything, mock.Anything).Return(!tt.leasedByOther, nil)
+			if !tt.leasedByOther {
+				mockRepo.On("ListQueued", "a", 10).Return(tt.deliveries, nil)
+			}
+			if tt.setup != nil {
+				tt.setup(mockRepo, mockPublisher)
+			}
+
+			consumer := service.OutboxConsumer{Name: "a", Publisher: mockPublisher}
+			relay, err := service.NewOutboxRelay(mockRepo, []service.OutboxConsumer{consumer}, outboxRetry, 10, time.Minute)
+			require.NoError(t, err)
+			delivered, err := relay.Deliver(context.Background(), consumer)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDelivered, delivered)
+			mockRepo.AssertExpectations(t)
+			mockPublisher.AssertExpectations(t)
+		})
+	}
+}
@@ -2,19 +2,30 @@ package service
 
 import (
 	"avito_test/domain"
+	"avito_test/pkg/testutils"
 	"avito_test/repository"
 	"avito_test/repository/mocks"
+	"avito_test/repository/prometheus"
 	"avito_test/usecases"
 	"avito_test/usecases/service"
+	"context"
+	"errors"
+	client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
+	"time"
 )
 
+var shoes = domain.ProductType{Code: "shoes", Name: "обувь"}
+
 func TestProductService_AddProduct(t *testing.T) {
 	tests := []struct {
 		name             string
 		sort             string
 		pvzId            int
+		mockTypeErr      error
 		mockPvz          domain.Pvz
 		mockPvzErr       error
 		mockReception    domain.Reception
@@ -26,20 +37,38 @@ func TestProductService_AddProduct(t *testing.T) {
 	}{
 		{
 			name:             "successful add product",
-			sort:             "apple",
+			sort:             "обувь",
 			pvzId:            1,
 			mockPvz:          domain.Pvz{Id: 1},
 			mockPvzErr:       nil,
 			mockReception:    domain.Reception{Status: "in_progress"},
 			mockReceptionErr: nil,
-			mockProduct:      domain.Product{Id: 1, Type: "apple"},
+			mockProduct:      domain.Product{Id: 1, Type: "shoes", TypeName: "обувь", Barcode: "4601234567890", OrderNumber: "ORD-1"},
 			mockProductErr:   nil,
 			wantErr:          false,
 		},
 		{
-			name:        "pvz not found",
+			name:           "barcode already in stock",
+			sort:           "shoes",
+			pvzId:          1,
+			mockPvz:        domain.Pvz{Id: 1},
+			mockReception:  domain.Reception{Status: "in_progress"},
+			mockProductErr: repository.ErrBarcodeAlreadyExists,
+			wantErr:        true,
+			expectedErr:    repository.ErrBarcodeAlreadyExists,
+		},
+		{
+			name:        "unknown product type",
 			sort:        "apple",
 			pvzId:       1,
+			mockTypeErr: repository.NotFound,
+			wantErr:     true,
+			expectedErr: usecases.ErrInvalidProductType,
+		},
+		{
+			name:        "pvz not found",
+			sort:        "обувь",
+			pvzId:       1,
 			mockPvz:     domain.Pvz{},
 			mockPvzErr:  repository.NotFound,
 			wantErr:     true,
@@ -47,7 +76,7 @@ func TestProductService_AddProduct(t *testing.T) {
 		},
 		{
 			name:             "reception not found",
-			sort:             "apple",
+			sort:             "обувь",
 			pvzId:            1,
 			mockPvz:          domain.Pvz{Id: 1},
 			mockPvzErr:       nil,
@@ -58,7 +87,7 @@ func TestProductService_AddProduct(t *testing.T) {
 		},
 		{
 			name:             "reception already closed",
-			sort:             "apple",
+			sort:             "обувь",
 			pvzId:            1,
 			mockPvz:          domain.Pvz{Id: 1},
 			mockPvzErr:       nil,
@@ -74,20 +103,30 @@ func TestProductService_AddProduct(t *testing.T) {
 			mockPvzRepo := new(mocks.Pvz)
 			mockReceptionRepo := new(mocks.Reception)
 			mockProductRepo := new(mocks.Product)
+			mockProductTypeRepo := new(mocks.ProductType)
 
-			mockPvzRepo.On("GetPvz", tt.pvzId).Return(tt.mockPvz, tt.mockPvzErr)
-			if tt.mockPvzErr == nil {
+			if tt.mockTypeErr != nil {
+				mockProductTypeRepo.On("GetProductType", tt.sort).Return(domain.ProductType{}, tt.mockTypeErr)
+			} else {
+				mockProductTypeRepo.On("GetProductType", tt.sort).Return(shoes, nil)
+				mockPvzRepo.On("GetPvz", tt.pvzId).Return(tt.mockPvz, tt.mockPvzErr)
+			}
+			if tt.mockTypeErr == nil && tt.mockPvzErr == nil {
 				mockReceptionRepo.On("GetLastReception", tt.pvzId).Return(tt.mockReception, tt.mockReceptionErr)
 			}
 			if tt.mockReceptionErr == nil && tt.mockReception.Status == "in_progress" {
-				mockProductRepo.On("AddProduct", tt.sort).Return(tt.mockProduct, tt.mockProductErr)
+				mockProductRepo.On("AddProduct", domain.Product{
+					Type: shoes.Code, TypeName: shoes.Name, Barcode: "4601234567890", OrderNumber: "ORD-1",
+				}).Return(tt.mockProduct, tt.mockProductErr)
 				if tt.mockProductErr == nil {
 					mockReceptionRepo.On("AddProduct", tt.pvzId, tt.mockProduct.Id).Return(nil)
 				}
 			}
 
-			productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockPvzRepo)
-			product, err := productService.AddProduct(tt.sort, tt.pvzId)
+			productService := service.NewProductService(mockProductRepo, mockProductTypeRepo, mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+			product, err := productService.AddProduct(context.Background(), usecases.NewProduct{
+				Type: tt.sort, Barcode: "4601234567890", OrderNumber: "ORD-1",
+			}, tt.pvzId)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -102,6 +141,7 @@ func TestProductService_AddProduct(t *testing.T) {
 			mockPvzRepo.AssertExpectations(t)
 			mockReceptionRepo.AssertExpectations(t)
 			mockProductRepo.AssertExpectations(t)
+			mockProductTypeRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -155,14 +195,15 @@ func TestProductService_DeleteProduct(t *testing.T) {
 				mockReceptionRepo.On("GetLastReception", tt.pvzId).Return(tt.mockReception, tt.mockReceptionErr)
 			}
 			if tt.mockReceptionErr == nil && tt.mockReception.Status == "in_progress" {
-				mockReceptionRepo.On("DeleteProduct", tt.pvzId).Return(tt.mockDeleteProductId, tt.mockDeleteProductErr)
+				mockReceptionRepo.On("DeleteProduct", tt.pvzId, 0, domain.RemovalReasonLastScan).Return(tt.mockDeleteProductId, tt.mockDeleteProductErr)
 				if tt.mockDeleteProductErr == nil {
+					mockProductRepo.On("GetProduct", 1).Return(testutils.MockProduct(), nil)
 					mockProductRepo.On("DeleteProduct", 1).Return(tt.mockProductErr)
 				}
 			}
 
-			productService := service.NewProductService(mockProductRepo, mockReceptionRepo, mockPvzRepo)
-			err := productService.DeleteProduct(tt.pvzId)
+			productService := service.NewProductService(mockProductRepo, new(mocks.ProductType), mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+			err := productService.DeleteProduct(context.Background(), tt.pvzId)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -179,3 +220,235 @@ func TestProductService_DeleteProduct(t *testing.T) {
 		})
 	}
 }
+
+func TestProductService_AddProduct_CustomerReturn(t *testing.T) {
+	issuedAt := time.Now()
+
+	tests := []struct {
+		name         string
+		newProduct   usecases.NewProduct
+		mockOriginal *domain.Product
+		stored       domain.Product
+		expectedErr  error
+	}{
+		{
+			name:       "reference by order number",
+			newProduct: usecases.NewProduct{Type: "shoes", OrderNumber: "ORD-1", ReturnReason: domain.ReturnReasonDefective},
+			stored:     domain.Product{Type: "shoes", TypeName: "обувь", OrderNumber: "ORD-1", ReturnReason: domain.ReturnReasonDefective},
+		},
+		{
+			name:         "order number taken from the original product",
+			newProduct:   usecases.NewProduct{Type: "shoes", ReturnReason: domain.ReturnReasonWrongItem, OriginalProductId: 3},
+			mockOriginal: &domain.Product{Id: 3, OrderNumber: "ORD-1", IssuedAt: &issuedAt},
+			stored: domain.Product{
+				Type: "shoes", TypeName: "обувь", OrderNumber: "ORD-1", ReturnReason: domain.ReturnReasonWrongItem, OriginalProductId: 3,
+			},
+		},
+		{
+			name:        "missing reason",
+			newProduct:  usecases.NewProduct{Type: "shoes", OrderNumber: "ORD-1"},
+			expectedErr: usecases.ErrReturnDetailsRequired,
+		},
+		{
+			name:        "missing reference",
+			newProduct:  usecases.NewProduct{Type: "shoes", ReturnReason: domain.ReturnReasonChangedMind},
+			expectedErr: usecases.ErrReturnDetailsRequired,
+		},
+		{
+			name:         "original product still in stock",
+			newProduct:   usecases.NewProduct{Type: "shoes", ReturnReason: domain.ReturnReasonDefective, OriginalProductId: 3},
+			mockOriginal: &domain.Product{Id: 3, OrderNumber: "ORD-1"},
+			expectedErr:  usecases.ErrInvalidReturnReference,
+		},
+		{
+			name:         "original product from another order",
+			newProduct:   usecases.NewProduct{Type: "shoes", OrderNumber: "ORD-2", ReturnReason: domain.ReturnReasonDefective, OriginalProductId: 3},
+			mockOriginal: &domain.Product{Id: 3, OrderNumber: "ORD-1", IssuedAt: &issuedAt},
+			expectedErr:  usecases.ErrInvalidReturnReference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPvzRepo := new(mocks.Pvz)
+			mockReceptionRepo := new(mocks.Reception)
+			mockProductRepo := new(mocks.Product)
+			mockProductTypeRepo := new(mocks.ProductType)
+
+			mockProductTypeRepo.On("GetProductType", "shoes").Return(shoes, nil)
+			mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1}, nil)
+			mockReceptionRepo.On("GetLastReception", 1).
+				Return(domain.Reception{Status: "in_progress", Kind: domain.ReceptionKindCustomerReturn}, nil)
+			if tt.mockOriginal != nil {
+				mockProductRepo.On("GetProduct", tt.mockOriginal.Id).Return(*tt.mockOriginal, nil)
+			}
+			if tt.expectedErr == nil {
+				added := tt.stored
+				added.Id = 7
+				mockProductRepo.On("AddProduct", tt.stored).Return(added, nil)
+				mockReceptionRepo.On("AddProduct", 1, 7).Return(nil)
+			}
+
+			productService := service.NewProductService(mockProductRepo, mockProductTypeRepo, mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+			product, err := productService.AddProduct(context.Background(), tt.newProduct, 1)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 7, product.Id)
+			}
+			mockReceptionRepo.AssertExpectations(t)
+			mockProductRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductService_AddProduct_LinkError(t *testing.T) {
+	mockPvzRepo := new(mocks.Pvz)
+	mockReceptionRepo := new(mocks.Reception)
+	mockProductRepo := new(mocks.Product)
+	mockProductTypeRepo := new(mocks.ProductType)
+
+	mockProductTypeRepo.On("GetProductType", "обувь").Return(shoes, nil)
+	mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1}, nil)
+	mockReceptionRepo.On("GetLastReception", 1).Return(domain.Reception{Status: "in_progress"}, nil)
+	mockProductRepo.On("AddProduct", domain.Product{Type: "shoes", TypeName: "обувь"}).Return(domain.Product{Id: 7, Type: "shoes"}, nil)
+	mockReceptionRepo.On("AddProduct", 1, 7).Return(errors.New("insert failed"))
+
+	productService := service.NewProductService(mockProductRepo, mockProductTypeRepo, mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+	product, err := productService.AddProduct(context.Background(), usecases.NewProduct{Type: "обувь"}, 1)
+
+	assert.Error(t, err)
+	assert.Equal(t, domain.Product{}, product)
+	mockReceptionRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestProductService_AddProduct_Capacity(t *testing.T) {
+	tests := []struct {
+		name        string
+		pvz         domain.Pvz
+		occupancy   domain.Occupancy
+		expectedErr error
+	}{
+		{
+			name:      "room left",
+			pvz:       domain.Pvz{Id: 1, Capacity: 10, TypeCapacity: map[string]int{"shoes": 3}},
+			occupancy: domain.Occupancy{Total: 9, ByType: map[string]int{"shoes": 2}},
+		},
+		{
+			name:        "pvz is full",
+			pvz:         domain.Pvz{Id: 1, Capacity: 10},
+			occupancy:   domain.Occupancy{Total: 10, ByType: map[string]int{"clothes": 10}},
+			expectedErr: usecases.ErrCapacityExceeded,
+		},
+		{
+			name:        "type limit reached",
+			pvz:         domain.Pvz{Id: 1, TypeCapacity: map[string]int{"shoes": 3}},
+			occupancy:   domain.Occupancy{Total: 3, ByType: map[string]int{"shoes": 3}},
+			expectedErr: usecases.ErrCapacityExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPvzRepo := new(mocks.Pvz)
+			mockReceptionRepo := new(mocks.Reception)
+			mockProductRepo := new(mocks.Product)
+			mockProductTypeRepo := new(mocks.ProductType)
+
+			mockProductTypeRepo.On("GetProductType", "shoes").Return(shoes, nil)
+			mockPvzRepo.On("GetPvz", 1).Return(tt.pvz, nil)
+			mockReceptionRepo.On("GetLastReception", 1).Return(domain.Reception{Status: "in_progress"}, nil)
+			mockPvzRepo.On("GetOccupancy", 1).Return(tt.occupancy, nil)
+			if tt.expectedErr == nil {
+				mockProductRepo.On("AddProduct", domain.Product{Type: "shoes", TypeName: "обувь"}).Return(domain.Product{Id: 7, Type: "shoes"}, nil)
+				mockReceptionRepo.On("AddProduct", 1, 7).Return(nil)
+			}
+
+			productService := service.NewProductService(mockProductRepo, mockProductTypeRepo, mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+			_, err := productService.AddProduct(context.Background(), usecases.NewProduct{Type: "shoes"}, 1)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockPvzRepo.AssertExpectations(t)
+			mockReceptionRepo.AssertExpectations(t)
+			mockProductRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductService_DeleteProductById(t *testing.T) {
+	tests := []struct {
+		name                 string
+		mockDeleteProductErr error
+		expectedErr          error
+	}{
+		{name: "successful delete product", mockDeleteProductErr: nil},
+		{name: "product not in reception", mockDeleteProductErr: repository.ErrProductNotFound, expectedErr: repository.ErrProductNotFound},
+		{name: "reception closed concurrently", mockDeleteProductErr: repository.ErrReceptionClosed, expectedErr: usecases.ErrAlreadyClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPvzRepo := new(mocks.Pvz)
+			mockReceptionRepo := new(mocks.Reception)
+			mockProductRepo := new(mocks.Product)
+
+			mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1}, nil)
+			mockReceptionRepo.On("GetLastReception", 1).Return(domain.Reception{Status: "in_progress"}, nil)
+			mockReceptionRepo.On("DeleteProduct", 1, 5, domain.RemovalReasonMisScan).Return("5", tt.mockDeleteProductErr)
+			if tt.mockDeleteProductErr == nil {
+				mockProductRepo.On("GetProduct", 5).Return(domain.Product{Id: 5, Type: "shoes"}, nil)
+				mockProductRepo.On("DeleteProduct", 5).Return(nil)
+			}
+
+			productService := service.NewProductService(mockProductRepo, new(mocks.ProductType), mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+			err := productService.DeleteProductById(context.Background(), 1, 5, domain.RemovalReasonMisScan)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockReceptionRepo.AssertExpectations(t)
+			mockProductRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductService_Metrics(t *testing.T) {
+	registry := client.NewRegistry()
+	mockPvzRepo := new(mocks.Pvz)
+	mockReceptionRepo := new(mocks.Reception)
+	mockProductRepo := new(mocks.Product)
+	mockProductTypeRepo := new(mocks.ProductType)
+
+	mockProductTypeRepo.On("GetProductType", "shoes").Return(shoes, nil)
+	mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1, City: "Москва"}, nil)
+	mockReceptionRepo.On("GetLastReception", 1).Return(domain.Reception{Status: "in_progress"}, nil)
+	mockProductRepo.On("AddProduct", domain.Product{Type: "shoes", TypeName: "обувь"}).Return(domain.Product{Id: 7, Type: "shoes"}, nil)
+	mockReceptionRepo.On("AddProduct", 1, 7).Return(nil)
+	mockReceptionRepo.On("DeleteProduct", 1, 0, domain.RemovalReasonLastScan).Return("7", nil)
+	mockProductRepo.On("GetProduct", 7).Return(domain.Product{Id: 7, Type: "shoes"}, nil)
+	mockProductRepo.On("DeleteProduct", 7).Return(nil)
+
+	productService := service.NewProductService(mockProductRepo, mockProductTypeRepo, mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, prometheus.NewMetrics(registry, nil))
+	_, err := productService.AddProduct(context.Background(), usecases.NewProduct{Type: "shoes"}, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, productService.DeleteProduct(context.Background(), 1))
+
+	expected := `
+# HELP products_added_total Total number of products added
+# TYPE products_added_total counter
+products_added_total{city="Москва",type="shoes"} 1
+# HELP products_deleted_total Total number of products deleted from receptions
+# TYPE products_deleted_total counter
+products_deleted_total{city="Москва",type="shoes"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "products_added_total", "products_deleted_total"))
+}
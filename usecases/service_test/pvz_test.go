@@ -2,47 +2,79 @@ package service
 
 import (
 	"avito_test/domain"
+	"avito_test/pkg/testutils"
+	"avito_test/repository"
 	"avito_test/repository/mocks"
+	"avito_test/usecases"
 	"avito_test/usecases/service"
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestPvzService_OpenPvz(t *testing.T) {
 	tests := []struct {
-		name    string
-		city    string
-		mockPvz domain.Pvz
-		mockErr error
-		wantErr bool
+		name        string
+		city        string
+		mockCity    domain.City
+		mockCityErr error
+		mockPvz     domain.Pvz
+		mockErr     error
+		wantErr     bool
+		expectedErr error
 	}{
 		{
-			name:    "successful open pvz",
-			city:    "Moscow",
-			mockPvz: domain.Pvz{Id: 1, City: "Moscow"},
-			mockErr: nil,
-			wantErr: false,
+			name:     "successful open pvz",
+			city:     "Moscow",
+			mockCity: domain.City{Id: 1, Name: "Moscow", Enabled: true},
+			mockPvz:  domain.Pvz{Id: 1, City: "Moscow"},
+			mockErr:  nil,
+			wantErr:  false,
 		},
 		{
-			name:    "open pvz error",
-			city:    "Moscow",
-			mockPvz: domain.Pvz{},
-			mockErr: errors.New("repository error"),
-			wantErr: true,
+			name:     "open pvz error",
+			city:     "Moscow",
+			mockCity: domain.City{Id: 1, Name: "Moscow", Enabled: true},
+			mockPvz:  domain.Pvz{},
+			mockErr:  errors.New("repository error"),
+			wantErr:  true,
+		},
+		{
+			name:        "unknown city",
+			city:        "Novosibirsk",
+			mockCityErr: repository.NotFound,
+			wantErr:     true,
+			expectedErr: usecases.ErrInvalidCity,
+		},
+		{
+			name:        "disabled city",
+			city:        "Kazan",
+			mockCity:    domain.City{Id: 3, Name: "Kazan", Enabled: false},
+			wantErr:     true,
+			expectedErr: usecases.ErrCityDisabled,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(mocks.Pvz)
-			mockRepo.On("OpenPvz", tt.city).Return(tt.mockPvz, tt.mockErr)
+			mockCityRepo := new(mocks.City)
+			mockCityRepo.On("GetCityByName", tt.city).Return(tt.mockCity, tt.mockCityErr)
+			if tt.mockCity.Enabled {
+				mockRepo.On("OpenPvz", tt.city).Return(tt.mockPvz, tt.mockErr)
+			}
 
-			pvzService := service.NewPvzService(mockRepo)
-			pvz, err := pvzService.OpenPvz(tt.city)
+			pvzService := service.NewPvzService(mockRepo, mockCityRepo, new(mocks.ProductType), &mocks.TxManager{}, testutils.Metrics())
+			pvz, err := pvzService.OpenPvz(context.Background(), tt.city)
 
 			if tt.wantErr {
 				assert.Error(t, err)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.mockPvz, pvz)
@@ -81,8 +113,8 @@ func TestPvzService_GetPvz(t *testing.T) {
 			mockRepo := new(mocks.Pvz)
 			mockRepo.On("GetPvz", tt.pvzId).Return(tt.mockPvz, tt.mockErr)
 
-			pvzService := service.NewPvzService(mockRepo)
-			pvz, err := pvzService.GetPvz(tt.pvzId)
+			pvzService := service.NewPvzService(mockRepo, new(mocks.City), new(mocks.ProductType), &mocks.TxManager{}, testutils.Metrics())
+			pvz, err := pvzService.GetPvz(context.Background(), tt.pvzId)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -94,3 +126,153 @@ func TestPvzService_GetPvz(t *testing.T) {
 		})
 	}
 }
+
+func TestPvzService_SetCapacity(t *testing.T) {
+	tests := []struct {
+		name         string
+		capacity     int
+		typeCapacity map[string]int
+		expectedErr  error
+	}{
+		{
+			name:         "type given by name is stored by code",
+			capacity:     100,
+			typeCapacity: map[string]int{"обувь": 10},
+		},
+		{
+			name:        "negative capacity",
+			capacity:    -1,
+			expectedErr: usecases.ErrInvalidCapacity,
+		},
+		{
+			name:         "zero type limit",
+			typeCapacity: map[string]int{"обувь": 0},
+			expectedErr:  usecases.ErrInvalidCapacity,
+		},
+		{
+			name:         "unknown type",
+			typeCapacity: map[string]int{"apple": 5},
+			expectedErr:  usecases.ErrInvalidProductType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Pvz)
+			mockProductTypeRepo := new(mocks.ProductType)
+			mockProductTypeRepo.On("GetProductType", "обувь").Return(domain.ProductType{Code: "shoes", Name: "обувь"}, nil).Maybe()
+			mockProductTypeRepo.On("GetProductType", "apple").Return(domain.ProductType{}, repository.NotFound).Maybe()
+			if tt.expectedErr == nil {
+				mockRepo.On("SetCapacity", 1, 100, map[string]int{"shoes": 10}).
+					Return(domain.Pvz{Id: 1, Capacity: 100, TypeCapacity: map[string]int{"shoes": 10}}, nil)
+			}
+
+			pvzService := service.NewPvzService(mockRepo, new(mocks.City), mockProductTypeRepo, &mocks.TxManager{}, testutils.Metrics())
+			pvz, err := pvzService.SetCapacity(context.Background(), 1, tt.capacity, tt.typeCapacity)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 100, pvz.Capacity)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPvzService_OpenPvz_Timeout(t *testing.T) {
+	mockRepo := new(mocks.Pvz)
+	mockCityRepo := new(mocks.City)
+	mockCityRepo.On("GetCityByName", "Moscow").Return(domain.City{Id: 1, Name: "Moscow", Enabled: true}, nil)
+	mockRepo.On("OpenPvz", "Moscow").Return(domain.Pvz{}, context.DeadlineExceeded)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	pvzService := service.NewPvzService(mockRepo, mockCityRepo, new(mocks.ProductType), &mocks.TxManager{}, testutils.Metrics())
+	_, err := pvzService.OpenPvz(ctx, "Moscow")
+
+	assert.ErrorIs(t, err, usecases.ErrTimeout)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPvzService_GetPvzListWithFilter(t *testing.T) {
+	items := []usecases.PvzWithReceptions{
+		{Pvz: domain.Pvz{Id: 4}},
+		{Pvz: domain.Pvz{Id: 7}},
+		{Pvz: domain.Pvz{Id: 9}},
+	}
+
+	mockRepo := new(mocks.Pvz)
+	mockRepo.On("GetPvzListWithFilter", repository.PvzListFilter{Offset: 2, Limit: 3}).Return(items, nil)
+	mockRepo.On("CountPvzWithFilter", (*time.Time)(nil), (*time.Time)(nil)).Return(12, nil)
+
+	pvzService := service.NewPvzService(mockRepo, new(mocks.City), new(mocks.ProductType), &mocks.TxManager{}, testutils.Metrics())
+	page, err := pvzService.GetPvzListWithFilter(context.Background(), usecases.PvzFilter{Page: 2, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, items[:2], page.Items)
+	assert.Equal(t, 12, page.Total)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mockRepo.On("GetPvzListWithFilter", repository.PvzListFilter{AfterId: 7, Limit: 3}).Return(items[2:], nil)
+
+	next, err := pvzService.GetPvzListWithFilter(context.Background(), usecases.PvzFilter{Page: 2, Limit: 2, Cursor: page.NextCursor})
+
+	assert.NoError(t, err)
+	assert.Equal(t, items[2:], next.Items)
+	assert.Empty(t, next.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPvzService_GetPvzListWithFilter_Bounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter usecases.PvzFilter
+		want   repository.PvzListFilter
+	}{
+		{
+			name:   "defaults",
+			filter: usecases.PvzFilter{},
+			want:   repository.PvzListFilter{Offset: 0, Limit: usecases.DefaultPvzListLimit + 1},
+		},
+		{
+			name:   "limit above the maximum",
+			filter: usecases.PvzFilter{Page: 2, Limit: math.MaxInt},
+			want:   repository.PvzListFilter{Offset: usecases.MaxPvzListLimit, Limit: usecases.MaxPvzListLimit + 1},
+		},
+		{
+			name:   "page past any offset",
+			filter: usecases.PvzFilter{Page: math.MaxInt, Limit: usecases.MaxPvzListLimit},
+			want: repository.PvzListFilter{
+				Offset: (math.MaxInt32 - 1) * usecases.MaxPvzListLimit,
+				Limit:  usecases.MaxPvzListLimit + 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Pvz)
+			mockRepo.On("GetPvzListWithFilter", tt.want).Return([]usecases.PvzWithReceptions{}, nil)
+			mockRepo.On("CountPvzWithFilter", (*time.Time)(nil), (*time.Time)(nil)).Return(0, nil)
+
+			pvzService := service.NewPvzService(mockRepo, new(mocks.City), new(mocks.ProductType), &mocks.TxManager{}, testutils.Metrics())
+			_, err := pvzService.GetPvzListWithFilter(context.Background(), tt.filter)
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPvzService_GetPvzListWithFilter_InvalidCursor(t *testing.T) {
+	mockRepo := new(mocks.Pvz)
+
+	pvzService := service.NewPvzService(mockRepo, new(mocks.City), new(mocks.ProductType), &mocks.TxManager{}, testutils.Metrics())
+	_, err := pvzService.GetPvzListWithFilter(context.Background(), usecases.PvzFilter{Page: 1, Limit: 10, Cursor: "not a cursor"})
+
+	assert.ErrorIs(t, err, usecases.ErrInvalidCursor)
+	mockRepo.AssertNotCalled(t, "GetPvzListWithFilter")
+}
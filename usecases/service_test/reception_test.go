@@ -2,12 +2,20 @@ package service
 
 import (
 	"avito_test/domain"
+	"avito_test/pkg/testutils"
 	"avito_test/repository"
 	"avito_test/repository/mocks"
+	"avito_test/repository/prometheus"
 	"avito_test/usecases"
 	"avito_test/usecases/service"
+	"context"
+	client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestReceptionService_StartReception(t *testing.T) {
@@ -52,6 +60,18 @@ func TestReceptionService_StartReception(t *testing.T) {
 			wantErr:          true,
 			expectedErr:      usecases.ErrUnclosedReception,
 		},
+		{
+			name:                  "concurrent start rejected by storage",
+			pvzId:                 1,
+			mockPvz:               domain.Pvz{Id: 1},
+			mockPvzErr:            nil,
+			mockReception:         domain.Reception{Status: "closed"},
+			mockReceptionErr:      nil,
+			mockStartReception:    domain.Reception{},
+			mockStartReceptionErr: repository.ErrReceptionInProgress,
+			wantErr:               true,
+			expectedErr:           usecases.ErrUnclosedReception,
+		},
 	}
 
 	for _, tt := range tests {
@@ -63,12 +83,12 @@ func TestReceptionService_StartReception(t *testing.T) {
 			if tt.mockPvzErr == nil {
 				mockReceptionRepo.On("GetLastReception", tt.pvzId).Return(tt.mockReception, tt.mockReceptionErr)
 				if tt.mockReception.Status == "closed" {
-					mockReceptionRepo.On("StartReception", tt.pvzId).Return(tt.mockStartReception, tt.mockStartReceptionErr)
+					mockReceptionRepo.On("StartReception", tt.pvzId, domain.ReceptionKindDelivery).Return(tt.mockStartReception, tt.mockStartReceptionErr)
 				}
 			}
 
-			receptionService := service.NewReceptionService(mockReceptionRepo, mockPvzRepo)
-			_, err := receptionService.StartReception(tt.pvzId)
+			receptionService := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+			_, err := receptionService.StartReception(context.Background(), tt.pvzId, domain.ReceptionKindDelivery)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -134,8 +154,8 @@ func TestReceptionService_CloseReception(t *testing.T) {
 				}
 			}
 
-			receptionService := service.NewReceptionService(mockReceptionRepo, mockPvzRepo)
-			reception, err := receptionService.CloseReception(tt.pvzId)
+			receptionService := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+			reception, err := receptionService.CloseReception(context.Background(), tt.pvzId)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -152,3 +172,73 @@ func TestReceptionService_CloseReception(t *testing.T) {
 		})
 	}
 }
+
+func TestReceptionService_GetReceptions(t *testing.T) {
+	filter := usecases.ReceptionFilter{Status: "closed"}
+
+	t.Run("success", func(t *testing.T) {
+		mockReceptionRepo := new(mocks.Reception)
+		mockPvzRepo := new(mocks.Pvz)
+		mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1}, nil)
+		mockReceptionRepo.On("ListReceptions", 1, filter).Return([]domain.Reception{{Id: 2, PvzId: 1, Status: "closed"}}, nil)
+
+		receptionService := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+		receptions, err := receptionService.GetReceptions(context.Background(), 1, filter)
+
+		assert.NoError(t, err)
+		assert.Len(t, receptions, 1)
+		mockPvzRepo.AssertExpectations(t)
+		mockReceptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("pvz not found", func(t *testing.T) {
+		mockReceptionRepo := new(mocks.Reception)
+		mockPvzRepo := new(mocks.Pvz)
+		mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{}, repository.NotFound)
+
+		receptionService := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, testutils.Metrics())
+		_, err := receptionService.GetReceptions(context.Background(), 1, filter)
+
+		assert.ErrorIs(t, err, repository.NotFound)
+		mockReceptionRepo.AssertNotCalled(t, "ListReceptions", 1, filter)
+	})
+}
+
+func TestReceptionService_Metrics(t *testing.T) {
+	registry := client.NewRegistry()
+	metrics := prometheus.NewMetrics(registry, nil)
+	mockReceptionRepo := new(mocks.Reception)
+	mockPvzRepo := new(mocks.Pvz)
+
+	mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1, City: "Казань"}, nil)
+	mockReceptionRepo.On("GetLastReception", 1).Return(domain.Reception{Status: "closed"}, nil).Once()
+	mockReceptionRepo.On("StartReception", 1, domain.ReceptionKindDelivery).Return(domain.Reception{Id: 1, PvzId: 1, Status: "in_progress"}, nil)
+	mockReceptionRepo.On("GetLastReception", 1).Return(domain.Reception{Status: "in_progress"}, nil).Once()
+	mockReceptionRepo.On("CloseReception", 1).Return(domain.Reception{Id: 1, PvzId: 1, StartDate: time.Now().Add(-5 * time.Minute), Status: "closed"}, nil)
+
+	receptionService := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, &mocks.TxManager{}, metrics)
+	_, err := receptionService.StartReception(context.Background(), 1, domain.ReceptionKindDelivery)
+	require.NoError(t, err)
+	_, err = receptionService.CloseReception(context.Background(), 1)
+	require.NoError(t, err)
+
+	expected := `
+# HELP receptions_started_total Total number of receptions started
+# TYPE receptions_started_total counter
+receptions_started_total{city="Казань"} 1
+# HELP receptions_closed_total Total number of receptions closed
+# TYPE receptions_closed_total counter
+receptions_closed_total{city="Казань"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "receptions_started_total", "receptions_closed_total"))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "reception_duration_seconds" {
+			histogram := family.GetMetric()[0].GetHistogram()
+			assert.EqualValues(t, 1, histogram.GetSampleCount())
+			assert.InDelta(t, 300, histogram.GetSampleSum(), 5)
+		}
+	}
+}
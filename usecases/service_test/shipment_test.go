@@ -0,0 +1,104 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/repository/mocks"
+	"avito_test/usecases"
+	"avito_test/usecases/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestShipmentService_CreateShipment(t *testing.T) {
+	overdue := []domain.Product{{Id: 3, Type: "shoes"}, {Id: 5, Type: "clothes"}}
+
+	tests := []struct {
+		name        string
+		productIds  []int
+		mockOverdue []domain.Product
+		mockShipErr error
+		expectedIds []int
+		expectedErr error
+	}{
+		{
+			name:        "ship every overdue product",
+			mockOverdue: overdue,
+			expectedIds: []int{3, 5},
+		},
+		{
+			name:        "ship selected products",
+			productIds:  []int{5},
+			mockOverdue: overdue,
+			expectedIds: []int{5},
+		},
+		{
+			name:        "product is not overdue",
+			productIds:  []int{5, 7},
+			mockOverdue: overdue,
+			expectedErr: usecases.ErrNotOverdue,
+		},
+		{
+			name:        "nothing overdue",
+			mockOverdue: []domain.Product{},
+			expectedErr: usecases.ErrShipmentEmpty,
+		},
+		{
+			name:        "shipped concurrently",
+			mockOverdue: overdue,
+			mockShipErr: repository.ErrProductNotInStock,
+			expectedIds: []int{3, 5},
+			expectedErr: usecases.ErrNotOverdue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockShipmentRepo := new(mocks.Shipment)
+			mockOrderRepo := new(mocks.Order)
+			mockPvzRepo := new(mocks.Pvz)
+
+			createdAt := time.Now()
+			mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{Id: 1}, nil)
+			mockShipmentRepo.On("ListOverdueProducts", 1).Return(tt.mockOverdue, nil)
+			if tt.expectedIds != nil {
+				mockShipmentRepo.On("CreateShipment", 1, tt.expectedIds).
+					Return(domain.ReturnShipment{Id: 9, PvzId: 1, CreatedAt: createdAt}, tt.mockShipErr)
+			}
+			if tt.expectedErr == nil {
+				mockOrderRepo.On("ReturnOrdersOfProducts", tt.expectedIds).Return(nil)
+			}
+
+			shipmentService := service.NewShipmentService(mockShipmentRepo, mockOrderRepo, mockPvzRepo, &mocks.TxManager{})
+			shipment, err := shipmentService.CreateShipment(context.Background(), 1, tt.productIds)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 9, shipment.Shipment.Id)
+				assert.Len(t, shipment.Products, len(tt.expectedIds))
+				for _, product := range shipment.Products {
+					assert.Equal(t, &createdAt, product.ShippedAt)
+				}
+			}
+			mockPvzRepo.AssertExpectations(t)
+			mockShipmentRepo.AssertExpectations(t)
+			mockOrderRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShipmentService_GetOverdue_PvzNotFound(t *testing.T) {
+	mockShipmentRepo := new(mocks.Shipment)
+	mockPvzRepo := new(mocks.Pvz)
+	mockPvzRepo.On("GetPvz", 1).Return(domain.Pvz{}, repository.NotFound)
+
+	shipmentService := service.NewShipmentService(mockShipmentRepo, new(mocks.Order), mockPvzRepo, &mocks.TxManager{})
+	_, err := shipmentService.GetOverdue(context.Background(), 1)
+
+	assert.ErrorIs(t, err, repository.NotFound)
+	mockShipmentRepo.AssertNotCalled(t, "ListOverdueProducts", 1)
+}
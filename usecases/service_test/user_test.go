@@ -5,6 +5,7 @@ import (
 	"avito_test/repository"
 	"avito_test/repository/mocks"
 	"avito_test/usecases/service"
+	"context"
 	"errors"
 	"golang.org/x/crypto/bcrypt"
 	"testing"
@@ -49,7 +50,7 @@ func TestUserService_Register(t *testing.T) {
 			mockRepo.On("Register", tt.email, mock.Anything, tt.role).Return(tt.mockUser, tt.mockErr)
 
 			userService := service.NewUserService(mockRepo)
-			user, err := userService.Register(tt.email, tt.password, tt.role)
+			user, err := userService.Register(context.Background(), tt.email, tt.password, tt.role)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -113,7 +114,7 @@ func TestUserService_Login(t *testing.T) {
 			mockRepo.On("Login", tt.email).Return(tt.mockUser, tt.mockErr)
 
 			userService := service.NewUserService(mockRepo)
-			token, err := userService.Login(tt.email, tt.password)
+			token, err := userService.Login(context.Background(), tt.email, tt.password)
 
 			if tt.wantErr {
 				assert.Error(t, err)
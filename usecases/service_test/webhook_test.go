@@ -0,0 +1,189 @@
+package service
+
+import (
+	"avito_test/domain"
+	"avito_test/repository"
+	"avito_test/repository/mocks"
+	"avito_test/usecases"
+	usecasesMocks "avito_test/usecases/mocks"
+	"avito_test/usecases/service"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+var retryPolicy = service.RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Second, MaxBackoff: 15 * time.Second}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		webhook     domain.Webhook
+		expectedErr error
+	}{
+		{
+			name:    "secret is generated",
+			webhook: domain.Webhook{URL: "https://partner.example/hook", EventTypes: []string{domain.EventProductAdded}, City: "Москва"},
+		},
+		{
+			name:        "not an http url",
+			webhook:     domain.Webhook{URL: "partner.example/hook"},
+			expectedErr: usecases.ErrInvalidWebhookURL,
+		},
+		{
+			name:        "event type partners cannot subscribe to",
+			webhook:     domain.Webhook{URL: "https://partner.example/hook", EventTypes: []string{domain.EventPvzOpened}},
+			expectedErr: usecases.ErrInvalidWebhookFilter,
+		},
+		{
+			name:        "unknown city",
+			webhook:     domain.Webhook{URL: "https://partner.example/hook", City: "Тверь"},
+			expectedErr: usecases.ErrInvalidWebhookFilter,
+		},
+		{
+			name:        "unknown pvz",
+			webhook:     domain.Webhook{URL: "https://partner.example/hook", PvzId: 9},
+			expectedErr: usecases.ErrInvalidWebhookFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Webhook)
+			mockCityRepo := new(mocks.City)
+			mockCityRepo.On("GetCityByName", "Москва").Return(domain.City{Id: 1, Name: "Москва", Enabled: true}, nil).Maybe()
+			mockCityRepo.On("GetCityByName", "Тверь").Return(domain.City{}, repository.NotFound).Maybe()
+			mockRepo.On("CreateWebhook", mock.MatchedBy(func(w domain.Webhook) bool { return w.PvzId == 9 })).
+				Return(domain.Webhook{}, repository.NotFound).Maybe()
+			mockRepo.On("CreateWebhook", mock.MatchedBy(func(w domain.Webhook) bool { return w.PvzId == 0 && len(w.Secret) == 64 })).
+				Return(domain.Webhook{Id: 1}, nil).Maybe()
+
+			webhookService := service.NewWebhookService(mockRepo, new(mocks.Pvz), mockCityRepo, new(usecasesMocks.WebhookSender), retryPolicy, 10, time.Minute)
+			_, err := webhookService.CreateWebhook(context.Background(), tt.webhook)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				mockRepo.AssertNumberOfCalls(t, "CreateWebhook", 1)
+			}
+		})
+	}
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	mockRepo := new(mocks.Webhook)
+	mockPvzRepo := new(mocks.Pvz)
+	mockRepo.On("ListWebhooks").Return([]domain.Webhook{
+		{Id: 1},
+		{Id: 2, EventTypes: []string{domain.EventReceptionClosed}},
+		{Id: 3, City: "Казань"},
+		{Id: 4, PvzId: 5, City: "Москва"},
+		{Id: 5, PvzId: 6},
+	}, nil)
+	mockPvzRepo.On("GetPvz", 5).Return(domain.Pvz{Id: 5, City: "Москва"}, nil)
+	for _, webhookId := range []int{1, 4} {
+		mockRepo.On("AddDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.WebhookId == webhookId && d.EventId == 11 && d.Status == domain.DeliveryStatusPending
+		})).Return(nil).Once()
+	}
+
+	webhookService := service.NewWebhookService(mockRepo, mockPvzRepo, new(mocks.City), new(usecasesMocks.WebhookSender), retryPolicy, 10, time.Minute)
+	err := webhookService.Publish(context.Background(), domain.Event{Id: 11, Type: domain.EventProductAdded, PvzId: 5})
+	assert.NoError(t, err)
+
+	// pvz.opened is not offered to partners.
+	err = webhookService.Publish(context.Background(), domain.Event{Id: 12, Type: domain.EventPvzOpened, PvzId: 5})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "ListWebhooks", 1)
+}
+
+func TestWebhookService_DeliverDue(t *testing.T) {
+	sendErr := errors.New("unexpected status 503 Service Unavailable")
+
+	tests := []struct {
+		name           string
+		attempts       int
+		sendErr        error
+		wantStatus     string
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:       "delivered",
+			wantStatus: domain.DeliveryStatusDelivered,
+		},
+		{
+			name:           "first failure waits the base backoff",
+			sendErr:        sendErr,
+			wantStatus:     domain.DeliveryStatusPending,
+			wantRetryAfter: 10 * time.Second,
+		},
+		{
+			name:           "backoff is capped",
+			attempts:       1,
+			sendErr:        sendErr,
+			wantStatus:     domain.DeliveryStatusPending,
+			wantRetryAfter: 15 * time.Second,
+		},
+		{
+			name:       "out of attempts",
+			attempts:   2,
+			sendErr:    sendErr,
+			wantStatus: domain.DeliveryStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := domain.Webhook{Id: 1, URL: "https://partner.example/hook", Secret: "s3cr3t"}
+			delivery := domain.WebhookDelivery{Id: 4, WebhookId: 1, Status: domain.DeliveryStatusPending, Attempts: tt.attempts}
+
+			mockRepo := new(mocks.Webhook)
+			mockSender := new(usecasesMocks.WebhookSender)
+			var claimedFor time.Duration
+			mockRepo.On("ClaimDueDeliveries", mock.Anything, mock.Anything, 10).Run(func(args mock.Arguments) {
+				claimedFor = args.Get(1).(time.Time).Sub(args.Get(0).(time.Time))
+			}).Return([]domain.WebhookDelivery{delivery}, nil)
+			mockRepo.On("GetWebhook", 1).Return(webhook, nil)
+			mockSender.On("Send", webhook, delivery).Return(503, tt.sendErr)
+
+			var saved domain.WebhookDelivery
+			mockRepo.On("UpdateDelivery", mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(0).(domain.WebhookDelivery)
+			}).Return(nil)
+
+			webhookService := service.NewWebhookService(mockRepo, new(mocks.Pvz), new(mocks.City), mockSender, retryPolicy, 10, time.Minute)
+			before := time.Now()
+			_, err := webhookService.DeliverDue(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, time.Minute, claimedFor)
+			assert.Equal(t, tt.wantStatus, saved.Status)
+			assert.Equal(t, tt.attempts+1, saved.Attempts)
+			if tt.wantRetryAfter > 0 {
+				assert.WithinDuration(t, before.Add(tt.wantRetryAfter), saved.NextAttemptAt, time.Second)
+				assert.Equal(t, tt.sendErr.Error(), saved.LastError)
+			}
+			if tt.sendErr == nil {
+				assert.NotNil(t, saved.DeliveredAt)
+			}
+			mockSender.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookService_Redeliver_OtherWebhook(t *testing.T) {
+	mockRepo := new(mocks.Webhook)
+	mockRepo.On("GetWebhook", 1).Return(domain.Webhook{Id: 1}, nil)
+	mockRepo.On("GetDelivery", 4).Return(domain.WebhookDelivery{Id: 4, WebhookId: 2}, nil)
+
+	webhookService := service.NewWebhookService(mockRepo, new(mocks.Pvz), new(mocks.City), new(usecasesMocks.WebhookSender), retryPolicy, 10, time.Minute)
+	_, err := webhookService.Redeliver(context.Background(), 1, 4)
+
+	assert.ErrorIs(t, err, repository.NotFound)
+	mockRepo.AssertNotCalled(t, "UpdateDelivery", mock.Anything)
+}
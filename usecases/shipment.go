@@ -0,0 +1,16 @@
+package usecases
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+type Shipment interface {
+	// MarkOverdue marks the products whose storage period has run out and returns how many.
+	MarkOverdue(ctx context.Context) (int, error)
+	GetOverdue(ctx context.Context, pvzId int) ([]domain.Product, error)
+	// CreateShipment sends the overdue products back to the sender; with no
+	// productIds every overdue product of the PVZ is shipped.
+	CreateShipment(ctx context.Context, pvzId int, productIds []int) (domain.ReturnShipmentWithProducts, error)
+	GetShipment(ctx context.Context, shipmentId int) (domain.ReturnShipmentWithProducts, error)
+}
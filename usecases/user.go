@@ -1,9 +1,12 @@
 package usecases
 
-import "avito_test/domain"
+import (
+	"avito_test/domain"
+	"context"
+)
 
 type User interface {
 	GetToken(id string, role string) (string, error)
-	Register(email string, password string, role string) (domain.User, error)
-	Login(email string, password string) (string, error)
+	Register(ctx context.Context, email string, password string, role string) (domain.User, error)
+	Login(ctx context.Context, email string, password string) (string, error)
 }
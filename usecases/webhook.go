@@ -0,0 +1,25 @@
+package usecases
+
+import (
+	"avito_test/domain"
+	"context"
+)
+
+type Webhook interface {
+	// CreateWebhook generates a secret unless one is given; only the result carries it.
+	CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, webhookId int) (domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookId int) error
+	// ListDeliveries returns the latest deliveries of the webhook, newest first.
+	ListDeliveries(ctx context.Context, webhookId int) ([]domain.WebhookDelivery, error)
+	// Redeliver sends the delivery again right away and returns it with the outcome recorded.
+	Redeliver(ctx context.Context, webhookId int, deliveryId int) (domain.WebhookDelivery, error)
+}
+
+// WebhookSender makes a single delivery attempt.
+type WebhookSender interface {
+	// Send POSTs the delivery payload signed with the webhook secret and returns the
+	// response status; a non-2xx status is an error.
+	Send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error)
+}